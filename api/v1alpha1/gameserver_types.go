@@ -26,6 +26,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -58,14 +59,24 @@ type GameServerSpec struct {
 	// +optional
 	GameConfigs *GameConfigs `json:"gameConfigs,omitempty"`
 
+	// State is the desired run state of the game server, either 'Running' or 'Stopped'.
+	// Stopping a game server removes its pod, but keeps its storage and service.
+	// If not specified, the state is derived from the deprecated Replicas field.
+	// +optional
+	State GameServerState `json:"state,omitempty"`
+
 	// Replicas is the number of game server instances to run.
+	//
+	// Deprecated: use State instead. A GameServer always runs a single instance, so 0 stops the
+	// game server and any other value runs it. Replicas is ignored when State is set.
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:default=1
 	// +optional
 	Replicas int32 `json:"replicas,omitempty"`
 
 	// Storage defines the storage configuration for the game server.
-	// If not specified, a default storage size of 10Gi will be used.
+	// If not specified, persistent storage is enabled with the recommended size of the game,
+	// or 10Gi for games without recommendations.
 	// +optional
 	Storage *StorageSpec `json:"storage,omitempty"`
 
@@ -75,11 +86,162 @@ type GameServerSpec struct {
 	Service *ServiceSpec `json:"service,omitempty"`
 
 	// Resources defines resource requests and limits for the primary game server container.
-	// If not specified, Kubernetes scheduler defaults apply.
+	// If not specified, the recommended requests of the game are used. Games without recommendations
+	// fall back to the Kubernetes scheduler defaults.
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Backup configures scheduled backups of the game server data.
+	// Requires persistent storage to be enabled.
+	// +optional
+	Backup *BackupSpec `json:"backup,omitempty"`
+
+	// Updates configures scheduled updates of the game by LinuxGSM.
+	// If not specified, the game is only installed and never updated.
+	// +optional
+	Updates *UpdatesSpec `json:"updates,omitempty"`
+
+	// RCON enables the remote console of the game, which the operator uses to save the world
+	// and announce restarts.
+	// +optional
+	RCON *RCONSpec `json:"rcon,omitempty"`
+
+	// Shutdown configures how the game server is stopped, warning players and saving the world first.
+	// If not specified, the game gets the Kubernetes default of 30 seconds to stop.
+	// +optional
+	Shutdown *ShutdownSpec `json:"shutdown,omitempty"`
+
+	// Probes configures the startup, readiness and liveness probes of the game server container.
+	// If not specified, the probes of the game are used, so the game server is only ready once the game accepts players.
+	// +optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
+	// Idle stops the game server once no players were online for a while, so empty game servers use no resources.
+	// Players are counted by querying the game, so only games the operator knows how to query are stopped.
+	// +optional
+	Idle *IdleSpec `json:"idle,omitempty"`
+
+	// Schedule only runs the game server within its windows, e.g. evenings and weekends.
+	// If not specified, the game server runs all the time.
+	// +optional
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// LinuxGSM configures LinuxGSM, which installs, starts and monitors the game.
+	// +optional
+	LinuxGSM *LinuxGSMSpec `json:"linuxgsm,omitempty"`
+
+	// ConfigFiles are written into the data volume from ConfigMaps and Secrets before the game server starts.
+	// The game server is restarted when they change. Requires persistent storage.
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=map
+	// +listMapKey=path
+	// +optional
+	ConfigFiles []ConfigFile `json:"configFiles,omitempty"`
+
+	// Secrets passes the passwords and tokens of the game from Secrets, so they are kept out of ConfigMaps and the
+	// GameServer. They are written into the settings of the game the operator knows, and passed to the game server
+	// container as environment variables.
+	// +optional
+	Secrets *SecretsSpec `json:"secrets,omitempty"`
+}
+
+// LinuxGSMSpec configures LinuxGSM.
+type LinuxGSMSpec struct {
+	// Config are settings of the config of the LinuxGSM instance, e.g. "maxplayers" or "servername", which are
+	// written into config-lgsm/<game>server/<game>server.cfg before the game server starts. Changing them restarts
+	// the game server. See the _default.cfg of the game for its settings.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// ConfigFile is a file written into the data volume from a key of a ConfigMap or Secret.
+type ConfigFile struct {
+	// Path is the path of the file relative to the data volume, e.g. "serverfiles/server.cfg" for a config file of
+	// the game or "config-lgsm/rustserver/common.cfg" for a LinuxGSM config. Files in serverfiles are only written
+	// once LinuxGSM installed the game, as it only installs the game into an empty directory.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9._/ -]*$`
+	Path string `json:"path"`
+
+	// ConfigMapKeyRef selects the key of a ConfigMap in the namespace of the GameServer holding the file.
+	// Exactly one of configMapKeyRef and secretKeyRef must be set.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects the key of a Secret in the namespace of the GameServer holding the file.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// Mode is how the file is written, 'Overwrite' or 'Merge'.
+	// +kubebuilder:default=Overwrite
+	// +optional
+	Mode ConfigFileMode `json:"mode,omitempty"`
+}
+
+// ConfigFileMode is how a config file is written into the data volume.
+// +kubebuilder:validation:Enum=Overwrite;Merge
+type ConfigFileMode string
+
+const (
+	// ConfigFileModeOverwrite replaces the file, so changes the game makes to it are lost on restart.
+	ConfigFileModeOverwrite ConfigFileMode = "Overwrite"
+
+	// ConfigFileModeMerge treats each line of the source as a setting, "name=value" or "name value", replacing the
+	// lines of the file that set the same name and adding the others. Other settings of the file are kept.
+	// Empty lines and comments starting with #, ; or // are skipped.
+	ConfigFileModeMerge ConfigFileMode = "Merge"
+)
+
+// SecretsSpec defines the passwords and tokens of the game server.
+//
+// They are passed to the game server container as the SERVER_PASSWORD, RCON_PASSWORD, GSLT and ADMIN_PASSWORD
+// environment variables, and written into the settings of the game on every start for games with known settings.
+// The game server is restarted when they change.
+type SecretsSpec struct {
+	// ServerPassword is the password players need to join the game server.
+	// +optional
+	ServerPassword *SecretSource `json:"serverPassword,omitempty"`
+
+	// RCONPassword is the password of the remote console enabled by spec.rcon, instead of spec.rcon.passwordSecretRef.
+	// +optional
+	RCONPassword *SecretSource `json:"rconPassword,omitempty"`
+
+	// GSLT selects the key of a Secret holding the Steam Game Server Login Token of the game server, which Source
+	// engine games need to be listed publicly.
+	// +optional
+	GSLT *corev1.SecretKeySelector `json:"gslt,omitempty"`
+
+	// AdminPassword is the password of the admins of the game server.
+	// +optional
+	AdminPassword *SecretSource `json:"adminPassword,omitempty"`
+}
+
+// SecretSource is a password, read from a Secret or generated by the operator.
+// Exactly one of secretKeyRef and generate must be set.
+type SecretSource struct {
+	// SecretKeyRef selects the key of a Secret in the namespace of the GameServer holding the password.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// Generate generates a random password, stored in the <name>-secrets Secret owned by the GameServer.
+	// The password is kept until generate is unset or the GameServer is deleted.
+	// +optional
+	Generate bool `json:"generate,omitempty"`
 }
 
+// GameServerState is the desired run state of a game server.
+// +kubebuilder:validation:Enum=Running;Stopped
+type GameServerState string
+
+const (
+	// GameServerStateRunning runs a single instance of the game server.
+	GameServerStateRunning GameServerState = "Running"
+
+	// GameServerStateStopped scales the game server down to zero, keeping its storage and service.
+	GameServerStateStopped GameServerState = "Stopped"
+)
+
 type GameConfigs struct {
 	// Minecraft holds configuration specific to Minecraft game servers.
 	// +optional
@@ -88,12 +250,191 @@ type GameConfigs struct {
 
 type MinecraftConfig struct {
 	// Version specifies the Minecraft server version.
+	// If not specified, the version of the modpack is installed, or the latest release supported by the loader.
+	// Required for the Forge and NeoForge loaders without a modpack.
+	// +kubebuilder:validation:Pattern=`^[0-9A-Za-z._+-]*$`
 	// +optional
 	Version string `json:"version,omitempty"`
 
-	// Mods is a list of mods to be installed on the Minecraft server.
+	// Loader is the server software the operator installs, instead of the vanilla server LinuxGSM installs.
+	// The server is installed into the data volume before the first start, and again whenever the resolved
+	// version, loader or modpack changes. The operator installs the server when any of version, loader or modpack
+	// is set; if loader is not specified it defaults to the loader of the modpack, or Vanilla.
+	// +optional
+	Loader MinecraftLoader `json:"loader,omitempty"`
+
+	// LoaderVersion pins the version of the loader: the Paper build, or the Fabric, Forge or NeoForge version.
+	// If not specified, the loader version of the modpack is installed, or the recommended or latest stable
+	// version of the loader for the Minecraft version.
+	// +kubebuilder:validation:Pattern=`^[0-9A-Za-z._+-]*$`
+	// +optional
+	LoaderVersion string `json:"loaderVersion,omitempty"`
+
+	// Modpack is installed into the server files before the mods. Modrinth modpacks are installed from their
+	// .mrpack file, CurseForge modpacks from their server pack. URLs ending in .mrpack are installed as Modrinth
+	// modpacks, other URLs as a zip of the server files. The files of the previous modpack are removed when the
+	// modpack changes. For CurseForge modpacks, sha1 is the hash of the server pack. Requires persistent storage.
+	// +optional
+	Modpack *MinecraftMod `json:"modpack,omitempty"`
+
+	// Mods are downloaded into the mods directory of the server before it starts. Mods installed by the operator
+	// that are no longer listed are removed, mods added by hand are kept. Requires persistent storage.
+	// +kubebuilder:validation:MaxItems=200
+	// +listType=atomic
+	// +optional
+	Mods []MinecraftMod `json:"mods,omitempty"`
+
+	// Properties are written into server.properties before the server starts, so changing them restarts the
+	// game server. Properties that are not set keep the value in server.properties. Requires persistent storage.
+	// +optional
+	Properties *MinecraftProperties `json:"properties,omitempty"`
+
+	// Whitelist are the names of the players allowed to join the server. When set, the whitelist is enforced and
+	// replaces whitelist.json; names are resolved to the UUIDs of their Mojang accounts, or their offline UUIDs
+	// when online mode is disabled. Changes are applied to the running server through its console, without a
+	// restart. Requires persistent storage.
+	// +kubebuilder:validation:MaxItems=500
+	// +kubebuilder:validation:items:Pattern=`^[A-Za-z0-9_]{1,16}$`
+	// +listType=set
 	// +optional
-	Mods []string `json:"mods,omitempty"`
+	Whitelist []string `json:"whitelist,omitempty"`
+
+	// Ops are the names of the players that are operators of the server. When set, they replace ops.json; names
+	// are resolved and changes applied like the whitelist. Requires persistent storage.
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:Pattern=`^[A-Za-z0-9_]{1,16}$`
+	// +listType=set
+	// +optional
+	Ops []string `json:"ops,omitempty"`
+}
+
+// MinecraftProperties are settings of server.properties.
+type MinecraftProperties struct {
+	// MOTD is the message of the day shown in the server list.
+	// +optional
+	MOTD *string `json:"motd,omitempty"`
+
+	// Difficulty is the difficulty of the game.
+	// +kubebuilder:validation:Enum=peaceful;easy;normal;hard
+	// +optional
+	Difficulty string `json:"difficulty,omitempty"`
+
+	// Gamemode is the game mode of players joining the server.
+	// +kubebuilder:validation:Enum=survival;creative;adventure;spectator
+	// +optional
+	Gamemode string `json:"gamemode,omitempty"`
+
+	// MaxPlayers is the maximum number of players on the server at the same time.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxPlayers *int32 `json:"maxPlayers,omitempty"`
+
+	// ViewDistance is the number of chunks the server sends to players in each direction.
+	// +kubebuilder:validation:Minimum=3
+	// +kubebuilder:validation:Maximum=32
+	// +optional
+	ViewDistance *int32 `json:"viewDistance,omitempty"`
+
+	// SimulationDistance is the number of chunks around players the server updates.
+	// +kubebuilder:validation:Minimum=3
+	// +kubebuilder:validation:Maximum=32
+	// +optional
+	SimulationDistance *int32 `json:"simulationDistance,omitempty"`
+
+	// SpawnProtection is the radius around the spawn that only operators can build in. Zero disables it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SpawnProtection *int32 `json:"spawnProtection,omitempty"`
+
+	// Seed is the seed of the world, which only applies when the world is generated.
+	// +optional
+	Seed *string `json:"seed,omitempty"`
+
+	// OnlineMode checks that players have a Mojang account. Whitelisted players and ops are resolved to offline
+	// UUIDs when it is disabled.
+	// +optional
+	OnlineMode *bool `json:"onlineMode,omitempty"`
+
+	// PVP allows players to damage each other.
+	// +optional
+	PVP *bool `json:"pvp,omitempty"`
+
+	// Hardcore bans players when they die.
+	// +optional
+	Hardcore *bool `json:"hardcore,omitempty"`
+
+	// AllowFlight keeps players flying in survival mode, e.g. with mods, from being kicked.
+	// +optional
+	AllowFlight *bool `json:"allowFlight,omitempty"`
+
+	// Extra are other properties by their name in server.properties, e.g. "enable-command-block". Properties set
+	// by the fields above, the whitelist or RCON cannot be set here.
+	// +optional
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// MinecraftLoader is the server software of a Minecraft server.
+// +kubebuilder:validation:Enum=Vanilla;Paper;Fabric;Forge;NeoForge
+type MinecraftLoader string
+
+const (
+	// MinecraftLoaderVanilla is the server released by Mojang.
+	MinecraftLoaderVanilla MinecraftLoader = "Vanilla"
+
+	// MinecraftLoaderPaper is the Paper server, which loads plugins instead of mods.
+	MinecraftLoaderPaper MinecraftLoader = "Paper"
+
+	// MinecraftLoaderFabric is the vanilla server with the Fabric mod loader.
+	MinecraftLoaderFabric MinecraftLoader = "Fabric"
+
+	// MinecraftLoaderForge is the Minecraft Forge server, from Minecraft 1.17 on.
+	MinecraftLoaderForge MinecraftLoader = "Forge"
+
+	// MinecraftLoaderNeoForge is the NeoForge server.
+	MinecraftLoaderNeoForge MinecraftLoader = "NeoForge"
+)
+
+// MinecraftModSource is where a mod is downloaded from.
+// +kubebuilder:validation:Enum=Modrinth;CurseForge;URL
+type MinecraftModSource string
+
+const (
+	// MinecraftModSourceModrinth downloads the mod from Modrinth.
+	MinecraftModSourceModrinth MinecraftModSource = "Modrinth"
+
+	// MinecraftModSourceCurseForge downloads the mod from CurseForge, which requires the operator to be given a
+	// CurseForge API key.
+	MinecraftModSourceCurseForge MinecraftModSource = "CurseForge"
+
+	// MinecraftModSourceURL downloads the mod file from a URL.
+	MinecraftModSourceURL MinecraftModSource = "URL"
+)
+
+// MinecraftMod is a mod of a Minecraft server.
+type MinecraftMod struct {
+	// Source is where the mod is downloaded from.
+	Source MinecraftModSource `json:"source"`
+
+	// Project is the ID or slug of the Modrinth project, or the numeric ID of the CurseForge mod.
+	// Required for the Modrinth and CurseForge sources.
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// Version is the ID or version number of the Modrinth version, or the ID of the CurseForge file.
+	// If not specified, the most recent version for the Minecraft version is installed. It is resolved once,
+	// later releases of the mod are not installed until the mod or the Minecraft version changes.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// URL is the download URL of the mod file for the URL source.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// SHA1 is the expected SHA-1 hash of the mod file. Modrinth and CurseForge files are checked against the hash
+	// reported by their API when not specified.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{40}$`
+	// +optional
+	SHA1 string `json:"sha1,omitempty"`
 }
 
 // StorageSpec defines the storage configuration for the game server.
@@ -105,8 +446,9 @@ type StorageSpec struct {
 	Enabled *bool `json:"enabled,omitempty"`
 
 	// Size is the size of the persistent volume claim for the game server data.
+	// If not specified, the recommended size of the game is used, or 10Gi for games without recommendations.
+	// The size can be increased later when the StorageClass allows volume expansion, but never decreased.
 	// +kubebuilder:validation:Pattern=`^\d+Gi$`
-	// +kubebuilder:default="10Gi"
 	// +optional
 	Size string `json:"size,omitempty"`
 
@@ -114,6 +456,347 @@ type StorageSpec struct {
 	// If not specified, the default StorageClass for the cluster will be used.
 	// +optional
 	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// DeletionPolicy determines what happens to the persistent volume claim when the GameServer is deleted.
+	// 'Retain' keeps the claim and the world data on it, 'Delete' removes it once the game server has stopped.
+	// Stopping a game server never removes its claim.
+	// If not specified, 'Retain' will be used.
+	// +optional
+	DeletionPolicy StorageDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// FromSnapshot is the name of a VolumeSnapshot in the same namespace the data volume is populated from
+	// when it is created, e.g. one taken by a GameServer with Snapshot backups. The data volume is only
+	// created once, so changing this field has no effect on an existing volume.
+	// +optional
+	FromSnapshot string `json:"fromSnapshot,omitempty"`
+}
+
+// StorageDeletionPolicy determines what happens to the game server data when the GameServer is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type StorageDeletionPolicy string
+
+const (
+	// StorageDeletionPolicyRetain keeps the persistent volume claim after the GameServer is deleted.
+	StorageDeletionPolicyRetain StorageDeletionPolicy = "Retain"
+
+	// StorageDeletionPolicyDelete deletes the persistent volume claim together with the GameServer.
+	StorageDeletionPolicyDelete StorageDeletionPolicy = "Delete"
+)
+
+// BackupSpec defines scheduled backups of the game server data.
+//
+// An Archive backup stops the game server, so LinuxGSM can save the world, archives the data volume
+// and uploads the archive to the target. The game server is started again once the backup finished.
+// A Snapshot backup snapshots the data volume of the running game server instead.
+// +kubebuilder:validation:XValidation:rule="(has(self.mode) && self.mode == 'Snapshot') != has(self.target)",message="target is required for Archive backups and not supported for Snapshot backups"
+type BackupSpec struct {
+	// Schedule is the cron schedule backups are taken on, e.g. '0 4 * * *'.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the time zone the schedule is interpreted in, e.g. 'Europe/Amsterdam'.
+	// If not specified, the time zone of the kube-controller-manager is used for Archive backups and UTC for Snapshot backups.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// Suspend pauses scheduled backups without removing the configuration.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Mode is how backups are taken. 'Archive' stops the game server and uploads a tarball of the data volume
+	// to the target. 'Snapshot' creates a CSI VolumeSnapshot of the data volume while the game server keeps
+	// running, which is much faster for large worlds but requires a CSI driver with snapshot support.
+	// +kubebuilder:default=Archive
+	// +optional
+	Mode BackupMode `json:"mode,omitempty"`
+
+	// VolumeSnapshotClassName is the VolumeSnapshotClass used for Snapshot backups.
+	// If not specified, the default VolumeSnapshotClass of the CSI driver is used.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Retention is the number of backups to keep on the target, or the number of VolumeSnapshots to keep.
+	// Older backups are removed after each successful backup.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=7
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+
+	// BeforeDeletion takes a final backup when the GameServer is deleted,
+	// after the game server has stopped and before its storage is released.
+	// +optional
+	BeforeDeletion bool `json:"beforeDeletion,omitempty"`
+
+	// Target is where Archive backups are stored.
+	// +optional
+	Target BackupTarget `json:"target,omitzero"`
+}
+
+// BackupMode is how backups are taken.
+// +kubebuilder:validation:Enum=Archive;Snapshot
+type BackupMode string
+
+const (
+	// BackupModeArchive uploads a tarball of the data volume to a backup target.
+	BackupModeArchive BackupMode = "Archive"
+
+	// BackupModeSnapshot creates a CSI VolumeSnapshot of the data volume.
+	BackupModeSnapshot BackupMode = "Snapshot"
+)
+
+// BackupTarget is where backups are stored. Exactly one target must be specified.
+// +kubebuilder:validation:XValidation:rule="has(self.persistentVolumeClaim) != has(self.s3)",message="exactly one of persistentVolumeClaim or s3 must be set"
+type BackupTarget struct {
+	// PersistentVolumeClaim stores backups on an existing persistent volume claim.
+	// +optional
+	PersistentVolumeClaim *PersistentVolumeClaimBackupTarget `json:"persistentVolumeClaim,omitempty"`
+
+	// S3 stores backups in a bucket of an S3-compatible object store, such as AWS S3 or MinIO.
+	// +optional
+	S3 *S3BackupTarget `json:"s3,omitempty"`
+}
+
+// PersistentVolumeClaimBackupTarget stores backups on an existing persistent volume claim.
+type PersistentVolumeClaimBackupTarget struct {
+	// ClaimName is the name of a persistent volume claim in the namespace of the GameServer.
+	// Use a ReadWriteMany claim to share it between game servers.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory on the claim backups are stored in.
+	// If not specified, the name of the GameServer is used.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// S3BackupTarget stores backups in a bucket of an S3-compatible object store.
+type S3BackupTarget struct {
+	// Endpoint is the URL of the S3 API, e.g. 'https://s3.eu-west-1.amazonaws.com' or 'http://minio.minio:9000'.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket backups are stored in.
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix is the key prefix backups are stored under.
+	// If not specified, '<namespace>/<name>' of the GameServer is used.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the region of the bucket. Most S3-compatible stores ignore it.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the namespace of the GameServer
+	// with the 'AWS_ACCESS_KEY_ID' and 'AWS_SECRET_ACCESS_KEY' keys.
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+}
+
+// UpdatesSpec defines scheduled updates of the game server.
+//
+// On the schedule the operator runs the LinuxGSM update or check-update command in the running game server.
+// LinuxGSM only restarts the game server when a new build was installed.
+type UpdatesSpec struct {
+	// Schedule is the cron schedule updates are checked for on, e.g. '0 5 * * *'.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the time zone the schedule is interpreted in, e.g. 'Europe/Amsterdam'.
+	// If not specified, UTC is used.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// Strategy determines what happens when an update is available. 'CheckOnly' only reports it in the status,
+	// 'UpdateWhenEmpty' installs it once no players are connected and 'Force' installs it right away.
+	// +kubebuilder:default=CheckOnly
+	// +optional
+	Strategy UpdateStrategy `json:"strategy,omitempty"`
+
+	// Suspend pauses scheduled updates without removing the configuration.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// UpdateStrategy determines what happens when an update of the game is available.
+// +kubebuilder:validation:Enum=CheckOnly;UpdateWhenEmpty;Force
+type UpdateStrategy string
+
+const (
+	// UpdateStrategyCheckOnly reports available updates without installing them.
+	UpdateStrategyCheckOnly UpdateStrategy = "CheckOnly"
+
+	// UpdateStrategyUpdateWhenEmpty installs available updates once no players are connected.
+	// Games for which LinuxGSM cannot report the connected players are never updated.
+	UpdateStrategyUpdateWhenEmpty UpdateStrategy = "UpdateWhenEmpty"
+
+	// UpdateStrategyForce installs available updates right away, disconnecting connected players.
+	UpdateStrategyForce UpdateStrategy = "Force"
+)
+
+// RCONSpec defines the remote console of the game server.
+//
+// For games with known RCON settings, such as Minecraft, Rust, Factorio and the Source engine games,
+// the operator enables RCON in the config of the game on every start. Other games have to enable it
+// themselves, and require the protocol and port to be set.
+type RCONSpec struct {
+	// Protocol is the RCON protocol the game speaks, 'Source' or 'WebRCON'.
+	// If not specified, the protocol of the game is used.
+	// +optional
+	Protocol RCONProtocol `json:"protocol,omitempty"`
+
+	// Port is the port the remote console listens on.
+	// If not specified, the RCON port of the game is used.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// PasswordSecretRef selects the key of a Secret in the namespace of the GameServer holding the RCON password.
+	// Exactly one of passwordSecretRef and spec.secrets.rconPassword must be set.
+	// The game server is restarted when the password changes.
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// RCONProtocol is the protocol of the remote console of a game.
+// +kubebuilder:validation:Enum=Source;WebRCON
+type RCONProtocol string
+
+const (
+	// RCONProtocolSource is the Source RCON protocol, also spoken by Minecraft, Factorio and ARK.
+	RCONProtocolSource RCONProtocol = "Source"
+
+	// RCONProtocolWebRCON is the WebSocket based RCON protocol of Rust.
+	RCONProtocolWebRCON RCONProtocol = "WebRCON"
+)
+
+// ShutdownSpec defines how the game server is stopped.
+//
+// Before the pod of the game server is terminated, and before the operator restarts the game for an update
+// or a GameServerCommand, players are warned on the warnings schedule and the world is saved. The console commands
+// are sent over RCON when it is configured, and to the LinuxGSM console otherwise.
+type ShutdownSpec struct {
+	// GracePeriodSeconds is how long LinuxGSM gets to stop the game once the players were warned.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=120
+	// +optional
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// Warnings are the times before the game server stops at which players are warned, e.g. ['5m', '1m', '10s'].
+	// Games without a known announce command are not warned.
+	// +kubebuilder:validation:MaxItems=10
+	// +listType=atomic
+	// +optional
+	Warnings []metav1.Duration `json:"warnings,omitempty"`
+
+	// SaveCommand is the console command saving the world before the game server stops, e.g. 'save-all flush'.
+	// If not specified, the save command of the game is used.
+	// +optional
+	SaveCommand string `json:"saveCommand,omitempty"`
+}
+
+// ProbeType is how the game server container is probed.
+// +kubebuilder:validation:Enum=TCP;A2S;Minecraft;Monitor;None
+type ProbeType string
+
+const (
+	// ProbeTypeTCP connects to the port.
+	ProbeTypeTCP ProbeType = "TCP"
+	// ProbeTypeA2S sends a Steam A2S_INFO query to the port.
+	ProbeTypeA2S ProbeType = "A2S"
+	// ProbeTypeMinecraft sends a Minecraft Server List Ping to the port.
+	ProbeTypeMinecraft ProbeType = "Minecraft"
+	// ProbeTypeMonitor runs the LinuxGSM monitor command.
+	ProbeTypeMonitor ProbeType = "Monitor"
+	// ProbeTypeNone disables the probes.
+	ProbeTypeNone ProbeType = "None"
+)
+
+// ProbesSpec configures the probes of the game server container.
+//
+// The startup and readiness probes check that the game accepts players, so the Service only routes players to
+// the game server once the game is installed and started. The liveness probe runs the LinuxGSM monitor command,
+// which starts the game again when it stopped.
+type ProbesSpec struct {
+	// Type is how the startup and readiness probes check the game. If not specified, the game is queried when
+	// the operator knows how to query it, TCP games are probed on their game port and other games are not probed.
+	// +optional
+	Type ProbeType `json:"type,omitempty"`
+
+	// Port is the container port probed by the TCP, A2S and Minecraft probes.
+	// If not specified, the port the game is queried on, or its game port, is probed.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// StartupTimeout is how long the game may take to start before the container is restarted. The first start
+	// includes installing the game, which downloads tens of gigabytes for some games. Defaults to 2h.
+	// +optional
+	StartupTimeout *metav1.Duration `json:"startupTimeout,omitempty"`
+
+	// Liveness enables the liveness probe. The container is restarted when the LinuxGSM monitor command keeps
+	// failing. Defaults to true, unless the type is None.
+	// +optional
+	Liveness *bool `json:"liveness,omitempty"`
+}
+
+// IdleSpec defines when an empty game server is stopped.
+//
+// An idle game server is scaled down to zero like a stopped one, keeping its storage and service. It is started
+// again when its games.idebeijer.github.io/wake-requested-at annotation is set to a later time, or when spec.state
+// is set to Stopped and back to Running.
+type IdleSpec struct {
+	// Timeout is how long no players may be online before the game server is stopped. The time the game takes to
+	// start counts from when it accepts players. Defaults to 30m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// CheckInterval is how often the game server is queried for the players online. Defaults to 1m.
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+
+	// WakeOnConnect starts the game server when a player connects to it while it is idle. Until the game accepts
+	// players, the Service routes them to the operator, which tells Minecraft players that the server is starting.
+	// Requires the operator to run with --wake-proxy-address.
+	// +optional
+	WakeOnConnect bool `json:"wakeOnConnect,omitempty"`
+}
+
+// AnnotationWakeRequestedAt starts a game server stopped by spec.idle again when set to a time, in RFC 3339 format,
+// after the game server was stopped.
+const AnnotationWakeRequestedAt = "games.idebeijer.github.io/wake-requested-at"
+
+// ScheduleSpec defines when the game server runs.
+//
+// The game server is started when a window opens and stopped when it closes, unless another window is open.
+// It is stopped like any stopped game server, warning the players and saving the world first when spec.shutdown
+// is set. spec.state Stopped keeps the game server stopped within the windows, and spec.idle stops it within them
+// when it is empty.
+type ScheduleSpec struct {
+	// Windows are the periods the game server runs in.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	// +listType=atomic
+	Windows []ScheduleWindow `json:"windows"`
+
+	// TimeZone is the time zone the windows are interpreted in, e.g. 'Europe/Amsterdam'.
+	// If not specified, UTC is used.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+}
+
+// ScheduleWindow is a period the game server runs in, from a start until a stop on cron schedules.
+type ScheduleWindow struct {
+	// Start is the cron schedule the window opens on, e.g. '0 18 * * 1-5' for weekday evenings.
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// Stop is the cron schedule the window closes on, e.g. '0 23 * * 1-5'.
+	// +kubebuilder:validation:MinLength=1
+	Stop string `json:"stop"`
 }
 
 // ServiceSpec defines the service configuration for the game server.
@@ -129,7 +812,8 @@ type ServiceSpec struct {
 	// Ports is a list of ports to expose on the service.
 	// The operator will automatically configure the underlying Pod's containerPorts
 	// based on the targetPort values specified here.
-	// If not specified, no ports will be exposed.
+	// If not specified, the default ports of the game are exposed. Games without known defaults
+	// expose no ports.
 	// +optional
 	Ports []ServicePort `json:"ports,omitempty"`
 
@@ -173,11 +857,142 @@ type ServicePort struct {
 	NodePort int32 `json:"nodePort,omitempty"`
 }
 
+// GameServerPhase is a high-level summary of where the GameServer is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Installing;Running;Stopped;Failed
+type GameServerPhase string
+
+const (
+	// GameServerPhasePending means the game server workload or its pod has not started yet,
+	// e.g. because it is still being scheduled or waiting for its volume to be provisioned.
+	GameServerPhasePending GameServerPhase = "Pending"
+
+	// GameServerPhaseInstalling means the game server container is running but not ready yet.
+	// LinuxGSM installs and updates the game on startup, which can take a long time for large games.
+	GameServerPhaseInstalling GameServerPhase = "Installing"
+
+	// GameServerPhaseRunning means the game server pod is ready.
+	GameServerPhaseRunning GameServerPhase = "Running"
+
+	// GameServerPhaseStopped means the game server is intentionally scaled down to zero.
+	GameServerPhaseStopped GameServerPhase = "Stopped"
+
+	// GameServerPhaseFailed means the game server cannot start without intervention,
+	// e.g. because its image cannot be pulled or its container keeps crashing.
+	GameServerPhaseFailed GameServerPhase = "Failed"
+)
+
+// Condition types set on a GameServer.
+const (
+	// GameServerConditionAvailable indicates whether the game server is ready to accept players.
+	GameServerConditionAvailable = "Available"
+
+	// GameServerConditionProgressing indicates whether the game server is being created, started, stopped or updated.
+	GameServerConditionProgressing = "Progressing"
+
+	// GameServerConditionDegraded indicates whether the game server failed to reach or maintain its desired state.
+	GameServerConditionDegraded = "Degraded"
+
+	// GameServerConditionStorageResized indicates whether the data volume has the size requested in spec.storage.size.
+	// It is False while the volume is expanded, or when its StorageClass does not support expansion.
+	GameServerConditionStorageResized = "StorageResized"
+
+	// GameServerConditionReplicasIgnored is set when spec.replicas requests more than one instance.
+	// A GameServer always runs a single instance, so the requested value is not honored.
+	GameServerConditionReplicasIgnored = "ReplicasIgnored"
+
+	// GameServerConditionIdle is set for game servers with spec.idle. It is True while the game server is stopped
+	// because no players were online for spec.idle.timeout, and Unknown when the players cannot be counted.
+	GameServerConditionIdle = "Idle"
+)
+
+// EndpointType describes how a GameServerEndpoint is reached.
+// +kubebuilder:validation:Enum=LoadBalancer;NodePort;ClusterIP
+type EndpointType string
+
+const (
+	// EndpointTypeLoadBalancer is an ingress IP or hostname assigned to a LoadBalancer service.
+	EndpointTypeLoadBalancer EndpointType = "LoadBalancer"
+
+	// EndpointTypeNodePort is an external IP of the node running the game server, combined with the node port.
+	EndpointTypeNodePort EndpointType = "NodePort"
+
+	// EndpointTypeClusterIP is the cluster IP of the service, only reachable from within the cluster.
+	EndpointTypeClusterIP EndpointType = "ClusterIP"
+)
+
+// GameServerEndpoint is an address that can be used to connect to a port of the game server.
+type GameServerEndpoint struct {
+	// Name is the name of the service port this endpoint belongs to.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Type describes how the endpoint is reached.
+	Type EndpointType `json:"type"`
+
+	// Address is the IP address or hostname to connect to.
+	Address string `json:"address"`
+
+	// Port is the port number to connect to.
+	Port int32 `json:"port"`
+
+	// Protocol is the protocol used by the port.
+	Protocol corev1.Protocol `json:"protocol"`
+}
+
 // GameServerStatus defines the observed state of GameServer.
 type GameServerStatus struct {
 	// For Kubernetes API conventions, see:
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
 
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is a high-level summary of the state of the game server.
+	// +optional
+	Phase GameServerPhase `json:"phase,omitempty"`
+
+	// Endpoints lists the addresses players and tooling can use to connect to the game server,
+	// computed from the Service for each of its ports. For each port, externally reachable endpoints are
+	// listed before the cluster IP.
+	// +listType=atomic
+	// +optional
+	Endpoints []GameServerEndpoint `json:"endpoints,omitempty"`
+
+	// Backup reports the state of scheduled backups.
+	// +optional
+	Backup *BackupStatus `json:"backup,omitempty"`
+
+	// Storage reports the state of the data volume.
+	// +optional
+	Storage *StorageStatus `json:"storage,omitempty"`
+
+	// Updates reports the state of scheduled updates.
+	// +optional
+	Updates *UpdateStatus `json:"updates,omitempty"`
+
+	// Server reports what the running game server answers when queried, for games the operator knows how to query.
+	// +optional
+	Server *ServerStatus `json:"server,omitempty"`
+
+	// Schedule reports whether spec.schedule runs the game server and when that changes next.
+	// +optional
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+
+	// Minecraft reports the state of spec.gameConfigs.minecraft.
+	// +optional
+	Minecraft *MinecraftStatus `json:"minecraft,omitempty"`
+
+	// ConfigHash is the hash of spec.linuxgsm.config, the contents of spec.configFiles and the Secrets referenced by
+	// spec.secrets and spec.rcon the pod template was built with, so changes to the ConfigMaps and Secrets restart
+	// the game server.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// LastPlayerSeen is when players were last seen online, for game servers with spec.idle.
+	// +optional
+	LastPlayerSeen *metav1.Time `json:"lastPlayerSeen,omitempty"`
+
 	// conditions represent the current state of the GameServer resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	//
@@ -193,8 +1008,302 @@ type GameServerStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ScheduleStatus reports the state of the game server according to its schedule.
+type ScheduleStatus struct {
+	// State is Running while a window of the schedule is open, and Stopped otherwise.
+	State GameServerState `json:"state"`
+
+	// NextTransitionTime is when the schedule next starts or stops the game server.
+	// +optional
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+
+	// NextState is the state the schedule changes to at NextTransitionTime.
+	// +optional
+	NextState GameServerState `json:"nextState,omitempty"`
+}
+
+// StorageStatus reports the state of the data volume.
+type StorageStatus struct {
+	// Size is the size requested on the persistent volume claim.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Capacity is the actual size of the volume. It is smaller than Size while the volume is being expanded.
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+}
+
+// UpdateStatus reports the state of scheduled updates.
+type UpdateStatus struct {
+	// LastCheckTime is when updates were last checked for.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// LastUpdateTime is when a new build was last installed.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// InstalledBuild is the installed build of the game, as reported by LinuxGSM.
+	// +optional
+	InstalledBuild string `json:"installedBuild,omitempty"`
+
+	// AvailableBuild is the latest available build of the game, as reported by LinuxGSM.
+	// +optional
+	AvailableBuild string `json:"availableBuild,omitempty"`
+
+	// UpdateAvailable reports whether the last check found an update that is not installed yet.
+	// +optional
+	UpdateAvailable bool `json:"updateAvailable,omitempty"`
+
+	// Running reports whether a check or update is running in the game server container.
+	// +optional
+	Running bool `json:"running,omitempty"`
+
+	// Message describes the outcome of the last check or update.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MinecraftStatus reports the state of the Minecraft specific configuration.
+type MinecraftStatus struct {
+	// Server reports the server the operator installs, when spec.gameConfigs.minecraft sets a version, loader or
+	// modpack.
+	// +optional
+	Server *MinecraftServerStatus `json:"server,omitempty"`
+
+	// Mods reports the files the mods of spec.gameConfigs.minecraft.mods resolved to, in the same order.
+	// +listType=atomic
+	// +optional
+	Mods []MinecraftModStatus `json:"mods,omitempty"`
+
+	// Players reports the UUIDs the players of spec.gameConfigs.minecraft.whitelist and ops resolved to.
+	// +listType=atomic
+	// +optional
+	Players []MinecraftPlayerStatus `json:"players,omitempty"`
+}
+
+// MinecraftPlayerStatus reports the UUID a player name resolved to, or why it could not be resolved.
+type MinecraftPlayerStatus struct {
+	// Name is the name of the player as listed in the spec.
+	Name string `json:"name"`
+
+	// Offline is whether the name was resolved to an offline UUID, because online mode is disabled.
+	// +optional
+	Offline bool `json:"offline,omitempty"`
+
+	// UUID is the UUID of the player.
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+
+	// Message reports why the name could not be resolved. Players that are not resolved are left out of
+	// whitelist.json and ops.json, and resolving them is retried.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastResolveTime is when the name was last resolved.
+	// +optional
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
+}
+
+// MinecraftModStatus reports the file a mod resolved to, or why it could not be resolved.
+type MinecraftModStatus struct {
+	// Mod is the mod as listed in the spec.
+	Mod MinecraftMod `json:"mod"`
+
+	// GameVersion is the Minecraft version the mod was resolved for.
+	// +optional
+	GameVersion string `json:"gameVersion,omitempty"`
+
+	// Loader is the loader the mod was resolved for.
+	// +optional
+	Loader MinecraftLoader `json:"loader,omitempty"`
+
+	// File is the file the mod resolved to, which is installed into the mods directory.
+	// +optional
+	File *MinecraftModFile `json:"file,omitempty"`
+
+	// Message reports why the mod could not be resolved. Mods that are not resolved are not installed,
+	// and resolving them is retried.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastResolveTime is when the mod was last resolved.
+	// +optional
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
+}
+
+// MinecraftServerRequest is the Minecraft server as specified in spec.gameConfigs.minecraft.
+type MinecraftServerRequest struct {
+	// Version is the Minecraft version as specified.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Loader is the loader as specified.
+	// +optional
+	Loader MinecraftLoader `json:"loader,omitempty"`
+
+	// LoaderVersion is the loader version as specified.
+	// +optional
+	LoaderVersion string `json:"loaderVersion,omitempty"`
+
+	// Modpack is the modpack as specified.
+	// +optional
+	Modpack *MinecraftMod `json:"modpack,omitempty"`
+}
+
+// MinecraftServerStatus reports the Minecraft server the spec resolved to, or why it could not be resolved.
+type MinecraftServerStatus struct {
+	// Request is the server as specified when it was resolved. The server is resolved again once the spec differs,
+	// so a new release of a loader or modpack without a pinned version does not restart the game server.
+	Request MinecraftServerRequest `json:"request"`
+
+	// Version is the resolved Minecraft version.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Loader is the resolved loader.
+	// +optional
+	Loader MinecraftLoader `json:"loader,omitempty"`
+
+	// LoaderVersion is the resolved loader version, empty for Vanilla.
+	// +optional
+	LoaderVersion string `json:"loaderVersion,omitempty"`
+
+	// File is the server jar, or the installer of the Forge and NeoForge servers.
+	// +optional
+	File *MinecraftModFile `json:"file,omitempty"`
+
+	// Modpack is the file the modpack resolved to.
+	// +optional
+	Modpack *MinecraftModpackFile `json:"modpack,omitempty"`
+
+	// Message reports why the server could not be resolved. The previously resolved server stays installed
+	// until it is, and resolving it is retried.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastResolveTime is when the server was last resolved.
+	// +optional
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
+}
+
+// MinecraftModpackFormat is how a modpack file is installed.
+// +kubebuilder:validation:Enum=Mrpack;ServerPack
+type MinecraftModpackFormat string
+
+const (
+	// MinecraftModpackFormatMrpack is a Modrinth .mrpack file, whose files are downloaded as listed in its index.
+	MinecraftModpackFormatMrpack MinecraftModpackFormat = "Mrpack"
+
+	// MinecraftModpackFormatServerPack is a zip of the server files, which are extracted into the server files.
+	MinecraftModpackFormatServerPack MinecraftModpackFormat = "ServerPack"
+)
+
+// MinecraftModpackFile is a resolved modpack file.
+type MinecraftModpackFile struct {
+	MinecraftModFile `json:",inline"`
+
+	// Format is how the modpack file is installed.
+	Format MinecraftModpackFormat `json:"format"`
+}
+
+// MinecraftModFile is a resolved mod file.
+type MinecraftModFile struct {
+	// Version is the version of the mod, the version number on Modrinth and the file ID on CurseForge.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Name is the name of the file in the mods directory.
+	Name string `json:"name"`
+
+	// URL is where the file is downloaded from.
+	URL string `json:"url"`
+
+	// SHA1 is the SHA-1 hash the downloaded file is checked against, if known.
+	// +optional
+	SHA1 string `json:"sha1,omitempty"`
+}
+
+// ServerStatus reports what the game server answers when queried through its Service.
+type ServerStatus struct {
+	// LastQueryTime is when the game server was last queried.
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// Name is the name of the server as shown in the server browser.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Map is the map or world that is loaded, for games that report it.
+	// +optional
+	Map string `json:"map,omitempty"`
+
+	// Version is the version of the game.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Players is the number of connected players, excluding bots.
+	Players int32 `json:"players"`
+
+	// MaxPlayers is the number of player slots.
+	// +optional
+	MaxPlayers int32 `json:"maxPlayers,omitempty"`
+
+	// PlayerNames lists the names of connected players as far as the game reports them.
+	// Minecraft only reports a sample of up to 12 players.
+	// +listType=atomic
+	// +optional
+	PlayerNames []string `json:"playerNames,omitempty"`
+
+	// Message describes why the last query failed. The other fields then report the last successful query.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BackupStatus reports the state of scheduled backups.
+type BackupStatus struct {
+	// LastScheduleTime is when the last backup was started.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is when the last successful backup finished.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastBackup is the archive created by the last successful backup.
+	// +optional
+	LastBackup *BackupArtifact `json:"lastBackup,omitempty"`
+
+	// LastSnapshot is the name of the VolumeSnapshot taken by the last successful Snapshot backup.
+	// Set it as spec.storage.fromSnapshot of a new GameServer to create a game server from it.
+	// +optional
+	LastSnapshot string `json:"lastSnapshot,omitempty"`
+}
+
+// BackupArtifact is a backup archive stored on a backup target.
+type BackupArtifact struct {
+	// Name is the file name of the archive on the backup target.
+	Name string `json:"name"`
+
+	// SizeBytes is the size of the archive in bytes.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// SHA256 is the hex encoded SHA-256 checksum of the archive.
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Game",type=string,JSONPath=`.spec.gameName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].reason`
+// +kubebuilder:printcolumn:name="Players",type=integer,JSONPath=`.status.server.players`
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.status.endpoints[0].address`,priority=1
+// +kubebuilder:printcolumn:name="Port",type=integer,JSONPath=`.status.endpoints[0].port`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // GameServer is the Schema for the gameservers API
 type GameServer struct {
@@ -0,0 +1,206 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GameServerCommandSpec defines the desired state of GameServerCommand
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new GameServerCommand instead"
+// +kubebuilder:validation:XValidation:rule="self.command == 'send' ? has(self.args) && size(self.args) > 0 : !has(self.args)",message="args are required for send and not supported for other commands"
+type GameServerCommandSpec struct {
+	// GameServerRef is the GameServer in the same namespace the command runs in.
+	// The game server must be running.
+	// +required
+	GameServerRef corev1.LocalObjectReference `json:"gameServerRef"`
+
+	// Command is the LinuxGSM command to run, e.g. 'details' or 'restart'.
+	// Starting and stopping the game server is done with spec.state of the GameServer instead.
+	// +required
+	Command GameServerCommandName `json:"command"`
+
+	// Args are passed to the command. 'send' takes the console command to send to the game server,
+	// e.g. 'say hello'.
+	// +kubebuilder:validation:MaxItems=1
+	// +kubebuilder:validation:items:MaxLength=1024
+	// +listType=atomic
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// TimeoutSeconds is how long the command may run before it is stopped and the GameServerCommand fails.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=300
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// GameServerCommandName is a LinuxGSM command that can be run in a game server.
+// +kubebuilder:validation:Enum=restart;details;send;backup;validate;update;check-update;force-update;monitor
+type GameServerCommandName string
+
+const (
+	// GameServerCommandRestart restarts the game, without restarting the game server pod.
+	GameServerCommandRestart GameServerCommandName = "restart"
+
+	// GameServerCommandDetails reports the configuration and state of the game server.
+	GameServerCommandDetails GameServerCommandName = "details"
+
+	// GameServerCommandSend sends a command to the console of the game server.
+	GameServerCommandSend GameServerCommandName = "send"
+
+	// GameServerCommandBackup takes a LinuxGSM backup, stored on the data volume.
+	GameServerCommandBackup GameServerCommandName = "backup"
+
+	// GameServerCommandValidate validates the game files with SteamCMD.
+	GameServerCommandValidate GameServerCommandName = "validate"
+
+	// GameServerCommandUpdate installs an available update of the game.
+	GameServerCommandUpdate GameServerCommandName = "update"
+
+	// GameServerCommandCheckUpdate checks whether an update of the game is available.
+	GameServerCommandCheckUpdate GameServerCommandName = "check-update"
+
+	// GameServerCommandForceUpdate reinstalls the latest build of the game, even when it is installed already.
+	GameServerCommandForceUpdate GameServerCommandName = "force-update"
+
+	// GameServerCommandMonitor checks whether the game is running and restarts it when it is not.
+	GameServerCommandMonitor GameServerCommandName = "monitor"
+)
+
+// GameServerCommandPhase is a high-level summary of where the GameServerCommand is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type GameServerCommandPhase string
+
+const (
+	// GameServerCommandPhasePending means the command waits for the game server to be ready,
+	// or for earlier commands of the same game server to finish.
+	GameServerCommandPhasePending GameServerCommandPhase = "Pending"
+
+	// GameServerCommandPhaseRunning means the command is running in the game server container.
+	GameServerCommandPhaseRunning GameServerCommandPhase = "Running"
+
+	// GameServerCommandPhaseSucceeded means the command exited with code 0.
+	GameServerCommandPhaseSucceeded GameServerCommandPhase = "Succeeded"
+
+	// GameServerCommandPhaseFailed means the command did not run, exited with a non-zero code or timed out.
+	GameServerCommandPhaseFailed GameServerCommandPhase = "Failed"
+)
+
+// Condition types set on a GameServerCommand.
+const (
+	// GameServerCommandConditionProgressing indicates whether the command is waiting or running.
+	GameServerCommandConditionProgressing = "Progressing"
+
+	// GameServerCommandConditionComplete indicates whether the command succeeded.
+	GameServerCommandConditionComplete = "Complete"
+
+	// GameServerCommandConditionFailed indicates whether the command failed.
+	GameServerCommandConditionFailed = "Failed"
+)
+
+// GameServerCommandStatus defines the observed state of GameServerCommand.
+type GameServerCommandStatus struct {
+	// Phase is a high-level summary of the state of the command.
+	// +optional
+	Phase GameServerCommandPhase `json:"phase,omitempty"`
+
+	// StartTime is when the command started running.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the command finished or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExitCode is the exit code of the command.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Stdout is the standard output of the command, without colors. Only the end of long output is kept.
+	// +optional
+	Stdout string `json:"stdout,omitempty"`
+
+	// Stderr is the standard error of the command, without colors. Only the end of long output is kept.
+	// +optional
+	Stderr string `json:"stderr,omitempty"`
+
+	// conditions represent the current state of the GameServerCommand resource.
+	//
+	// Condition types include:
+	// - "Progressing": the command is waiting or running
+	// - "Complete": the command succeeded
+	// - "Failed": the command failed
+	//
+	// The status of each condition is one of True, False, or Unknown.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="GameServer",type=string,JSONPath=`.spec.gameServerRef.name`
+// +kubebuilder:printcolumn:name="Command",type=string,JSONPath=`.spec.command`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Exit Code",type=integer,JSONPath=`.status.exitCode`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerCommand is the Schema for the gameservercommands API
+type GameServerCommand struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of GameServerCommand
+	// +required
+	Spec GameServerCommandSpec `json:"spec"`
+
+	// status defines the observed state of GameServerCommand
+	// +optional
+	Status GameServerCommandStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GameServerCommandList contains a list of GameServerCommand
+type GameServerCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []GameServerCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &GameServerCommand{}, &GameServerCommandList{})
+		return nil
+	})
+}
@@ -0,0 +1,174 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GameServerRestoreSpec defines the desired state of GameServerRestore
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable, create a new GameServerRestore instead"
+type GameServerRestoreSpec struct {
+	// GameServerRef is the GameServer in the same namespace whose data is restored.
+	// The game server is stopped during the restore and started again afterwards.
+	// +required
+	GameServerRef corev1.LocalObjectReference `json:"gameServerRef"`
+
+	// Backup is the backup archive to restore.
+	// +required
+	Backup RestoreBackupSource `json:"backup"`
+}
+
+// RestoreBackupSource is a backup archive to restore a game server from.
+type RestoreBackupSource struct {
+	// Name is the file name of the archive on the backup target, as reported in the
+	// status.backup.lastBackup.name of the GameServer.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^/]+\.tar\.gz$`
+	Name string `json:"name"`
+
+	// SHA256 is the expected hex encoded SHA-256 checksum of the archive.
+	// When empty, the archive is verified against the checksum file stored next to it by the backup.
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	// +optional
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Target is where the archive is stored. Defaults to the backup target of the GameServer,
+	// set it to restore a backup taken by another GameServer.
+	// +optional
+	Target *BackupTarget `json:"target,omitempty"`
+}
+
+// GameServerRestorePhase is a high-level summary of where the GameServerRestore is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Stopping;Restoring;Completed;Failed
+type GameServerRestorePhase string
+
+const (
+	// GameServerRestorePhasePending means the restore has not started yet.
+	GameServerRestorePhasePending GameServerRestorePhase = "Pending"
+
+	// GameServerRestorePhaseStopping means the game server is being stopped before its data is replaced.
+	GameServerRestorePhaseStopping GameServerRestorePhase = "Stopping"
+
+	// GameServerRestorePhaseRestoring means the restore Job is verifying and extracting the archive.
+	GameServerRestorePhaseRestoring GameServerRestorePhase = "Restoring"
+
+	// GameServerRestorePhaseCompleted means the data was restored and the game server is started again.
+	GameServerRestorePhaseCompleted GameServerRestorePhase = "Completed"
+
+	// GameServerRestorePhaseFailed means the restore did not run or did not finish,
+	// e.g. because the archive checksum did not match. The game server is started again.
+	GameServerRestorePhaseFailed GameServerRestorePhase = "Failed"
+)
+
+// Condition types set on a GameServerRestore.
+const (
+	// GameServerRestoreConditionGameServerStopped indicates whether the game server is stopped for the restore.
+	GameServerRestoreConditionGameServerStopped = "GameServerStopped"
+
+	// GameServerRestoreConditionProgressing indicates whether the restore is still running.
+	GameServerRestoreConditionProgressing = "Progressing"
+
+	// GameServerRestoreConditionComplete indicates whether the data was restored.
+	GameServerRestoreConditionComplete = "Complete"
+
+	// GameServerRestoreConditionFailed indicates whether the restore failed.
+	GameServerRestoreConditionFailed = "Failed"
+)
+
+// GameServerRestoreStatus defines the observed state of GameServerRestore.
+type GameServerRestoreStatus struct {
+	// Phase is a high-level summary of the state of the restore.
+	// +optional
+	Phase GameServerRestorePhase `json:"phase,omitempty"`
+
+	// StartTime is when the game server was stopped for the restore.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore completed or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// JobName is the name of the Job restoring the archive.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// conditions represent the current state of the GameServerRestore resource.
+	//
+	// Condition types include:
+	// - "GameServerStopped": the game server is stopped for the restore
+	// - "Progressing": the restore is still running
+	// - "Complete": the data was restored
+	// - "Failed": the restore failed
+	//
+	// The status of each condition is one of True, False, or Unknown.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="GameServer",type=string,JSONPath=`.spec.gameServerRef.name`
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backup.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GameServerRestore is the Schema for the gameserverrestores API
+type GameServerRestore struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of GameServerRestore
+	// +required
+	Spec GameServerRestoreSpec `json:"spec"`
+
+	// status defines the observed state of GameServerRestore
+	// +optional
+	Status GameServerRestoreStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GameServerRestoreList contains a list of GameServerRestore
+type GameServerRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []GameServerRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(s *runtime.Scheme) error {
+		s.AddKnownTypes(GroupVersion, &GameServerRestore{}, &GameServerRestoreList{})
+		return nil
+	})
+}
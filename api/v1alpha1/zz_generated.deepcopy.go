@@ -34,6 +34,125 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupArtifact) DeepCopyInto(out *BackupArtifact) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupArtifact.
+func (in *BackupArtifact) DeepCopy() *BackupArtifact {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupArtifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	in.Target.DeepCopyInto(&out.Target)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSpec.
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBackup != nil {
+		in, out := &in.LastBackup, &out.LastBackup
+		*out = new(BackupArtifact)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
+func (in *BackupStatus) DeepCopy() *BackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTarget) DeepCopyInto(out *BackupTarget) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(PersistentVolumeClaimBackupTarget)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTarget.
+func (in *BackupTarget) DeepCopy() *BackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigFile) DeepCopyInto(out *ConfigFile) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigFile.
+func (in *ConfigFile) DeepCopy() *ConfigFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GameConfigs) DeepCopyInto(out *GameConfigs) {
 	*out = *in
@@ -44,148 +163,1022 @@ func (in *GameConfigs) DeepCopyInto(out *GameConfigs) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameConfigs.
-func (in *GameConfigs) DeepCopy() *GameConfigs {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameConfigs.
+func (in *GameConfigs) DeepCopy() *GameConfigs {
+	if in == nil {
+		return nil
+	}
+	out := new(GameConfigs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServer) DeepCopyInto(out *GameServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServer.
+func (in *GameServer) DeepCopy() *GameServer {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerCommand) DeepCopyInto(out *GameServerCommand) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerCommand.
+func (in *GameServerCommand) DeepCopy() *GameServerCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServerCommand) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerCommandList) DeepCopyInto(out *GameServerCommandList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GameServerCommand, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerCommandList.
+func (in *GameServerCommandList) DeepCopy() *GameServerCommandList {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerCommandList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServerCommandList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerCommandSpec) DeepCopyInto(out *GameServerCommandSpec) {
+	*out = *in
+	out.GameServerRef = in.GameServerRef
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerCommandSpec.
+func (in *GameServerCommandSpec) DeepCopy() *GameServerCommandSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerCommandSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerCommandStatus) DeepCopyInto(out *GameServerCommandStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerCommandStatus.
+func (in *GameServerCommandStatus) DeepCopy() *GameServerCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerEndpoint) DeepCopyInto(out *GameServerEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerEndpoint.
+func (in *GameServerEndpoint) DeepCopy() *GameServerEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerList) DeepCopyInto(out *GameServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GameServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerList.
+func (in *GameServerList) DeepCopy() *GameServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerRestore) DeepCopyInto(out *GameServerRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerRestore.
+func (in *GameServerRestore) DeepCopy() *GameServerRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServerRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerRestoreList) DeepCopyInto(out *GameServerRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GameServerRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerRestoreList.
+func (in *GameServerRestoreList) DeepCopy() *GameServerRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GameServerRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerRestoreSpec) DeepCopyInto(out *GameServerRestoreSpec) {
+	*out = *in
+	out.GameServerRef = in.GameServerRef
+	in.Backup.DeepCopyInto(&out.Backup)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerRestoreSpec.
+func (in *GameServerRestoreSpec) DeepCopy() *GameServerRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerRestoreStatus) DeepCopyInto(out *GameServerRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerRestoreStatus.
+func (in *GameServerRestoreStatus) DeepCopy() *GameServerRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerSpec) DeepCopyInto(out *GameServerSpec) {
+	*out = *in
+	if in.GameConfigs != nil {
+		in, out := &in.GameConfigs, &out.GameConfigs
+		*out = new(GameConfigs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Updates != nil {
+		in, out := &in.Updates, &out.Updates
+		*out = new(UpdatesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RCON != nil {
+		in, out := &in.RCON, &out.RCON
+		*out = new(RCONSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(ShutdownSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Idle != nil {
+		in, out := &in.Idle, &out.Idle
+		*out = new(IdleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LinuxGSM != nil {
+		in, out := &in.LinuxGSM, &out.LinuxGSM
+		*out = new(LinuxGSMSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigFiles != nil {
+		in, out := &in.ConfigFiles, &out.ConfigFiles
+		*out = make([]ConfigFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = new(SecretsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerSpec.
+func (in *GameServerSpec) DeepCopy() *GameServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GameServerStatus) DeepCopyInto(out *GameServerStatus) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]GameServerEndpoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(StorageStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Updates != nil {
+		in, out := &in.Updates, &out.Updates
+		*out = new(UpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(ServerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Minecraft != nil {
+		in, out := &in.Minecraft, &out.Minecraft
+		*out = new(MinecraftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastPlayerSeen != nil {
+		in, out := &in.LastPlayerSeen, &out.LastPlayerSeen
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerStatus.
+func (in *GameServerStatus) DeepCopy() *GameServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GameServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdleSpec) DeepCopyInto(out *IdleSpec) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleSpec.
+func (in *IdleSpec) DeepCopy() *IdleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinuxGSMSpec) DeepCopyInto(out *LinuxGSMSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinuxGSMSpec.
+func (in *LinuxGSMSpec) DeepCopy() *LinuxGSMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LinuxGSMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftConfig) DeepCopyInto(out *MinecraftConfig) {
+	*out = *in
+	if in.Modpack != nil {
+		in, out := &in.Modpack, &out.Modpack
+		*out = new(MinecraftMod)
+		**out = **in
+	}
+	if in.Mods != nil {
+		in, out := &in.Mods, &out.Mods
+		*out = make([]MinecraftMod, len(*in))
+		copy(*out, *in)
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = new(MinecraftProperties)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Whitelist != nil {
+		in, out := &in.Whitelist, &out.Whitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ops != nil {
+		in, out := &in.Ops, &out.Ops
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftConfig.
+func (in *MinecraftConfig) DeepCopy() *MinecraftConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftMod) DeepCopyInto(out *MinecraftMod) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftMod.
+func (in *MinecraftMod) DeepCopy() *MinecraftMod {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftMod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftModFile) DeepCopyInto(out *MinecraftModFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftModFile.
+func (in *MinecraftModFile) DeepCopy() *MinecraftModFile {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftModFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftModStatus) DeepCopyInto(out *MinecraftModStatus) {
+	*out = *in
+	out.Mod = in.Mod
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(MinecraftModFile)
+		**out = **in
+	}
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftModStatus.
+func (in *MinecraftModStatus) DeepCopy() *MinecraftModStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftModStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftModpackFile) DeepCopyInto(out *MinecraftModpackFile) {
+	*out = *in
+	out.MinecraftModFile = in.MinecraftModFile
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftModpackFile.
+func (in *MinecraftModpackFile) DeepCopy() *MinecraftModpackFile {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftModpackFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftPlayerStatus) DeepCopyInto(out *MinecraftPlayerStatus) {
+	*out = *in
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftPlayerStatus.
+func (in *MinecraftPlayerStatus) DeepCopy() *MinecraftPlayerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftPlayerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftProperties) DeepCopyInto(out *MinecraftProperties) {
+	*out = *in
+	if in.MOTD != nil {
+		in, out := &in.MOTD, &out.MOTD
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxPlayers != nil {
+		in, out := &in.MaxPlayers, &out.MaxPlayers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ViewDistance != nil {
+		in, out := &in.ViewDistance, &out.ViewDistance
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SimulationDistance != nil {
+		in, out := &in.SimulationDistance, &out.SimulationDistance
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SpawnProtection != nil {
+		in, out := &in.SpawnProtection, &out.SpawnProtection
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Seed != nil {
+		in, out := &in.Seed, &out.Seed
+		*out = new(string)
+		**out = **in
+	}
+	if in.OnlineMode != nil {
+		in, out := &in.OnlineMode, &out.OnlineMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PVP != nil {
+		in, out := &in.PVP, &out.PVP
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Hardcore != nil {
+		in, out := &in.Hardcore, &out.Hardcore
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowFlight != nil {
+		in, out := &in.AllowFlight, &out.AllowFlight
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftProperties.
+func (in *MinecraftProperties) DeepCopy() *MinecraftProperties {
 	if in == nil {
 		return nil
 	}
-	out := new(GameConfigs)
+	out := new(MinecraftProperties)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GameServer) DeepCopyInto(out *GameServer) {
+func (in *MinecraftServerRequest) DeepCopyInto(out *MinecraftServerRequest) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Modpack != nil {
+		in, out := &in.Modpack, &out.Modpack
+		*out = new(MinecraftMod)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServer.
-func (in *GameServer) DeepCopy() *GameServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerRequest.
+func (in *MinecraftServerRequest) DeepCopy() *MinecraftServerRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(GameServer)
+	out := new(MinecraftServerRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GameServer) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftServerStatus) DeepCopyInto(out *MinecraftServerStatus) {
+	*out = *in
+	in.Request.DeepCopyInto(&out.Request)
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(MinecraftModFile)
+		**out = **in
 	}
-	return nil
+	if in.Modpack != nil {
+		in, out := &in.Modpack, &out.Modpack
+		*out = new(MinecraftModpackFile)
+		**out = **in
+	}
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerStatus.
+func (in *MinecraftServerStatus) DeepCopy() *MinecraftServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftServerStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GameServerList) DeepCopyInto(out *GameServerList) {
+func (in *MinecraftStatus) DeepCopyInto(out *MinecraftStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GameServer, len(*in))
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(MinecraftServerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mods != nil {
+		in, out := &in.Mods, &out.Mods
+		*out = make([]MinecraftModStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Players != nil {
+		in, out := &in.Players, &out.Players
+		*out = make([]MinecraftPlayerStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerList.
-func (in *GameServerList) DeepCopy() *GameServerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftStatus.
+func (in *MinecraftStatus) DeepCopy() *MinecraftStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GameServerList)
+	out := new(MinecraftStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GameServerList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimBackupTarget) DeepCopyInto(out *PersistentVolumeClaimBackupTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimBackupTarget.
+func (in *PersistentVolumeClaimBackupTarget) DeepCopy() *PersistentVolumeClaimBackupTarget {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(PersistentVolumeClaimBackupTarget)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GameServerSpec) DeepCopyInto(out *GameServerSpec) {
+func (in *ProbesSpec) DeepCopyInto(out *ProbesSpec) {
 	*out = *in
-	if in.GameConfigs != nil {
-		in, out := &in.GameConfigs, &out.GameConfigs
-		*out = new(GameConfigs)
-		(*in).DeepCopyInto(*out)
+	if in.StartupTimeout != nil {
+		in, out := &in.StartupTimeout, &out.StartupTimeout
+		*out = new(metav1.Duration)
+		**out = **in
 	}
-	if in.Storage != nil {
-		in, out := &in.Storage, &out.Storage
-		*out = new(StorageSpec)
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesSpec.
+func (in *ProbesSpec) DeepCopy() *ProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RCONSpec) DeepCopyInto(out *RCONSpec) {
+	*out = *in
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(ServiceSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RCONSpec.
+func (in *RCONSpec) DeepCopy() *RCONSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RCONSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreBackupSource) DeepCopyInto(out *RestoreBackupSource) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(BackupTarget)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreBackupSource.
+func (in *RestoreBackupSource) DeepCopy() *RestoreBackupSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreBackupSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupTarget) DeepCopyInto(out *S3BackupTarget) {
+	*out = *in
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupTarget.
+func (in *S3BackupTarget) DeepCopy() *S3BackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	if in.NextTransitionTime != nil {
+		in, out := &in.NextTransitionTime, &out.NextTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSource) DeepCopyInto(out *SecretSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerSpec.
-func (in *GameServerSpec) DeepCopy() *GameServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSource.
+func (in *SecretSource) DeepCopy() *SecretSource {
 	if in == nil {
 		return nil
 	}
-	out := new(GameServerSpec)
+	out := new(SecretSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GameServerStatus) DeepCopyInto(out *GameServerStatus) {
+func (in *SecretsSpec) DeepCopyInto(out *SecretsSpec) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ServerPassword != nil {
+		in, out := &in.ServerPassword, &out.ServerPassword
+		*out = new(SecretSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RCONPassword != nil {
+		in, out := &in.RCONPassword, &out.RCONPassword
+		*out = new(SecretSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GSLT != nil {
+		in, out := &in.GSLT, &out.GSLT
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdminPassword != nil {
+		in, out := &in.AdminPassword, &out.AdminPassword
+		*out = new(SecretSource)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GameServerStatus.
-func (in *GameServerStatus) DeepCopy() *GameServerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretsSpec.
+func (in *SecretsSpec) DeepCopy() *SecretsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GameServerStatus)
+	out := new(SecretsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MinecraftConfig) DeepCopyInto(out *MinecraftConfig) {
+func (in *ServerStatus) DeepCopyInto(out *ServerStatus) {
 	*out = *in
-	if in.Mods != nil {
-		in, out := &in.Mods, &out.Mods
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PlayerNames != nil {
+		in, out := &in.PlayerNames, &out.PlayerNames
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftConfig.
-func (in *MinecraftConfig) DeepCopy() *MinecraftConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatus.
+func (in *ServerStatus) DeepCopy() *ServerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MinecraftConfig)
+	out := new(ServerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -243,6 +1236,26 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShutdownSpec) DeepCopyInto(out *ShutdownSpec) {
+	*out = *in
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]metav1.Duration, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShutdownSpec.
+func (in *ShutdownSpec) DeepCopy() *ShutdownSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ShutdownSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
 	*out = *in
@@ -267,3 +1280,71 @@ func (in *StorageSpec) DeepCopy() *StorageSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageStatus) DeepCopyInto(out *StorageStatus) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageStatus.
+func (in *StorageStatus) DeepCopy() *StorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateStatus) DeepCopyInto(out *UpdateStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStatus.
+func (in *UpdateStatus) DeepCopy() *UpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatesSpec) DeepCopyInto(out *UpdatesSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatesSpec.
+func (in *UpdatesSpec) DeepCopy() *UpdatesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
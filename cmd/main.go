@@ -34,10 +34,15 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -46,6 +51,13 @@ import (
 
 	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
 	"github.com/idebeijer/gameserver-operator/internal/controller"
+	webhookgamesv1alpha1 "github.com/idebeijer/gameserver-operator/internal/webhook/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+	"github.com/idebeijer/gameserver-operator/pkg/podexec"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
 	versions "github.com/idebeijer/gameserver-operator/pkg/versions"
 	// +kubebuilder:scaffold:imports
 )
@@ -71,6 +83,9 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var wakeProxyAddress string
+	var wakeProxyNodeName string
+	var curseForgeAPIKey string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -92,6 +107,15 @@ func main() {
 	opts := zap.Options{
 		Development: true,
 	}
+	flag.StringVar(&wakeProxyAddress, "wake-proxy-address", "",
+		"The IP address of the pod of the manager, which players connecting to idle game servers are routed to. "+
+			"Game servers do not wake on connect if not set.")
+	flag.StringVar(&wakeProxyNodeName, "wake-proxy-node-name", "",
+		"The name of the node the manager runs on, which Services with externalTrafficPolicy Local need to route "+
+			"players to the wake proxy.")
+	flag.StringVar(&curseForgeAPIKey, "curseforge-api-key", os.Getenv("CURSEFORGE_API_KEY"),
+		"The key for the CurseForge API, defaulting to the CURSEFORGE_API_KEY environment variable. "+
+			"Minecraft mods from CurseForge are not installed if not set.")
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
@@ -190,19 +214,78 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+
+		// Only the EndpointSlices routing Services to the wake proxy are watched, not those of every Service.
+		// ConfigMaps and Secrets are only watched for their metadata, as config files and passwords can come from any
+		// of them, without their managed fields.
+		Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+			&discoveryv1.EndpointSlice{}: {Label: labels.SelectorFromSet(labels.Set{
+				discoveryv1.LabelManagedBy: specs.WakeEndpointSliceManager,
+			})},
+			&corev1.ConfigMap{}: {Transform: cache.TransformStripManagedFields()},
+			&corev1.Secret{}:    {Transform: cache.TransformStripManagedFields()},
+		}},
+		// ConfigMaps and Secrets are read from the API server, so the contents of every ConfigMap and Secret in the
+		// cluster are not kept in memory.
+		Client: client.Options{Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+		}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	executor, err := podexec.NewExecutor(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create pod executor")
+		os.Exit(1)
+	}
+
+	modResolver := mods.NewResolver(curseForgeAPIKey)
 	if err := (&controller.GameServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		APIReader:  mgr.GetAPIReader(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("gameserver-controller"),
+		Executor:   executor,
+		RCONDialer: rcon.Dial,
+		Querier:    query.Query,
+
+		ModResolver:       modResolver,
+		ServerResolver:    minecraft.NewServerResolver(modResolver),
+		PlayerResolver:    minecraft.NewPlayerResolver(),
+		WakeProxyAddress:  wakeProxyAddress,
+		WakeProxyNodeName: wakeProxyNodeName,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GameServer")
 		os.Exit(1)
 	}
+	if err := (&controller.GameServerRestoreReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorder("gameserverrestore-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GameServerRestore")
+		os.Exit(1)
+	}
+	if err := (&controller.GameServerCommandReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorder("gameservercommand-controller"),
+		Executor:   executor,
+		RCONDialer: rcon.Dial,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GameServerCommand")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookgamesv1alpha1.SetupGameServerWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GameServer")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// backgroundPollInterval is how often a reconcile checks whether the background task of an object is done.
+const backgroundPollInterval = 5 * time.Second
+
+// backgroundTasks runs work that takes longer than a reconcile should, like LinuxGSM updates and the warnings before a
+// restart, in the background, so it does not block the workers of the controller. A reconcile starts the task of an
+// object, and a later reconcile records its result once it is done and forgets the task. Tasks are kept in memory,
+// so a task that is tracked as running in the status of an object but not known here was interrupted, e.g. by a
+// restart of the operator.
+type backgroundTasks[T any] struct {
+	mu    sync.Mutex
+	tasks map[types.UID]*backgroundTask[T]
+}
+
+type backgroundTask[T any] struct {
+	done   bool
+	result T
+}
+
+// start runs the task of the object with the UID in the background. The task outlives the reconcile that starts it,
+// so it is not cancelled with its context.
+func (b *backgroundTasks[T]) start(ctx context.Context, uid types.UID, run func(ctx context.Context) T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tasks == nil {
+		b.tasks = map[types.UID]*backgroundTask[T]{}
+	}
+	task := &backgroundTask[T]{}
+	b.tasks[uid] = task
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		result := run(ctx)
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		task.result, task.done = result, true
+	}()
+}
+
+// result returns the result of the task of the object with the UID. It reports whether the object has a task and
+// whether it is done.
+func (b *backgroundTasks[T]) result(uid types.UID) (result T, found, done bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	task, found := b.tasks[uid]
+	if !found || !task.done {
+		return result, found, false
+	}
+	return task.result, true, true
+}
+
+// forget drops the task of the object with the UID, once its result is recorded or the object is deleted. A task that
+// is still running finishes without its result being recorded.
+func (b *backgroundTasks[T]) forget(uid types.UID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tasks, uid)
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for backup events.
+const (
+	reasonBackingUp          = "BackingUp"
+	reasonFinalBackupStarted = "FinalBackupStarted"
+	reasonFinalBackupFailed  = "FinalBackupFailed"
+)
+
+// reconcileGameServerBackupCronJob applies the CronJob taking scheduled backups,
+// or removes it when Archive backups are no longer configured.
+func (r *GameServerReconciler) reconcileGameServerBackupCronJob(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	// Backups read the data volume, without persistent storage a backup job would never start
+	// and keep the game server stopped.
+	cronJobApply := specs.BuildGameServerBackupCronJob(gs)
+	if cronJobApply == nil || !specs.LinuxGSMStorageEnabled(gs) {
+		cronJob := &batchv1.CronJob{}
+		key := types.NamespacedName{Name: specs.GameServerBackupCronJobName(gs), Namespace: gs.Namespace}
+		found, err := r.getOptional(ctx, key, cronJob)
+		if err != nil || !found || !metav1.IsControlledBy(cronJob, gs) {
+			return err
+		}
+		if err := r.Delete(ctx, cronJob); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete backup CronJob: %w", err)
+		}
+		return nil
+	}
+
+	cronJobApply.WithOwnerReferences(gameServerOwnerReference(gs))
+
+	return r.Apply(ctx, cronJobApply,
+		client.FieldOwner(fieldManagerGameServer),
+		client.ForceOwnership,
+	)
+}
+
+// reconcileFinalBackup takes a backup before a deleted GameServer releases its storage,
+// when configured to, and reports whether the backup is done.
+// A failed backup is reported as an event but does not block the deletion.
+func (r *GameServerReconciler) reconcileFinalBackup(ctx context.Context, gs *gamesv1alpha1.GameServer) (bool, error) {
+	if gs.Spec.Backup == nil || !gs.Spec.Backup.BeforeDeletion || !specs.LinuxGSMStorageEnabled(gs) {
+		return true, nil
+	}
+	if specs.GameServerBackupMode(gs) == gamesv1alpha1.BackupModeSnapshot {
+		return r.reconcileFinalSnapshot(ctx, gs)
+	}
+
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Name: specs.GameServerFinalBackupJobName(gs), Namespace: gs.Namespace}
+	found, err := r.getOptional(ctx, key, job)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		jobApply := specs.BuildGameServerFinalBackupJob(gs)
+		jobApply.WithOwnerReferences(gameServerOwnerReference(gs))
+		if err := r.Apply(ctx, jobApply,
+			client.FieldOwner(fieldManagerGameServer),
+			client.ForceOwnership,
+		); err != nil {
+			return false, fmt.Errorf("failed to create final backup Job: %w", err)
+		}
+		r.recordEvent(gs, corev1.EventTypeNormal, reasonFinalBackupStarted, "Delete",
+			"Taking a final backup before deletion")
+		return false, nil
+	}
+
+	switch {
+	case jobConditionTrue(job, batchv1.JobComplete):
+		return true, nil
+	case jobConditionTrue(job, batchv1.JobFailed):
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonFinalBackupFailed, "Delete",
+			"Final backup Job %s failed, continuing with the deletion", job.Name)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// listBackupJobs returns the scheduled and final backup Jobs of the game server.
+func (r *GameServerReconciler) listBackupJobs(ctx context.Context, gs *gamesv1alpha1.GameServer) ([]batchv1.Job, error) {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs,
+		client.InNamespace(gs.Namespace),
+		client.MatchingLabels(specs.GameServerBackupLabels(gs)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to list backup Jobs: %w", err)
+	}
+	return jobs.Items, nil
+}
+
+// lastSuccessfulBackupPod returns the pod of the most recent successful backup Job, which describes
+// the created archive in its termination message.
+func (r *GameServerReconciler) lastSuccessfulBackupPod(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	jobs []batchv1.Job,
+) (*corev1.Pod, error) {
+	job := lastSuccessfulJob(jobs)
+	if job == nil {
+		return nil, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(gs.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: job.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list backup pods: %w", err)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodSucceeded {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// buildBackupStatus derives the backup status from the backup Jobs. The archive of the last backup is
+// read from the termination message of its pod and kept once the Job and its pod are cleaned up.
+func buildBackupStatus(
+	current *gamesv1alpha1.BackupStatus,
+	jobs []batchv1.Job,
+	lastPod *corev1.Pod,
+) *gamesv1alpha1.BackupStatus {
+	if len(jobs) == 0 {
+		return current
+	}
+
+	status := &gamesv1alpha1.BackupStatus{}
+	if current != nil {
+		status = current.DeepCopy()
+	}
+
+	for i := range jobs {
+		created := jobs[i].CreationTimestamp
+		if status.LastScheduleTime == nil || status.LastScheduleTime.Before(&created) {
+			status.LastScheduleTime = &created
+		}
+	}
+
+	job := lastSuccessfulJob(jobs)
+	if job == nil {
+		return status
+	}
+
+	if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(job.Status.CompletionTime) {
+		status.LastSuccessfulTime = job.Status.CompletionTime.DeepCopy()
+		status.LastBackup = nil
+	}
+	if status.LastBackup == nil && lastPod != nil {
+		status.LastBackup = backupArtifactFromPod(lastPod)
+	}
+
+	return status
+}
+
+// backupArtifactFromPod parses the archive description the backup script writes as termination message.
+func backupArtifactFromPod(pod *corev1.Pod) *gamesv1alpha1.BackupArtifact {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+			continue
+		}
+		artifact := &gamesv1alpha1.BackupArtifact{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(cs.State.Terminated.Message)), artifact); err != nil {
+			return nil
+		}
+		return artifact
+	}
+	return nil
+}
+
+// backupInProgress reports whether one of the backup Jobs is running, which keeps the game server stopped.
+func backupInProgress(jobs []batchv1.Job, now time.Time) bool {
+	for i := range jobs {
+		if jobRunning(&jobs[i], now) {
+			return true
+		}
+	}
+	return false
+}
+
+// jobRunning reports whether a backup or restore Job has not finished. The Job controller fails Jobs that run past
+// their deadline; Jobs that earlier versions of the operator created without one stop counting as running once they
+// ran for specs.BackupJobDeadline.
+func jobRunning(job *batchv1.Job, now time.Time) bool {
+	if jobConditionTrue(job, batchv1.JobComplete) || jobConditionTrue(job, batchv1.JobFailed) {
+		return false
+	}
+	if job.Spec.ActiveDeadlineSeconds != nil {
+		return true
+	}
+	started := job.CreationTimestamp.Time
+	if job.Status.StartTime != nil {
+		started = job.Status.StartTime.Time
+	}
+	return now.Before(started.Add(specs.BackupJobDeadline))
+}
+
+func lastSuccessfulJob(jobs []batchv1.Job) *batchv1.Job {
+	var last *batchv1.Job
+	for i := range jobs {
+		job := &jobs[i]
+		if !jobConditionTrue(job, batchv1.JobComplete) || job.Status.CompletionTime == nil {
+			continue
+		}
+		if last == nil || last.Status.CompletionTime.Before(job.Status.CompletionTime) {
+			last = job
+		}
+	}
+	return last
+}
+
+func jobConditionTrue(job *batchv1.Job, condType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == condType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServer backups", func() {
+	start := metav1.NewTime(time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC))
+
+	newJob := func(name string, created metav1.Time, condType batchv1.JobConditionType) batchv1.Job {
+		job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: created}}
+		if condType != "" {
+			completed := metav1.NewTime(created.Add(10 * time.Minute))
+			job.Status.CompletionTime = &completed
+			job.Status.Conditions = []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+
+	succeededPod := func(message string) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "backup",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+			}},
+		}}
+	}
+
+	It("considers a backup in progress until its Job finished", func() {
+		now := start.Add(25 * time.Hour)
+		Expect(backupInProgress(nil, now)).To(BeFalse())
+		Expect(backupInProgress([]batchv1.Job{newJob("a", start, batchv1.JobComplete)}, now)).To(BeFalse())
+		Expect(backupInProgress([]batchv1.Job{newJob("a", start, batchv1.JobFailed)}, now)).To(BeFalse())
+		Expect(backupInProgress([]batchv1.Job{
+			newJob("a", start, batchv1.JobComplete),
+			newJob("b", metav1.NewTime(start.Add(24*time.Hour)), ""),
+		}, now)).To(BeTrue())
+	})
+
+	It("stops waiting for a stuck Job without a deadline once it would have passed", func() {
+		stuck := newJob("stuck", start, "")
+		Expect(backupInProgress([]batchv1.Job{stuck}, start.Add(specs.BackupJobDeadline-time.Minute))).To(BeTrue())
+		Expect(backupInProgress([]batchv1.Job{stuck}, start.Add(specs.BackupJobDeadline))).To(BeFalse())
+
+		By("leaving Jobs with a deadline to the Job controller")
+		stuck.Spec.ActiveDeadlineSeconds = new(int64(specs.BackupJobDeadline.Seconds()))
+		Expect(backupInProgress([]batchv1.Job{stuck}, start.Add(2*specs.BackupJobDeadline))).To(BeTrue())
+	})
+
+	It("records the archive of the last successful backup", func() {
+		later := metav1.NewTime(start.Add(24 * time.Hour))
+		jobs := []batchv1.Job{
+			newJob("a", start, batchv1.JobComplete),
+			newJob("b", later, batchv1.JobComplete),
+			newJob("c", metav1.NewTime(later.Add(24*time.Hour)), batchv1.JobFailed),
+		}
+		pod := succeededPod(`{"name":"example-20260103040000.tar.gz","sizeBytes":1048576,"sha256":"abc123"}`)
+
+		status := buildBackupStatus(nil, jobs, pod)
+		Expect(status).NotTo(BeNil())
+		Expect(status.LastScheduleTime.Time).To(Equal(later.Add(24 * time.Hour)))
+		Expect(status.LastSuccessfulTime.Time).To(Equal(later.Add(10 * time.Minute)))
+		Expect(status.LastBackup).To(Equal(&gamesv1alpha1.BackupArtifact{
+			Name:      "example-20260103040000.tar.gz",
+			SizeBytes: 1048576,
+			SHA256:    "abc123",
+		}))
+
+		By("keeping the recorded archive once the Jobs are cleaned up")
+		Expect(buildBackupStatus(status, nil, nil)).To(Equal(status))
+	})
+
+	It("ignores termination messages that do not describe an archive", func() {
+		Expect(backupArtifactFromPod(succeededPod("tar: short read"))).To(BeNil())
+	})
+})
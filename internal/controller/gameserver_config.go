@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const reasonConfigFileNotFound = "ConfigFileNotFound"
+
+// reconcileGameServerConfig hashes spec.linuxgsm.config, the contents of spec.configFiles and the passwords and tokens
+// of spec.secrets and spec.rcon into status.configHash, which annotates the pod template, so the game server restarts
+// when the ConfigMaps and Secrets they are read from change. Config files that cannot be read are reported as an
+// event and left out of the hash; the pod does not start until they exist, unless they are optional. Missing
+// passwords and tokens are left out as well, the kubelet reports them.
+func (r *GameServerReconciler) reconcileGameServerConfig(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	hash := sha256.New()
+	hashed := false
+	if gs.Spec.LinuxGSM != nil && len(gs.Spec.LinuxGSM.Config) > 0 {
+		for _, name := range slices.Sorted(maps.Keys(gs.Spec.LinuxGSM.Config)) {
+			_, _ = fmt.Fprintf(hash, "config\x00%s\x00%s\x00", name, gs.Spec.LinuxGSM.Config[name])
+		}
+		hashed = true
+	}
+	if specs.LinuxGSMStorageEnabled(gs) {
+		for _, file := range gs.Spec.ConfigFiles {
+			content, found, err := r.getConfigFile(ctx, gs, file)
+			if err != nil {
+				return err
+			}
+			if !found {
+				r.recordEvent(gs, corev1.EventTypeWarning, reasonConfigFileNotFound, "ReadConfigFile",
+					"The source of config file %s does not exist", file.Path)
+				continue
+			}
+			_, _ = fmt.Fprintf(hash, "file\x00%s\x00%s\x00%d\x00", file.Path, file.Mode, len(content))
+			_, _ = hash.Write(content)
+			hashed = true
+		}
+	}
+	for _, ref := range specs.GameServerSecretRefs(gs) {
+		content, found, err := r.getSecretKey(ctx, gs.Namespace, ref)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		_, _ = fmt.Fprintf(hash, "secret\x00%s\x00%s\x00%d\x00", ref.Name, ref.Key, len(content))
+		_, _ = hash.Write(content)
+		hashed = true
+	}
+
+	configHash := ""
+	if hashed {
+		configHash = hex.EncodeToString(hash.Sum(nil))
+	}
+	if gs.Status.ConfigHash == configHash {
+		return nil
+	}
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status.ConfigHash = configHash
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer config hash: %w", err)
+	}
+	return nil
+}
+
+// getFromAPI gets an object that is not cached, like the ConfigMaps and Secrets of config files, from the API server.
+// It reports whether the object exists.
+func (r *GameServerReconciler) getFromAPI(ctx context.Context, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := r.apiReader().Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %T %s: %w", obj, key, err)
+	}
+	return true, nil
+}
+
+// getConfigFile returns the content of the key of the ConfigMap or Secret of a config file, and whether it exists.
+func (r *GameServerReconciler) getConfigFile(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	file gamesv1alpha1.ConfigFile,
+) ([]byte, bool, error) {
+	switch {
+	case file.ConfigMapKeyRef != nil:
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Name: file.ConfigMapKeyRef.Name, Namespace: gs.Namespace}
+		found, err := r.getFromAPI(ctx, key, configMap)
+		if err != nil || !found {
+			return nil, false, err
+		}
+		if data, ok := configMap.Data[file.ConfigMapKeyRef.Key]; ok {
+			return []byte(data), true, nil
+		}
+		data, ok := configMap.BinaryData[file.ConfigMapKeyRef.Key]
+		return data, ok, nil
+	case file.SecretKeyRef != nil:
+		return r.getSecretKey(ctx, gs.Namespace, file.SecretKeyRef)
+	}
+	return nil, false, nil
+}
+
+// getSecretKey returns the content of the key of a Secret, and whether it exists.
+func (r *GameServerReconciler) getSecretKey(
+	ctx context.Context,
+	namespace string,
+	ref *corev1.SecretKeySelector,
+) ([]byte, bool, error) {
+	secret := &corev1.Secret{}
+	found, err := r.getFromAPI(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	data, ok := secret.Data[ref.Key]
+	return data, ok, nil
+}
+
+// gameServersForConfigMap maps a ConfigMap to the GameServers in its namespace with a config file from it.
+func (r *GameServerReconciler) gameServersForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.gameServersForConfig(ctx, obj, false)
+}
+
+// gameServersForSecret maps a Secret to the GameServers in its namespace with a config file, passwords or tokens
+// from it.
+func (r *GameServerReconciler) gameServersForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.gameServersForConfig(ctx, obj, true)
+}
+
+// gameServersForConfig maps the metadata of a ConfigMap, or a Secret when secret is set, to the GameServers in its
+// namespace reading from it.
+func (r *GameServerReconciler) gameServersForConfig(
+	ctx context.Context,
+	obj client.Object,
+	secret bool,
+) []reconcile.Request {
+	gameServers := &gamesv1alpha1.GameServerList{}
+	if err := r.List(ctx, gameServers, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range gameServers.Items {
+		if gameServerReadsConfig(&gameServers.Items[i], obj.GetName(), secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: gameServers.Items[i].Name, Namespace: obj.GetNamespace()},
+			})
+		}
+	}
+	return requests
+}
+
+// gameServerReadsConfig reports whether the game server reads config files, or passwords and tokens when secret is
+// set, from the ConfigMap or Secret named name.
+func gameServerReadsConfig(gs *gamesv1alpha1.GameServer, name string, secret bool) bool {
+	for _, file := range gs.Spec.ConfigFiles {
+		if (!secret && file.ConfigMapKeyRef != nil && file.ConfigMapKeyRef.Name == name) ||
+			(secret && file.SecretKeyRef != nil && file.SecretKeyRef.Name == name) {
+			return true
+		}
+	}
+	if secret {
+		for _, ref := range specs.GameServerSecretRefs(gs) {
+			if ref.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServer config", func() {
+	const name = "config-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+	var reconciler *GameServerReconciler
+
+	reconcileGameServer := func() *gamesv1alpha1.GameServer {
+		GinkgoHelper()
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		return gs
+	}
+
+	podAnnotations := func() map[string]string {
+		GinkgoHelper()
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		return sts.Spec.Template.Annotations
+	}
+
+	BeforeEach(func() {
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+		}
+
+		Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			Data:       map[string]string{"server.cfg": "hostname \"Kube\"\n"},
+		})).To(Succeed())
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "cs2"
+			gs.Spec.ConfigFiles = []gamesv1alpha1.ConfigFile{{
+				Path: "serverfiles/game/csgo/cfg/server.cfg",
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Key:                  "server.cfg",
+				},
+			}}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("restarts the game server when the contents of its config files change", func() {
+		gs := reconcileGameServer()
+		Expect(gs.Status.ConfigHash).NotTo(BeEmpty())
+		Expect(podAnnotations()).To(HaveKeyWithValue(specs.ConfigHashAnnotation, gs.Status.ConfigHash))
+		previous := gs.Status.ConfigHash
+
+		By("keeping the hash while nothing changes")
+		gs = reconcileGameServer()
+		Expect(gs.Status.ConfigHash).To(Equal(previous))
+
+		By("changing the hash with the ConfigMap")
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, key, configMap)).To(Succeed())
+		configMap.Data["server.cfg"] = "hostname \"Kubernetes\"\n"
+		Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+		metadata := &metav1.PartialObjectMetadata{ObjectMeta: configMap.ObjectMeta}
+		Expect(reconciler.gameServersForConfigMap(ctx, metadata)).To(ConsistOf(reconcile.Request{NamespacedName: key}))
+		Expect(reconciler.gameServersForSecret(ctx, metadata)).To(BeEmpty())
+
+		By("reading the ConfigMap from the API server rather than the cache")
+		reconciler.Client = staleCacheClient{Client: k8sClient}
+		reconciler.APIReader = k8sClient
+		gs = reconcileGameServer()
+		Expect(gs.Status.ConfigHash).NotTo(Equal(previous))
+		Expect(podAnnotations()).To(HaveKeyWithValue(specs.ConfigHashAnnotation, gs.Status.ConfigHash))
+	})
+
+	It("restarts the game server when its passwords change", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-password", Namespace: testNamespace},
+			Data:       map[string][]byte{"password": []byte("hunter2")},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		DeferCleanup(func() { Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed()) })
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.Secrets = &gamesv1alpha1.SecretsSpec{
+			ServerPassword: &gamesv1alpha1.SecretSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+				Key:                  "password",
+			}},
+		}
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		previous := reconcileGameServer().Status.ConfigHash
+
+		secret.Data["password"] = []byte("correct horse battery staple")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+		Expect(reconciler.gameServersForSecret(ctx, &metav1.PartialObjectMetadata{ObjectMeta: secret.ObjectMeta})).
+			To(ConsistOf(reconcile.Request{NamespacedName: key}))
+
+		gs = reconcileGameServer()
+		Expect(gs.Status.ConfigHash).NotTo(Equal(previous))
+		Expect(podAnnotations()).To(HaveKeyWithValue(specs.ConfigHashAnnotation, gs.Status.ConfigHash))
+	})
+
+	It("leaves config files that do not exist out of the hash", func() {
+		Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		})).To(Succeed())
+
+		gs := reconcileGameServer()
+		Expect(gs.Status.ConfigHash).To(BeEmpty())
+		Expect(podAnnotations()).NotTo(HaveKey(specs.ConfigHashAnnotation))
+	})
+})
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/podexec"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for console events.
+const (
+	reasonConsoleFailed = "ConsoleFailed"
+)
+
+// consoleTimeout bounds connecting to the console and running a command.
+const consoleTimeout = 10 * time.Second
+
+// errRCONUnreachable is returned when the remote console of the game server cannot be connected to.
+var errRCONUnreachable = errors.New("the remote console is unreachable")
+
+// gameServerConsole sends console commands to running game servers, over RCON when it is configured and reachable,
+// and to the LinuxGSM console otherwise.
+type gameServerConsole struct {
+	client     client.Client
+	executor   podexec.Executor
+	rconDialer rcon.Dialer
+}
+
+// exec runs a console command in the game server and returns its response, which is only known over RCON.
+// ran is false when the game server is not running or its console cannot be reached.
+func (c gameServerConsole) exec(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	command string,
+) (response string, ran bool, err error) {
+	pod := &corev1.Pod{}
+	key := types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}
+	if err := c.client.Get(ctx, key, pod); err != nil {
+		return "", false, client.IgnoreNotFound(err)
+	}
+	if !podReady(pod) {
+		return "", false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+
+	if protocol, port := specs.GameServerRCON(gs); port != 0 && c.rconDialer != nil && pod.Status.PodIP != "" {
+		response, err := c.execRCON(ctx, gs, protocol, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))), command)
+		if !errors.Is(err, errRCONUnreachable) {
+			return response, err == nil, err
+		}
+		// Games keeping the RCON settings in a config file of their own only enable RCON once they created it on
+		// their first start, so the LinuxGSM console is used until then.
+		logf.FromContext(ctx).Info("Sending the console command to the LinuxGSM console", "reason", err.Error())
+	}
+
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok || c.executor == nil {
+		return "", false, nil
+	}
+	result, err := c.executor.Exec(ctx, gs.Namespace, pod.Name, specs.GameServerContainerName,
+		game.Command(linuxgsm.CommandSend, command))
+	if err != nil {
+		return "", false, err
+	}
+	if result.ExitCode != 0 {
+		output := linuxgsm.CleanOutput(result.Stdout + result.Stderr)
+		return "", false, fmt.Errorf("%s exited with code %d: %s", linuxgsm.CommandSend, result.ExitCode, lastLine(output))
+	}
+	return "", true, nil
+}
+
+func (c gameServerConsole) execRCON(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	protocol rcon.Protocol,
+	address, command string,
+) (string, error) {
+	password, err := c.rconPassword(ctx, gs)
+	if err != nil {
+		return "", err
+	}
+	rconClient, err := c.rconDialer(ctx, protocol, address, password)
+	if errors.Is(err, rcon.ErrAuthFailed) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errRCONUnreachable, err)
+	}
+	defer func() { _ = rconClient.Close() }()
+	return rconClient.Execute(ctx, command)
+}
+
+// rconPassword reads the RCON password from the Secret referenced by the GameServer.
+func (c gameServerConsole) rconPassword(ctx context.Context, gs *gamesv1alpha1.GameServer) (string, error) {
+	ref := specs.GameServerRCONPasswordSecretRef(gs)
+	if ref == nil {
+		return "", errors.New("no RCON password is configured")
+	}
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: gs.Namespace}, secret); err != nil {
+		return "", fmt.Errorf("failed to get RCON password Secret: %w", err)
+	}
+	password, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("RCON password Secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return string(password), nil
+}
+
+// save saves the world of the game server, for games with a save command.
+func (c gameServerConsole) save(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	command := specs.GameServerSaveCommand(gs)
+	if command == "" {
+		return nil
+	}
+	_, _, err := c.exec(ctx, gs, command)
+	return err
+}
+
+// announce sends a message to the players of the game server, for games with an announce command.
+func (c gameServerConsole) announce(ctx context.Context, gs *gamesv1alpha1.GameServer, message string) error {
+	command := specs.GameServerAnnounceCommand(gs, message)
+	if command == "" {
+		return nil
+	}
+	_, _, err := c.exec(ctx, gs, command)
+	return err
+}
+
+// warnPlayers warns the players that the game server stops or restarts, on the warnings of spec.shutdown,
+// and saves the world. It returns once the last warning has passed. Failed console commands do not stop
+// the warnings and are returned together.
+func (c gameServerConsole) warnPlayers(ctx context.Context, gs *gamesv1alpha1.GameServer, event string) error {
+	var errs []error
+	warnings := specs.GameServerShutdownWarnings(gs)
+	for i, warning := range warnings {
+		if err := c.announce(ctx, gs, specs.ShutdownWarning(event, warning)); err != nil {
+			errs = append(errs, err)
+		}
+
+		next := time.Duration(0)
+		if i+1 < len(warnings) {
+			next = warnings[i+1]
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(warning - next):
+		}
+	}
+
+	if err := c.save(ctx, gs); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// console returns the console of the game servers reconciled by r.
+func (r *GameServerReconciler) console() gameServerConsole {
+	return gameServerConsole{client: r.Client, executor: r.Executor, rconDialer: r.RCONDialer}
+}
+
+// saveWorld saves the world of the running game server. A failed save is recorded as an event rather than
+// returned, as games also save periodically on their own.
+func (r *GameServerReconciler) saveWorld(ctx context.Context, gs *gamesv1alpha1.GameServer) {
+	if err := r.console().save(ctx, gs); err != nil {
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonConsoleFailed, "Save", "Saving the world failed: %v", err)
+	}
+}
+
+// warnPlayers warns the players before the operator restarts the running game server. Failures are recorded
+// as an event rather than returned, so they never block the restart.
+func (r *GameServerReconciler) warnPlayers(ctx context.Context, gs *gamesv1alpha1.GameServer, event string) {
+	if err := r.console().warnPlayers(ctx, gs, event); err != nil {
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonConsoleFailed, "Shutdown", "Warning players failed: %v", err)
+	}
+}
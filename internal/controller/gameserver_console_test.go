@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+)
+
+// fakeRCON records the connections made and the commands run over RCON.
+type fakeRCON struct {
+	addresses []string
+	passwords []string
+	commands  []string
+	dialErr   error
+}
+
+func (f *fakeRCON) Dial(_ context.Context, _ rcon.Protocol, address, password string) (rcon.Client, error) {
+	f.addresses = append(f.addresses, address)
+	f.passwords = append(f.passwords, password)
+	if f.dialErr != nil {
+		return nil, f.dialErr
+	}
+	return f, nil
+}
+
+func (f *fakeRCON) Execute(_ context.Context, command string) (string, error) {
+	f.commands = append(f.commands, command)
+	return "", nil
+}
+
+func (f *fakeRCON) Close() error {
+	return nil
+}
+
+var _ = Describe("GameServer console", func() {
+	const name = "console-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+	var (
+		reconciler *GameServerReconciler
+		fake       *fakeRCON
+		gs         *gamesv1alpha1.GameServer
+	)
+
+	createPod := func(ready bool) {
+		GinkgoHelper()
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "gameserver",
+				Image: "gameservermanagers/gameserver:mc",
+			}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.PodIP = "10.1.2.3"
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			StringData: map[string]string{"password": "secret"},
+		})).To(Succeed())
+
+		gs = newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.RCON = &gamesv1alpha1.RCONSpec{
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Key:                  "password",
+				},
+			}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+
+		fake = &fakeRCON{}
+		reconciler = &GameServerReconciler{
+			Client:     k8sClient,
+			Scheme:     k8sClient.Scheme(),
+			Recorder:   &events.FakeRecorder{},
+			RCONDialer: fake.Dial,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		})).To(Succeed())
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+	})
+
+	It("saves the world over the remote console of the running game server", func() {
+		createPod(true)
+
+		reconciler.saveWorld(ctx, gs)
+		Expect(fake.addresses).To(Equal([]string{"10.1.2.3:25575"}))
+		Expect(fake.passwords).To(Equal([]string{"secret"}))
+		Expect(fake.commands).To(Equal([]string{"save-all flush"}))
+	})
+
+	It("falls back to the LinuxGSM console without RCON", func() {
+		createPod(true)
+		executor := &fakeExecutor{}
+		reconciler.Executor = executor
+		gs.Spec.RCON = nil
+
+		reconciler.saveWorld(ctx, gs)
+		Expect(fake.addresses).To(BeEmpty())
+		Expect(executor.commands).To(Equal([]string{"/app/mcserver send save-all flush"}))
+	})
+
+	It("falls back to the LinuxGSM console while RCON is unreachable", func() {
+		createPod(true)
+		executor := &fakeExecutor{}
+		reconciler.Executor = executor
+		fake.dialErr = errors.New("connection refused")
+
+		reconciler.saveWorld(ctx, gs)
+		Expect(fake.addresses).To(HaveLen(1))
+		Expect(executor.commands).To(Equal([]string{"/app/mcserver send save-all flush"}))
+
+		By("not falling back when the password is rejected")
+		executor.commands = nil
+		fake.dialErr = rcon.ErrAuthFailed
+		_, ran, err := reconciler.console().exec(ctx, gs, "list")
+		Expect(err).To(MatchError(rcon.ErrAuthFailed))
+		Expect(ran).To(BeFalse())
+		Expect(executor.commands).To(BeEmpty())
+	})
+
+	It("warns players on the shutdown warnings and saves the world", func() {
+		createPod(true)
+		gs.Spec.Shutdown = &gamesv1alpha1.ShutdownSpec{Warnings: []metav1.Duration{
+			{Duration: 10 * time.Millisecond},
+			{Duration: 20 * time.Millisecond},
+		}}
+
+		Expect(reconciler.console().warnPlayers(ctx, gs, "restarts")).To(Succeed())
+		Expect(fake.commands).To(HaveLen(3))
+		Expect(fake.commands[0]).To(HavePrefix("say The server restarts in"))
+		Expect(fake.commands[1]).To(HavePrefix("say The server restarts in"))
+		Expect(fake.commands[2]).To(Equal("save-all flush"))
+	})
+
+	It("does not connect while the game server is not ready", func() {
+		createPod(false)
+
+		_, ran, err := reconciler.console().exec(ctx, gs, "list")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ran).To(BeFalse())
+		Expect(fake.addresses).To(BeEmpty())
+	})
+})
@@ -29,32 +29,98 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+	"github.com/idebeijer/gameserver-operator/pkg/podexec"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+	"github.com/idebeijer/gameserver-operator/pkg/utils"
+	"github.com/idebeijer/gameserver-operator/pkg/wake"
 )
 
 const (
 	finalizerName          = "gameserver.games.idebeijer.github.io/finalizer"
 	fieldManagerGameServer = "gameserver-controller"
+
+	maxConcurrentGameServerReconciles = 4
 )
 
 // GameServerReconciler reconciles a GameServer object
 type GameServerReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	// APIReader reads objects from the API server rather than the cache, like the Secret of the generated passwords,
+	// which must not be generated again because the cache has not seen it yet. The Client is used without one.
+	APIReader client.Reader
+
+	// Executor runs LinuxGSM commands in the game server container. Scheduled updates are skipped without one.
+	Executor podexec.Executor
+
+	// RCONDialer connects to the remote consoles of game servers. RCON is not used without one.
+	RCONDialer rcon.Dialer
+
+	// Querier queries running game servers for their players. Game servers are not queried without one.
+	Querier query.Querier
+
+	// ModResolver resolves the Minecraft mods to install. They are not installed without one.
+	ModResolver *mods.Resolver
+
+	// ServerResolver resolves the Minecraft servers and modpacks to install. They are not installed without one.
+	ServerResolver *minecraft.ServerResolver
+
+	// PlayerResolver resolves the Minecraft players to whitelist. They are not whitelisted without one.
+	PlayerResolver *minecraft.PlayerResolver
+
+	// WakeProxyAddress is the IP address of the pod the operator runs in, which players connecting to idle game
+	// servers are routed to. Game servers do not wake on connect without one.
+	WakeProxyAddress string
+	// WakeProxyNodeName is the name of the node the operator runs on. Services with externalTrafficPolicy Local
+	// only route players to the wake proxy on nodes it runs on, which are not known without one.
+	WakeProxyNodeName string
+
+	wakeProxy *wake.Proxy
+	// updates runs the update checks of the game servers in the background.
+	updates backgroundTasks[*gamesv1alpha1.UpdateStatus]
 }
 
 // +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameserverrestores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservercommands,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=get;create
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;create;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -70,23 +136,163 @@ func (r *GameServerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	if !gs.DeletionTimestamp.IsZero() {
+		return r.reconcileGameServerDeletion(ctx, gs)
+	}
+
+	if err := r.ensureFinalizer(ctx, gs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The schedule, mods and config are reconciled first, as the StatefulSet is built from the status they report.
+	nextScheduledTransition, err := r.reconcileGameServerSchedule(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextMinecraftResolve, err := r.reconcileGameServerMinecraft(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextModResolve, err := r.reconcileGameServerMods(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextPlayerResolve, err := r.reconcileGameServerMinecraftPlayers(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	// The passwords are generated first, so they are part of the config hash of the first pod.
+	if err := r.reconcileGameServerSecrets(ctx, gs); err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileGameServerConfig(ctx, gs); err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
 	if err := r.reconcileGameServer(ctx, gs); err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextSnapshot, err := r.reconcileGameServerSnapshots(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextUpdateCheck, err := r.reconcileGameServerUpdates(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	nextQuery, err := r.reconcileGameServerQuery(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	nextIdleCheck, err := r.reconcileGameServerIdle(ctx, gs)
+	if err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileGameServerWakeProxy(ctx, gs); err != nil {
+		r.setReconcileErrorStatus(ctx, gs, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileGameServerStatus(ctx, gs); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: soonestRequeue(
+		nextScheduledTransition, nextMinecraftResolve, nextModResolve, nextPlayerResolve,
+		nextSnapshot, nextUpdateCheck, nextQuery, nextIdleCheck,
+	)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *GameServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := metrics.Registry.Register(newGameServerCollector(mgr.GetClient())); err != nil {
+		return fmt.Errorf("failed to register GameServer metrics: %w", err)
+	}
+
+	if r.WakeProxyAddress != "" {
+		r.wakeProxy = wake.NewProxy(r.WakeProxyAddress, r.WakeProxyNodeName, r.requestWake)
+		if err := mgr.Add(r.wakeProxy); err != nil {
+			return fmt.Errorf("failed to add the wake proxy: %w", err)
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&gamesv1alpha1.GameServer{}).
 		Named("gameserver").
+		// Exec calls into game server containers, e.g. to save the world, block a worker while they run,
+		// other game servers are reconciled in the meantime.
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentGameServerReconciles}).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.CronJob{}).
+		// Only the metadata of ConfigMaps and Secrets is cached, their contents are read from the API server.
+		Owns(&corev1.ConfigMap{}, builder.OnlyMetadata).
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		// The manager only caches the EndpointSlices routing Services to the wake proxy.
+		Owns(&discoveryv1.EndpointSlice{}).
+		// Pods are owned by the StatefulSet rather than the GameServer, but their state
+		// (image pull errors, crash loops, readiness) is reflected in the GameServer status.
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(gameServerForObject)).
+		// Data volumes are created by the StatefulSet, their resize progress is reflected in the GameServer status.
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(gameServerForObject)).
+		// Backup Jobs are owned by the backup CronJob and stop the game server while they run.
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(gameServerForObject)).
+		// A GameServerRestore keeps the game server stopped while it replaces the data.
+		Watches(&gamesv1alpha1.GameServerRestore{}, handler.EnqueueRequestsFromMapFunc(gameServerForRestore)).
+		// The contents of config files are hashed into the pod template.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.gameServersForConfigMap),
+			builder.OnlyMetadata).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.gameServersForSecret),
+			builder.OnlyMetadata).
 		Complete(r)
 }
 
+// gameServerForObject maps a game server pod or data volume, or a backup pod or Job, to the GameServer it belongs to.
+func gameServerForObject(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels["app.kubernetes.io/managed-by"] != utils.GameServerControllerName {
+		return nil
+	}
+	name, ok := labels["app.kubernetes.io/instance"]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: obj.GetNamespace()}}}
+}
+
+// recordEvent records an event on the GameServer, if the reconciler has an event recorder.
+func (r *GameServerReconciler) recordEvent(
+	gs *gamesv1alpha1.GameServer,
+	eventType, reason, action, note string,
+	args ...any,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(gs, nil, eventType, reason, action, note, args...)
+}
+
 func (r *GameServerReconciler) getGameServer(ctx context.Context, req ctrl.Request) (*gamesv1alpha1.GameServer, error) {
 	gs := &gamesv1alpha1.GameServer{}
 	if err := r.Get(ctx, req.NamespacedName, gs); err != nil {
@@ -31,7 +31,9 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -77,13 +79,20 @@ var _ = Describe("GameServer Controller", func() {
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &gamesv1alpha1.GameServer{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			if errors.IsNotFound(err) {
+				return
+			}
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance GameServer")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			// Drive the finalizer, there is no manager running in this suite.
+			controllerReconciler := &GameServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
@@ -96,8 +105,51 @@ var _ = Describe("GameServer Controller", func() {
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Verifying the status reflects the missing game server pod")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gameserver)).To(Succeed())
+			Expect(gameserver.Status.ObservedGeneration).To(Equal(gameserver.Generation))
+			Expect(gameserver.Status.Phase).To(Equal(gamesv1alpha1.GameServerPhasePending))
+			Expect(meta.IsStatusConditionTrue(gameserver.Status.Conditions,
+				gamesv1alpha1.GameServerConditionProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(gameserver.Status.Conditions,
+				gamesv1alpha1.GameServerConditionAvailable)).To(BeTrue())
+		})
+
+		It("stops the game server and removes the finalizer on deletion", func() {
+			recorder := events.NewFakeRecorder(10)
+			controllerReconciler := &GameServerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			By("Adding the finalizer on the first reconcile")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, gameserver)).To(Succeed())
+			Expect(gameserver.Finalizers).To(ContainElement(finalizerName))
+
+			By("Deleting the GameServer")
+			Expect(k8sClient.Delete(ctx, gameserver)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Verifying the StatefulSet was scaled down before the GameServer was released")
+			var sts appsv1.StatefulSet
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &sts)).To(Succeed())
+			Expect(sts.Spec.Replicas).To(HaveValue(Equal(int32(0))))
+			Expect(sts.Spec.PersistentVolumeClaimRetentionPolicy).NotTo(BeNil())
+			Expect(sts.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted).
+				To(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType))
+
+			err = k8sClient.Get(ctx, typeNamespacedName, gameserver)
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "expected the GameServer to be gone, got %v", err)
+
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonStoppingForDeletion)))
+			Expect(recorder.Events).To(Receive(ContainSubstring(reasonStorageRetained)))
+
+			Expect(k8sClient.Delete(ctx, &sts)).To(Succeed())
 		})
 
 		It("creates the StatefulSet and records managedFields owner", func() {
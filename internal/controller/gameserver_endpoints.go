@@ -0,0 +1,67 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+// buildGameServerEndpoints computes the connection endpoints for every port of the game server Service.
+// For each port, LoadBalancer ingress addresses are listed first, followed by the node ports on the
+// external IPs of the node running the game server and finally the cluster IP.
+func buildGameServerEndpoints(obs *gameServerObservation) []gamesv1alpha1.GameServerEndpoint {
+	svc := obs.service
+	if svc == nil {
+		return nil
+	}
+
+	var endpoints []gamesv1alpha1.GameServerEndpoint
+	for _, port := range svc.Spec.Ports {
+		endpoint := func(endpointType gamesv1alpha1.EndpointType, address string, portNumber int32) {
+			endpoints = append(endpoints, gamesv1alpha1.GameServerEndpoint{
+				Name:     port.Name,
+				Type:     endpointType,
+				Address:  address,
+				Port:     portNumber,
+				Protocol: port.Protocol,
+			})
+		}
+
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				switch {
+				case ingress.IP != "":
+					endpoint(gamesv1alpha1.EndpointTypeLoadBalancer, ingress.IP, port.Port)
+				case ingress.Hostname != "":
+					endpoint(gamesv1alpha1.EndpointTypeLoadBalancer, ingress.Hostname, port.Port)
+				}
+			}
+		}
+
+		if svc.Spec.Type == corev1.ServiceTypeNodePort && port.NodePort != 0 {
+			for _, address := range nodeExternalAddresses(obs.node) {
+				endpoint(gamesv1alpha1.EndpointTypeNodePort, address, port.NodePort)
+			}
+		}
+
+		if svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone {
+			endpoint(gamesv1alpha1.EndpointTypeClusterIP, svc.Spec.ClusterIP, port.Port)
+		}
+	}
+
+	return endpoints
+}
+
+func nodeExternalAddresses(node *corev1.Node) []string {
+	if node == nil {
+		return nil
+	}
+
+	var addresses []string
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeExternalIP || address.Type == corev1.NodeExternalDNS {
+			addresses = append(addresses, address.Address)
+		}
+	}
+	return addresses
+}
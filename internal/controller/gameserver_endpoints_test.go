@@ -0,0 +1,76 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer endpoints", func() {
+	newService := func(svcType corev1.ServiceType, ports ...corev1.ServicePort) *corev1.Service {
+		return &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				Type:      svcType,
+				ClusterIP: "10.0.0.10",
+				Ports:     ports,
+			},
+		}
+	}
+
+	It("returns no endpoints without a service", func() {
+		Expect(buildGameServerEndpoints(&gameServerObservation{})).To(BeEmpty())
+	})
+
+	It("lists load balancer ingress addresses before the cluster IP", func() {
+		svc := newService(corev1.ServiceTypeLoadBalancer,
+			corev1.ServicePort{Name: "game", Port: 27015, NodePort: 30015, Protocol: corev1.ProtocolUDP},
+		)
+		svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+			{IP: "203.0.113.10"},
+			{Hostname: "game.example.com"},
+		}
+
+		Expect(buildGameServerEndpoints(&gameServerObservation{service: svc})).To(Equal([]gamesv1alpha1.GameServerEndpoint{
+			{Name: "game", Type: gamesv1alpha1.EndpointTypeLoadBalancer, Address: "203.0.113.10", Port: 27015,
+				Protocol: corev1.ProtocolUDP},
+			{Name: "game", Type: gamesv1alpha1.EndpointTypeLoadBalancer, Address: "game.example.com", Port: 27015,
+				Protocol: corev1.ProtocolUDP},
+			{Name: "game", Type: gamesv1alpha1.EndpointTypeClusterIP, Address: "10.0.0.10", Port: 27015,
+				Protocol: corev1.ProtocolUDP},
+		}))
+	})
+
+	It("combines node ports with the external IPs of the game server node", func() {
+		svc := newService(corev1.ServiceTypeNodePort,
+			corev1.ServicePort{Name: "game", Port: 25565, NodePort: 30565, Protocol: corev1.ProtocolTCP},
+			corev1.ServicePort{Name: "query", Port: 25565, NodePort: 30566, Protocol: corev1.ProtocolUDP},
+		)
+		node := &corev1.Node{Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "192.168.1.5"},
+			{Type: corev1.NodeExternalIP, Address: "198.51.100.7"},
+		}}}
+
+		endpoints := buildGameServerEndpoints(&gameServerObservation{service: svc, node: node})
+		Expect(endpoints).To(Equal([]gamesv1alpha1.GameServerEndpoint{
+			{Name: "game", Type: gamesv1alpha1.EndpointTypeNodePort, Address: "198.51.100.7", Port: 30565,
+				Protocol: corev1.ProtocolTCP},
+			{Name: "game", Type: gamesv1alpha1.EndpointTypeClusterIP, Address: "10.0.0.10", Port: 25565,
+				Protocol: corev1.ProtocolTCP},
+			{Name: "query", Type: gamesv1alpha1.EndpointTypeNodePort, Address: "198.51.100.7", Port: 30566,
+				Protocol: corev1.ProtocolUDP},
+			{Name: "query", Type: gamesv1alpha1.EndpointTypeClusterIP, Address: "10.0.0.10", Port: 25565,
+				Protocol: corev1.ProtocolUDP},
+		}))
+	})
+
+	It("omits the cluster IP of headless services", func() {
+		svc := newService(corev1.ServiceTypeClusterIP,
+			corev1.ServicePort{Name: "game", Port: 2456, Protocol: corev1.ProtocolUDP},
+		)
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+
+		Expect(buildGameServerEndpoints(&gameServerObservation{service: svc})).To(BeEmpty())
+	})
+})
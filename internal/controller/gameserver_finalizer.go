@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for GameServer events.
+const (
+	reasonStoppingForDeletion = "StoppingForDeletion"
+	reasonStorageRetained     = "StorageRetained"
+	reasonStorageDeleted      = "StorageDeleted"
+)
+
+// deletionRequeueInterval is how often the teardown is rechecked while waiting for the game server to stop.
+// Pod deletions also trigger a reconcile, this is only a safety net.
+const deletionRequeueInterval = 10 * time.Second
+
+// ensureFinalizer adds the finalizer to the GameServer, so it is torn down in order when deleted.
+func (r *GameServerReconciler) ensureFinalizer(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	if controllerutil.ContainsFinalizer(gs, finalizerName) {
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(gs.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.AddFinalizer(gs, finalizerName)
+	if err := r.Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
+	}
+
+	return nil
+}
+
+// reconcileGameServerDeletion tears a deleted GameServer down before its finalizer is removed.
+// The game server is first scaled to zero, so LinuxGSM can save the world and stop the game on SIGTERM,
+// after which a final backup is taken when configured. Only then the owned objects are left to the
+// garbage collector, which removes or keeps the data volume according to the
+// PersistentVolumeClaimRetentionPolicy set from spec.storage.deletionPolicy.
+func (r *GameServerReconciler) reconcileGameServerDeletion(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(gs, finalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	stopped, err := r.stopGameServerForDeletion(ctx, gs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !stopped {
+		log.Info("Waiting for the game server to stop before deletion")
+		return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
+	}
+
+	backedUp, err := r.reconcileFinalBackup(ctx, gs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !backedUp {
+		log.Info("Waiting for the final backup to finish before deletion")
+		return ctrl.Result{RequeueAfter: deletionRequeueInterval}, nil
+	}
+
+	if specs.LinuxGSMStorageEnabled(gs) {
+		pvcName := specs.GameServerDataVolumeClaimName(gs)
+		if specs.GameServerStorageDeletionPolicy(gs) == gamesv1alpha1.StorageDeletionPolicyDelete {
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonStorageDeleted, "Delete",
+				"PersistentVolumeClaim %s is deleted with the GameServer", pvcName)
+		} else {
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonStorageRetained, "Delete",
+				"PersistentVolumeClaim %s is retained, delete it manually to remove the world data", pvcName)
+		}
+	}
+
+	forgetQueryDuration(gs)
+	r.updates.forget(gs.UID)
+	if r.wakeProxy != nil {
+		r.wakeProxy.Close(client.ObjectKeyFromObject(gs))
+	}
+
+	patch := client.MergeFromWithOptions(gs.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(gs, finalizerName)
+	if err := r.Patch(ctx, gs, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// stopGameServerForDeletion scales the game server to zero and reports whether its pod is gone.
+// The StatefulSet is applied as a whole, so changes to the deletion policy made right before
+// deleting the GameServer are taken into account as well.
+func (r *GameServerReconciler) stopGameServerForDeletion(ctx context.Context, gs *gamesv1alpha1.GameServer) (bool, error) {
+	sts := &appsv1.StatefulSet{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace}, sts)
+	if err != nil {
+		return false, err
+	}
+	if found {
+		if sts.Spec.Replicas == nil || *sts.Spec.Replicas != 0 {
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonStoppingForDeletion, "Delete",
+				"Stopping the game server before deletion")
+		}
+
+		if err := r.reconcileGameServerStatefulSet(ctx, withState(gs, gamesv1alpha1.GameServerStateStopped)); err != nil {
+			return false, err
+		}
+	}
+
+	podKey := types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}
+	podFound, err := r.getOptional(ctx, podKey, &corev1.Pod{})
+	if err != nil {
+		return false, err
+	}
+
+	return !podFound, nil
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for the Idle condition and its events.
+const (
+	reasonIdle           = "Idle"
+	reasonWoken          = "Woken"
+	reasonNotReady       = "NotReady"
+	reasonPlayersOnline  = "PlayersOnline"
+	reasonNoPlayers      = "NoPlayers"
+	reasonPlayersUnknown = "PlayersUnknown"
+)
+
+// reconcileGameServerIdle stops the game server once the players reported by reconcileGameServerQuery were zero
+// for spec.idle.timeout, and starts it again when a wake up is requested. It returns when the game server is due
+// to be stopped, or zero when it is not empty.
+//
+// The game server is only stopped when its players can be counted, so a game server that cannot be queried,
+// e.g. because it is still loading its world, keeps running.
+func (r *GameServerReconciler) reconcileGameServerIdle(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	if gs.Spec.Idle == nil {
+		return 0, r.patchIdleStatus(ctx, gs, nil, nil)
+	}
+
+	lastPlayerSeen := gs.Status.LastPlayerSeen
+	if idle := meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionIdle); idle != nil &&
+		idle.Status == metav1.ConditionTrue {
+		if specs.GameServerScheduledState(gs) == gamesv1alpha1.GameServerStateStopped {
+			return 0, r.patchIdleStatus(ctx, gs,
+				idleCondition(metav1.ConditionFalse, reasonStopped, "Game server is stopped"), lastPlayerSeen)
+		}
+		if requestedAt, ok := wakeRequestedAt(gs); !ok || !requestedAt.After(idle.LastTransitionTime.Time) {
+			return 0, nil
+		}
+		r.recordEvent(gs, corev1.EventTypeNormal, reasonWoken, "Start", "Starting the idle game server on request")
+		return 0, r.patchIdleStatus(ctx, gs,
+			idleCondition(metav1.ConditionFalse, reasonWoken, "Game server was started on request"), lastPlayerSeen)
+	}
+
+	if specs.GameServerScheduledState(gs) == gamesv1alpha1.GameServerStateStopped {
+		return 0, r.patchIdleStatus(ctx, gs,
+			idleCondition(metav1.ConditionFalse, reasonStopped, "Game server is stopped"), lastPlayerSeen)
+	}
+
+	pod := &corev1.Pod{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}, pod)
+	if err != nil {
+		return 0, err
+	}
+	if !found || !podReady(pod) {
+		return 0, r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionFalse, reasonNotReady,
+			"Waiting for the game server to accept players"), lastPlayerSeen)
+	}
+
+	server := gs.Status.Server
+	switch {
+	case server == nil || server.LastQueryTime == nil:
+		return 0, r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionUnknown, reasonPlayersUnknown,
+			fmt.Sprintf("The players of %s cannot be counted, the game server is not queried", gs.Spec.GameName)),
+			lastPlayerSeen)
+	case server.Message != "":
+		return 0, r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionUnknown, reasonPlayersUnknown,
+			fmt.Sprintf("The players cannot be counted: %s", server.Message)), lastPlayerSeen)
+	case server.Players > 0:
+		return 0, r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionFalse, reasonPlayersOnline,
+			fmt.Sprintf("%d players online", server.Players)), server.LastQueryTime.DeepCopy())
+	}
+
+	// The time the game took to start does not count, nor does the time the game server was stopped.
+	// A wake up request counts as players showing up, as it is usually made by a player about to join.
+	emptySince := podCondition(pod, corev1.PodReady).LastTransitionTime.Time
+	if lastPlayerSeen != nil && lastPlayerSeen.After(emptySince) {
+		emptySince = lastPlayerSeen.Time
+	}
+	if requestedAt, ok := wakeRequestedAt(gs); ok && requestedAt.After(emptySince) {
+		emptySince = requestedAt
+	}
+	timeout, _ := specs.GameServerIdleTimeouts(gs)
+	stopAt := emptySince.Add(timeout)
+	if now := time.Now(); now.Before(stopAt) {
+		return stopAt.Sub(now), r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionFalse, reasonNoPlayers,
+			fmt.Sprintf("No players online since %s, stopping the game server at %s",
+				emptySince.UTC().Format(time.RFC3339), stopAt.UTC().Format(time.RFC3339))), lastPlayerSeen)
+	}
+
+	r.recordEvent(gs, corev1.EventTypeNormal, reasonIdle, "Stop",
+		"Stopping the game server, no players were online for %s", timeout)
+	return 0, r.patchIdleStatus(ctx, gs, idleCondition(metav1.ConditionTrue, reasonIdle,
+		fmt.Sprintf("No players were online for %s, the game server is stopped until it is woken up", timeout)),
+		lastPlayerSeen)
+}
+
+// wakeRequestedAt returns when the game server was last requested to wake up, if ever.
+func wakeRequestedAt(gs *gamesv1alpha1.GameServer) (time.Time, bool) {
+	value, ok := gs.Annotations[gamesv1alpha1.AnnotationWakeRequestedAt]
+	if !ok {
+		return time.Time{}, false
+	}
+	requestedAt, err := time.Parse(time.RFC3339, value)
+	return requestedAt, err == nil
+}
+
+func idleCondition(status metav1.ConditionStatus, reason, message string) *metav1.Condition {
+	return &metav1.Condition{
+		Type:    gamesv1alpha1.GameServerConditionIdle,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// patchIdleStatus sets the Idle condition, or removes it when condition is nil, and the time players were last seen.
+func (r *GameServerReconciler) patchIdleStatus(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	condition *metav1.Condition,
+	lastPlayerSeen *metav1.Time,
+) error {
+	status := gs.Status.DeepCopy()
+	if condition == nil {
+		meta.RemoveStatusCondition(&status.Conditions, gamesv1alpha1.GameServerConditionIdle)
+	} else {
+		condition.ObservedGeneration = gs.Generation
+		meta.SetStatusCondition(&status.Conditions, *condition)
+	}
+	status.LastPlayerSeen = lastPlayerSeen
+	if equality.Semantic.DeepEqual(&gs.Status, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status = *status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer idle status: %w", err)
+	}
+	return nil
+}
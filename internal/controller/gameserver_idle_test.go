@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+)
+
+var _ = Describe("GameServer idle stop", func() {
+	const name = "idle-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+	var (
+		reconciler *GameServerReconciler
+		querier    *fakeQuerier
+	)
+
+	reconcileGameServer := func() (reconcile.Result, *gamesv1alpha1.GameServer) {
+		GinkgoHelper()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		return result, gs
+	}
+
+	idleCondition := func(gs *gamesv1alpha1.GameServer) *metav1.Condition {
+		GinkgoHelper()
+		cond := meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionIdle)
+		Expect(cond).NotTo(BeNil())
+		return cond
+	}
+
+	replicas := func() int32 {
+		GinkgoHelper()
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		return *sts.Spec.Replicas
+	}
+
+	// createReadyPod creates the pod of the game server, ready since the given time.
+	createReadyPod := func(readySince time.Time) {
+		GinkgoHelper()
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "gameserver",
+				Image: "gameservermanagers/gameserver:mc",
+			}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: readySince},
+		}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		querier = &fakeQuerier{}
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+			Querier:  querier.Query,
+		}
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+			gs.Spec.Service = &gamesv1alpha1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []gamesv1alpha1.ServicePort{{Name: "game", Port: 25565, Protocol: corev1.ProtocolTCP}},
+			}
+			gs.Spec.Idle = &gamesv1alpha1.IdleSpec{}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("records when players were last seen", func() {
+		createReadyPod(time.Now().Add(-time.Hour))
+		querier.info = query.Info{Players: 2, MaxPlayers: 20}
+
+		_, gs := reconcileGameServer()
+		Expect(gs.Status.LastPlayerSeen).NotTo(BeNil())
+		Expect(gs.Status.LastPlayerSeen.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionFalse))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonPlayersOnline))
+		Expect(replicas()).To(BeEquivalentTo(1))
+	})
+
+	It("counts down from when the game server became ready", func() {
+		createReadyPod(time.Now())
+
+		result, gs := reconcileGameServer()
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionFalse))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonNoPlayers))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+		Expect(replicas()).To(BeEquivalentTo(1))
+	})
+
+	It("keeps the game server running while its players cannot be counted", func() {
+		createReadyPod(time.Now().Add(-time.Hour))
+		querier.err = errors.New("i/o timeout")
+
+		_, gs := reconcileGameServer()
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionUnknown))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonPlayersUnknown))
+		Expect(replicas()).To(BeEquivalentTo(1))
+	})
+
+	It("stops the empty game server after the timeout and starts it again when woken up", func() {
+		createReadyPod(time.Now().Add(-time.Hour))
+
+		_, gs := reconcileGameServer()
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionTrue))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonIdle))
+		reconcileGameServer()
+		Expect(replicas()).To(BeEquivalentTo(0))
+
+		By("starting it again when a wake up is requested")
+		patch := ctrlclient.MergeFrom(gs.DeepCopy())
+		gs.Annotations = map[string]string{
+			gamesv1alpha1.AnnotationWakeRequestedAt: time.Now().Add(time.Second).UTC().Format(time.RFC3339),
+		}
+		Expect(k8sClient.Patch(ctx, gs, patch)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionFalse))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonWoken))
+		_, gs = reconcileGameServer()
+		Expect(replicas()).To(BeEquivalentTo(1))
+		Expect(idleCondition(gs).Reason).To(Equal(reasonNoPlayers))
+	})
+
+	It("forgets the idle state once spec.idle is removed", func() {
+		createReadyPod(time.Now().Add(-time.Hour))
+		_, gs := reconcileGameServer()
+		Expect(idleCondition(gs).Status).To(Equal(metav1.ConditionTrue))
+
+		gs.Spec.Idle = nil
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionIdle)).To(BeNil())
+		reconcileGameServer()
+		Expect(replicas()).To(BeEquivalentTo(1))
+	})
+})
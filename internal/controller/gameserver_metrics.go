@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// collectTimeout bounds reading the game servers from the cache on a scrape.
+const collectTimeout = 10 * time.Second
+
+// gameServerMetricLabels are the labels of every game server metric.
+var gameServerMetricLabels = []string{"namespace", "name", "game"}
+
+// queryDurationSeconds is set by the query reconciliation, the other metrics are derived from the
+// GameServer status when they are scraped.
+var queryDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "gameserver_query_duration_seconds",
+	Help: "Duration of the last query of the game server.",
+}, gameServerMetricLabels)
+
+func init() {
+	metrics.Registry.MustRegister(queryDurationSeconds)
+}
+
+var (
+	upDesc = prometheus.NewDesc("gameserver_up",
+		"Whether the game server is available (1) or not (0).", gameServerMetricLabels, nil)
+	playersDesc = prometheus.NewDesc("gameserver_players",
+		"Number of connected players, as reported by the last query.", gameServerMetricLabels, nil)
+	maxPlayersDesc = prometheus.NewDesc("gameserver_max_players",
+		"Number of player slots, as reported by the last query.", gameServerMetricLabels, nil)
+	lastBackupAgeDesc = prometheus.NewDesc("gameserver_last_backup_age_seconds",
+		"Seconds since the last successful backup finished.", gameServerMetricLabels, nil)
+	lastUpdateAgeDesc = prometheus.NewDesc("gameserver_last_update_age_seconds",
+		"Seconds since a new build of the game was last installed.", gameServerMetricLabels, nil)
+	restartsDesc = prometheus.NewDesc("gameserver_restarts_total",
+		"Number of times the game server container restarted since its pod was created.", gameServerMetricLabels, nil)
+)
+
+// gameServerCollector exposes the metrics of every GameServer. They are read from the cache on every scrape,
+// so the metrics of a deleted game server disappear with it.
+type gameServerCollector struct {
+	reader client.Reader
+	now    func() time.Time
+}
+
+func newGameServerCollector(reader client.Reader) *gameServerCollector {
+	return &gameServerCollector{reader: reader, now: time.Now}
+}
+
+func (c *gameServerCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		upDesc, playersDesc, maxPlayersDesc, lastBackupAgeDesc, lastUpdateAgeDesc, restartsDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *gameServerCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	list := &gamesv1alpha1.GameServerList{}
+	if err := c.reader.List(ctx, list); err != nil {
+		ch <- prometheus.NewInvalidMetric(upDesc, err)
+		return
+	}
+
+	now := c.now()
+	for i := range list.Items {
+		gs := &list.Items[i]
+		labels := []string{gs.Namespace, gs.Name, gs.Spec.GameName}
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+		}
+		age := func(desc *prometheus.Desc, t *metav1.Time) {
+			if t != nil {
+				gauge(desc, now.Sub(t.Time).Seconds())
+			}
+		}
+
+		up := 0.0
+		if meta.IsStatusConditionTrue(gs.Status.Conditions, gamesv1alpha1.GameServerConditionAvailable) {
+			up = 1
+		}
+		gauge(upDesc, up)
+
+		if server := gs.Status.Server; server != nil {
+			gauge(playersDesc, float64(server.Players))
+			gauge(maxPlayersDesc, float64(server.MaxPlayers))
+		}
+		if gs.Status.Backup != nil {
+			age(lastBackupAgeDesc, gs.Status.Backup.LastSuccessfulTime)
+		}
+		if gs.Status.Updates != nil {
+			age(lastUpdateAgeDesc, gs.Status.Updates.LastUpdateTime)
+		}
+
+		if restarts, ok := c.restarts(ctx, gs); ok {
+			ch <- prometheus.MustNewConstMetric(restartsDesc, prometheus.CounterValue, float64(restarts), labels...)
+		}
+	}
+}
+
+// restarts returns the restart count of the game server container, which is only known while its pod exists.
+func (c *gameServerCollector) restarts(ctx context.Context, gs *gamesv1alpha1.GameServer) (int32, bool) {
+	pod := &corev1.Pod{}
+	key := types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}
+	if err := c.reader.Get(ctx, key, pod); err != nil {
+		return 0, false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == specs.GameServerContainerName {
+			return status.RestartCount, true
+		}
+	}
+	return 0, false
+}
+
+// observeQueryDuration records the duration of a query of the game server.
+func observeQueryDuration(gs *gamesv1alpha1.GameServer, d time.Duration) {
+	queryDurationSeconds.WithLabelValues(gs.Namespace, gs.Name, gs.Spec.GameName).Set(d.Seconds())
+}
+
+// forgetQueryDuration removes the query duration of a game server that is no longer queried.
+func forgetQueryDuration(gs *gamesv1alpha1.GameServer) {
+	queryDurationSeconds.DeleteLabelValues(gs.Namespace, gs.Name, gs.Spec.GameName)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer metrics", func() {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	It("exposes the state of every game server", func() {
+		running := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = "running"
+			gs.Spec.GameName = "mc"
+			gs.Status.Conditions = []metav1.Condition{{
+				Type:   gamesv1alpha1.GameServerConditionAvailable,
+				Status: metav1.ConditionTrue,
+			}}
+			gs.Status.Server = &gamesv1alpha1.ServerStatus{Players: 3, MaxPlayers: 20}
+			gs.Status.Backup = &gamesv1alpha1.BackupStatus{
+				LastSuccessfulTime: &metav1.Time{Time: now.Add(-2 * time.Hour)},
+			}
+			gs.Status.Updates = &gamesv1alpha1.UpdateStatus{LastUpdateTime: &metav1.Time{Time: now.Add(-24 * time.Hour)}}
+		})
+		stopped := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = "stopped"
+		})
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "running-0", Namespace: testNamespace},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "gameserver", RestartCount: 2},
+			}},
+		}
+
+		reader := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(running, stopped, pod).Build()
+		collector := newGameServerCollector(reader)
+		collector.now = func() time.Time { return now }
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP gameserver_last_backup_age_seconds Seconds since the last successful backup finished.
+# TYPE gameserver_last_backup_age_seconds gauge
+gameserver_last_backup_age_seconds{game="mc",name="running",namespace="default"} 7200
+# HELP gameserver_last_update_age_seconds Seconds since a new build of the game was last installed.
+# TYPE gameserver_last_update_age_seconds gauge
+gameserver_last_update_age_seconds{game="mc",name="running",namespace="default"} 86400
+# HELP gameserver_max_players Number of player slots, as reported by the last query.
+# TYPE gameserver_max_players gauge
+gameserver_max_players{game="mc",name="running",namespace="default"} 20
+# HELP gameserver_players Number of connected players, as reported by the last query.
+# TYPE gameserver_players gauge
+gameserver_players{game="mc",name="running",namespace="default"} 3
+# HELP gameserver_restarts_total Number of times the game server container restarted since its pod was created.
+# TYPE gameserver_restarts_total counter
+gameserver_restarts_total{game="mc",name="running",namespace="default"} 2
+# HELP gameserver_up Whether the game server is available (1) or not (0).
+# TYPE gameserver_up gauge
+gameserver_up{game="mc",name="running",namespace="default"} 1
+gameserver_up{game="valheim",name="stopped",namespace="default"} 0
+`))).To(Succeed())
+	})
+})
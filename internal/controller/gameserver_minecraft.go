@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const (
+	reasonMinecraftResolutionFailed = "MinecraftResolutionFailed"
+
+	// serverResolveRetryInterval is how long resolving a server that failed to resolve is backed off.
+	serverResolveRetryInterval = time.Minute
+)
+
+// reconcileGameServerMinecraft resolves the Minecraft server of spec.gameConfigs.minecraft into
+// status.minecraft.server, which the init container of the StatefulSet installs. It returns when a server that failed
+// to resolve is retried, or zero when the server is resolved.
+//
+// The server is only resolved when its version, loader or modpack changes, so a new release of a loader or modpack
+// without a pinned version does not restart the game server. A server that fails to resolve keeps the previously
+// resolved server installed.
+func (r *GameServerReconciler) reconcileGameServerMinecraft(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	request := specs.GameServerMinecraftServer(gs)
+	var previous *gamesv1alpha1.MinecraftServerStatus
+	if gs.Status.Minecraft != nil {
+		previous = gs.Status.Minecraft.Server
+	}
+	if request == nil {
+		if previous == nil {
+			return 0, nil
+		}
+		status := gs.Status.Minecraft.DeepCopy()
+		status.Server = nil
+		return 0, r.patchMinecraftStatus(ctx, gs, status)
+	}
+	if r.ServerResolver == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	if previous != nil && equality.Semantic.DeepEqual(previous.Request, *request) {
+		if previous.Message == "" {
+			return 0, nil
+		}
+		if previous.LastResolveTime != nil {
+			if retryAt := previous.LastResolveTime.Add(serverResolveRetryInterval); now.Before(retryAt) {
+				return retryAt.Sub(now), nil
+			}
+		}
+	}
+
+	status := &gamesv1alpha1.MinecraftStatus{}
+	if gs.Status.Minecraft != nil {
+		status = gs.Status.Minecraft.DeepCopy()
+	}
+
+	var modpack *mods.Mod
+	if request.Modpack != nil {
+		modpack = &mods.Mod{
+			Source:  mods.Source(request.Modpack.Source),
+			Project: request.Modpack.Project,
+			Version: request.Modpack.Version,
+			URL:     request.Modpack.URL,
+			SHA1:    request.Modpack.SHA1,
+		}
+	}
+	resolved, err := r.ServerResolver.ResolveServer(ctx, minecraft.ServerRequest{
+		GameVersion:   request.Version,
+		Loader:        mods.Loader(request.Loader),
+		LoaderVersion: request.LoaderVersion,
+		Modpack:       modpack,
+	})
+	if err != nil {
+		server := &gamesv1alpha1.MinecraftServerStatus{}
+		if previous != nil {
+			server = previous.DeepCopy()
+		}
+		server.Request = *request
+		server.Message = err.Error()
+		server.LastResolveTime = &metav1.Time{Time: now}
+		status.Server = server
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonMinecraftResolutionFailed, "ResolveServer",
+			"Failed to resolve the Minecraft server: %v", err)
+		return serverResolveRetryInterval, r.patchMinecraftStatus(ctx, gs, status)
+	}
+
+	server := &gamesv1alpha1.MinecraftServerStatus{
+		Request:       *request,
+		Version:       resolved.GameVersion,
+		Loader:        gamesv1alpha1.MinecraftLoader(resolved.Loader),
+		LoaderVersion: resolved.LoaderVersion,
+		File: &gamesv1alpha1.MinecraftModFile{
+			Version: resolved.File.Version,
+			Name:    resolved.File.Name,
+			URL:     resolved.File.URL,
+			SHA1:    resolved.File.SHA1,
+		},
+		LastResolveTime: &metav1.Time{Time: now},
+	}
+	if resolved.Modpack != nil {
+		server.Modpack = &gamesv1alpha1.MinecraftModpackFile{
+			MinecraftModFile: gamesv1alpha1.MinecraftModFile{
+				Version: resolved.Modpack.File.Version,
+				Name:    resolved.Modpack.File.Name,
+				URL:     resolved.Modpack.File.URL,
+				SHA1:    resolved.Modpack.File.SHA1,
+			},
+			Format: gamesv1alpha1.MinecraftModpackFormat(resolved.Modpack.Format),
+		}
+	}
+	status.Server = server
+	return 0, r.patchMinecraftStatus(ctx, gs, status)
+}
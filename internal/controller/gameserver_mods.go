@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const (
+	reasonModResolutionFailed = "ModResolutionFailed"
+
+	// modResolveRetryInterval is how long resolving a mod that failed to resolve is backed off.
+	modResolveRetryInterval = time.Minute
+)
+
+// resolvedModKey identifies what a mod was resolved for: the mod as listed in the spec, the Minecraft version and
+// the loader.
+type resolvedModKey struct {
+	mod         gamesv1alpha1.MinecraftMod
+	gameVersion string
+	loader      gamesv1alpha1.MinecraftLoader
+}
+
+// reconcileGameServerMods resolves the mods of spec.gameConfigs.minecraft into status.minecraft.mods, which the
+// init container of the StatefulSet installs. It returns when mods that failed to resolve are retried, or zero when
+// all mods are resolved.
+//
+// Mods are only resolved when they are added or changed, or the Minecraft version or loader changes, so a new release
+// of a mod without a pinned version does not restart the game server. Mods are resolved for the server the operator
+// installs once it is resolved, or else for the Minecraft version of the spec.
+func (r *GameServerReconciler) reconcileGameServerMods(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	wanted := specs.GameServerMinecraftMods(gs)
+	if len(wanted) == 0 {
+		// The init container removes the mods it installed once the status lists none.
+		if gs.Status.Minecraft == nil {
+			return 0, nil
+		}
+		status := gs.Status.Minecraft.DeepCopy()
+		status.Mods = nil
+		return 0, r.patchMinecraftStatus(ctx, gs, status)
+	}
+	if r.ModResolver == nil {
+		return 0, nil
+	}
+
+	gameVersion := gs.Spec.GameConfigs.Minecraft.Version
+	var loader gamesv1alpha1.MinecraftLoader
+	previous := map[resolvedModKey]gamesv1alpha1.MinecraftModStatus{}
+	status := &gamesv1alpha1.MinecraftStatus{}
+	if gs.Status.Minecraft != nil {
+		status = gs.Status.Minecraft.DeepCopy()
+		for _, mod := range status.Mods {
+			previous[resolvedModKey{mod: mod.Mod, gameVersion: mod.GameVersion, loader: mod.Loader}] = mod
+		}
+		if server := status.Server; server != nil && server.File != nil {
+			gameVersion, loader = server.Version, server.Loader
+		}
+	}
+
+	now := time.Now()
+	var retry time.Duration
+	status.Mods = make([]gamesv1alpha1.MinecraftModStatus, 0, len(wanted))
+	for _, mod := range wanted {
+		if resolved, ok := previous[resolvedModKey{mod: mod, gameVersion: gameVersion, loader: loader}]; ok {
+			if resolved.File != nil {
+				status.Mods = append(status.Mods, resolved)
+				continue
+			}
+			if resolved.LastResolveTime != nil {
+				if retryAt := resolved.LastResolveTime.Add(modResolveRetryInterval); now.Before(retryAt) {
+					status.Mods = append(status.Mods, resolved)
+					retry = soonestRequeue(retry, retryAt.Sub(now))
+					continue
+				}
+			}
+		}
+
+		resolved := gamesv1alpha1.MinecraftModStatus{
+			Mod:             mod,
+			GameVersion:     gameVersion,
+			Loader:          loader,
+			LastResolveTime: &metav1.Time{Time: now},
+		}
+		file, err := r.ModResolver.Resolve(ctx, mods.Mod{
+			Source:  mods.Source(mod.Source),
+			Project: mod.Project,
+			Version: mod.Version,
+			URL:     mod.URL,
+			SHA1:    mod.SHA1,
+		}, mods.Target{GameVersion: gameVersion, Loader: mods.Loader(loader)})
+		if err != nil {
+			resolved.Message = err.Error()
+			retry = soonestRequeue(retry, modResolveRetryInterval)
+			r.recordEvent(gs, corev1.EventTypeWarning, reasonModResolutionFailed, "ResolveMod",
+				"Failed to resolve mod %s: %v", modName(mod), err)
+		} else {
+			resolved.File = &gamesv1alpha1.MinecraftModFile{
+				Version: file.Version,
+				Name:    file.Name,
+				URL:     file.URL,
+				SHA1:    file.SHA1,
+			}
+		}
+		status.Mods = append(status.Mods, resolved)
+	}
+
+	return retry, r.patchMinecraftStatus(ctx, gs, status)
+}
+
+// modName returns how a mod is referred to in events.
+func modName(mod gamesv1alpha1.MinecraftMod) string {
+	if mod.Source == gamesv1alpha1.MinecraftModSourceURL {
+		return mod.URL
+	}
+	return fmt.Sprintf("%s from %s", mod.Project, mod.Source)
+}
+
+// patchMinecraftStatus sets status.minecraft.
+func (r *GameServerReconciler) patchMinecraftStatus(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	status *gamesv1alpha1.MinecraftStatus,
+) error {
+	if equality.Semantic.DeepEqual(gs.Status.Minecraft, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status.Minecraft = status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer Minecraft status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+)
+
+var _ = Describe("GameServer mods", func() {
+	const name = "mods-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	sodium := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceModrinth, Project: "sodium"}
+	lithium := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceModrinth, Project: "lithium"}
+
+	var (
+		reconciler *GameServerReconciler
+		modrinth   *httptest.Server
+		requests   atomic.Int32
+	)
+
+	reconcileGameServer := func() (reconcile.Result, *gamesv1alpha1.GameServer) {
+		GinkgoHelper()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		return result, gs
+	}
+
+	initContainers := func() []corev1.Container {
+		GinkgoHelper()
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		return sts.Spec.Template.Spec.InitContainers
+	}
+
+	modsEnv := func() []corev1.EnvVar {
+		GinkgoHelper()
+		for _, container := range initContainers() {
+			if container.Name == "install-mods" {
+				return container.Env
+			}
+		}
+		Fail("the StatefulSet has no install-mods init container")
+		return nil
+	}
+
+	BeforeEach(func() {
+		requests.Store(0)
+		// Modrinth knows sodium for Minecraft 1.21.1 only, Mojang knows Minecraft 1.21.1 only.
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /mc/game/version_manifest_v2.json", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"versions": []map[string]string{
+				{"id": "1.21.1", "url": modrinth.URL + "/v1/packages/1.21.1.json"},
+			}})
+		})
+		mux.HandleFunc("GET /v1/packages/1.21.1.json", func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"downloads": map[string]any{"server": map[string]string{
+				"url":  "https://piston-data.mojang.com/server.jar",
+				"sha1": "fedcba9876543210fedcba9876543210fedcba98",
+			}}})
+		})
+		mux.HandleFunc("GET /v2/project/sodium/version", func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			versions := []map[string]any{}
+			if r.URL.Query().Get("game_versions") == `["1.21.1"]` {
+				versions = append(versions, map[string]any{
+					"version_number": "0.6.0",
+					"files": []map[string]any{{
+						"url":      "https://cdn.modrinth.com/sodium-0.6.0.jar",
+						"filename": "sodium-0.6.0.jar",
+						"primary":  true,
+						"hashes":   map[string]string{"sha1": "0123456789abcdef0123456789abcdef01234567"},
+					}},
+				})
+			}
+			_ = json.NewEncoder(w).Encode(versions)
+		})
+		modrinth = httptest.NewServer(mux)
+
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+			ModResolver: &mods.Resolver{
+				ModrinthURL: modrinth.URL,
+				Client:      modrinth.Client(),
+			},
+			ServerResolver: &minecraft.ServerResolver{
+				MojangURL: modrinth.URL,
+				Client:    modrinth.Client(),
+			},
+		}
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Version: "1.21.1",
+				Mods:    []gamesv1alpha1.MinecraftMod{sodium},
+			}}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		modrinth.Close()
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("installs the resolved mods before the game server starts", func() {
+		result, gs := reconcileGameServer()
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(gs.Status.Minecraft).NotTo(BeNil())
+		Expect(gs.Status.Minecraft.Mods).To(HaveLen(1))
+		Expect(gs.Status.Minecraft.Mods[0].Mod).To(Equal(sodium))
+		Expect(gs.Status.Minecraft.Mods[0].GameVersion).To(Equal("1.21.1"))
+		Expect(gs.Status.Minecraft.Mods[0].Loader).To(Equal(gamesv1alpha1.MinecraftLoaderVanilla))
+		Expect(gs.Status.Minecraft.Mods[0].File).To(Equal(&gamesv1alpha1.MinecraftModFile{
+			Version: "0.6.0",
+			Name:    "sodium-0.6.0.jar",
+			URL:     "https://cdn.modrinth.com/sodium-0.6.0.jar",
+			SHA1:    "0123456789abcdef0123456789abcdef01234567",
+		}))
+
+		By("installing the server before the mods")
+		Expect(gs.Status.Minecraft.Server).NotTo(BeNil())
+		Expect(gs.Status.Minecraft.Server.Version).To(Equal("1.21.1"))
+		Expect(gs.Status.Minecraft.Server.File.URL).To(Equal("https://piston-data.mojang.com/server.jar"))
+		Expect(initContainers()).To(HaveLen(2))
+		Expect(initContainers()[0].Name).To(Equal("install-minecraft"))
+
+		Expect(modsEnv()).To(ContainElement(corev1.EnvVar{
+			Name:  "MODS",
+			Value: "0123456789abcdef0123456789abcdef01234567 sodium-0.6.0.jar https://cdn.modrinth.com/sodium-0.6.0.jar\n",
+		}))
+
+		By("not resolving the mod again")
+		reconcileGameServer()
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+
+		By("removing the mods no longer listed")
+		gs.Spec.GameConfigs.Minecraft.Mods = nil
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(gs.Status.Minecraft).NotTo(BeNil())
+		Expect(gs.Status.Minecraft.Mods).To(BeEmpty())
+		Expect(modsEnv()).To(ContainElement(corev1.EnvVar{Name: "MODS"}))
+	})
+
+	It("reports mods that cannot be resolved and retries them later", func() {
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.GameConfigs.Minecraft.Version = "1.7.10"
+		gs.Spec.GameConfigs.Minecraft.Mods = append(gs.Spec.GameConfigs.Minecraft.Mods, lithium)
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+
+		result, gs := reconcileGameServer()
+		Expect(result.RequeueAfter).To(BeNumerically("~", modResolveRetryInterval, time.Second))
+		Expect(gs.Status.Minecraft.Server.Message).To(Equal("version 1.7.10 of Minecraft not found"))
+		Expect(gs.Status.Minecraft.Mods).To(HaveLen(2))
+		Expect(gs.Status.Minecraft.Mods[0].File).To(BeNil())
+		Expect(gs.Status.Minecraft.Mods[0].Message).To(Equal("project sodium on Modrinth has no version for Minecraft 1.7.10"))
+		Expect(gs.Status.Minecraft.Mods[1].Message).To(Equal("project lithium not found on Modrinth"))
+		Expect(modsEnv()).To(ContainElement(corev1.EnvVar{Name: "MODS"}))
+
+		By("backing off before resolving them again")
+		reconcileGameServer()
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+
+		By("resolving them again once the Minecraft version changes")
+		gs.Spec.GameConfigs.Minecraft.Version = "1.21.1"
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(gs.Status.Minecraft.Mods[0].File).NotTo(BeNil())
+		Expect(gs.Status.Minecraft.Mods[0].Message).To(BeEmpty())
+	})
+})
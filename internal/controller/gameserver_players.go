@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for Minecraft player events.
+const (
+	reasonPlayerResolutionFailed = "PlayerResolutionFailed"
+	reasonPlayersUpdated         = "PlayersUpdated"
+	reasonPlayersUpdateFailed    = "PlayersUpdateFailed"
+
+	// playerResolveRetryInterval is how long resolving a player that failed to resolve is backed off.
+	playerResolveRetryInterval = time.Minute
+)
+
+// minecraftPlayerCommands are the console commands adding and removing a player from whitelist.json or ops.json.
+var minecraftPlayerCommands = map[string][2]string{
+	specs.MinecraftWhitelistKey: {"whitelist add %s", "whitelist remove %s"},
+	specs.MinecraftOpsKey:       {"op %s", "deop %s"},
+}
+
+// reconcileGameServerMinecraftPlayers resolves the whitelisted players and ops of spec.gameConfigs.minecraft into
+// status.minecraft.players, and applies the ConfigMap holding whitelist.json and ops.json, which the game server
+// copies into the server files when it starts. It returns when players that failed to resolve are retried, or zero
+// when all players are resolved.
+//
+// Players added to or removed from the ConfigMap are also added or removed through the console of the running
+// server, so changing the lists does not restart it.
+func (r *GameServerReconciler) reconcileGameServerMinecraftPlayers(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	names := specs.GameServerMinecraftPlayerNames(gs)
+	if len(names) == 0 || !specs.LinuxGSMStorageEnabled(gs) {
+		// The status lists players as long as the ConfigMap may exist.
+		if gs.Status.Minecraft == nil || gs.Status.Minecraft.Players == nil {
+			return 0, nil
+		}
+		if err := r.deleteMinecraftPlayersConfigMap(ctx, gs); err != nil {
+			return 0, err
+		}
+		status := gs.Status.Minecraft.DeepCopy()
+		status.Players = nil
+		return 0, r.patchMinecraftStatus(ctx, gs, status)
+	}
+	if r.PlayerResolver == nil {
+		return 0, nil
+	}
+
+	retry, err := r.resolveMinecraftPlayers(ctx, gs, names)
+	if err != nil {
+		return 0, err
+	}
+
+	configMapApply := specs.BuildMinecraftPlayersConfigMap(gs)
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: specs.GameServerMinecraftPlayersConfigMapName(gs), Namespace: gs.Namespace}
+	found, err := r.getOptional(ctx, key, configMap)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		r.updateMinecraftPlayers(ctx, gs, configMap.Data, configMapApply.Data)
+	}
+
+	configMapApply.WithOwnerReferences(gameServerOwnerReference(gs))
+	if err := r.Apply(ctx, configMapApply,
+		client.FieldOwner(fieldManagerGameServer),
+		client.ForceOwnership,
+	); err != nil {
+		return 0, fmt.Errorf("failed to apply Minecraft players ConfigMap: %w", err)
+	}
+	return retry, nil
+}
+
+// resolveMinecraftPlayers resolves names into status.minecraft.players, to the UUIDs of their Mojang accounts or to
+// offline UUIDs when online mode is disabled. Resolved players are kept, players that failed to resolve are retried
+// after playerResolveRetryInterval, which it returns when it is the soonest retry.
+func (r *GameServerReconciler) resolveMinecraftPlayers(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	names []string,
+) (time.Duration, error) {
+	offline := !specs.GameServerMinecraftOnlineMode(gs)
+	previous := map[string]gamesv1alpha1.MinecraftPlayerStatus{}
+	status := &gamesv1alpha1.MinecraftStatus{}
+	if gs.Status.Minecraft != nil {
+		status = gs.Status.Minecraft.DeepCopy()
+		for _, player := range status.Players {
+			if player.Offline == offline {
+				previous[player.Name] = player
+			}
+		}
+	}
+
+	now := time.Now()
+	var retry time.Duration
+	status.Players = make([]gamesv1alpha1.MinecraftPlayerStatus, 0, len(names))
+	for _, name := range names {
+		if resolved, ok := previous[name]; ok {
+			if resolved.UUID != "" {
+				status.Players = append(status.Players, resolved)
+				continue
+			}
+			if resolved.LastResolveTime != nil {
+				if retryAt := resolved.LastResolveTime.Add(playerResolveRetryInterval); now.Before(retryAt) {
+					status.Players = append(status.Players, resolved)
+					retry = soonestRequeue(retry, retryAt.Sub(now))
+					continue
+				}
+			}
+		}
+
+		resolved := gamesv1alpha1.MinecraftPlayerStatus{
+			Name:            name,
+			Offline:         offline,
+			LastResolveTime: &metav1.Time{Time: now},
+		}
+		if offline {
+			resolved.UUID = minecraft.OfflinePlayer(name).UUID
+		} else if player, err := r.PlayerResolver.ResolvePlayer(ctx, name); err != nil {
+			resolved.Message = err.Error()
+			retry = soonestRequeue(retry, playerResolveRetryInterval)
+			r.recordEvent(gs, corev1.EventTypeWarning, reasonPlayerResolutionFailed, "ResolvePlayer",
+				"Failed to resolve player %s: %v", name, err)
+		} else {
+			resolved.UUID = player.UUID
+		}
+		status.Players = append(status.Players, resolved)
+	}
+
+	return retry, r.patchMinecraftStatus(ctx, gs, status)
+}
+
+// updateMinecraftPlayers adds and removes the players that differ between the current and desired whitelist.json
+// and ops.json through the console of the running server. Nothing is sent while the server is not running, as it
+// copies the files when it starts. Failures are reported as events, the files are applied at the next start.
+func (r *GameServerReconciler) updateMinecraftPlayers(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	current, desired map[string]string,
+) {
+	var commands []string
+	for _, key := range []string{specs.MinecraftWhitelistKey, specs.MinecraftOpsKey} {
+		currentData, ok := current[key]
+		if !ok {
+			continue
+		}
+		desiredData, ok := desired[key]
+		if !ok {
+			continue
+		}
+		currentNames, err := specs.ParseMinecraftPlayers(currentData)
+		if err != nil {
+			continue
+		}
+		desiredNames, err := specs.ParseMinecraftPlayers(desiredData)
+		if err != nil {
+			continue
+		}
+		add, remove := minecraftPlayerCommands[key][0], minecraftPlayerCommands[key][1]
+		for _, name := range desiredNames {
+			if !slices.Contains(currentNames, name) {
+				commands = append(commands, fmt.Sprintf(add, name))
+			}
+		}
+		for _, name := range currentNames {
+			if !slices.Contains(desiredNames, name) {
+				commands = append(commands, fmt.Sprintf(remove, name))
+			}
+		}
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+	for _, command := range commands {
+		_, ran, err := r.console().exec(ctx, gs, command)
+		if err != nil {
+			r.recordEvent(gs, corev1.EventTypeWarning, reasonPlayersUpdateFailed, "UpdatePlayers",
+				"Failed to run %q on the game server, the players are updated when it restarts: %v", command, err)
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+	r.recordEvent(gs, corev1.EventTypeNormal, reasonPlayersUpdated, "UpdatePlayers",
+		"Updated the whitelist and ops of the running game server")
+}
+
+// deleteMinecraftPlayersConfigMap removes the Minecraft players ConfigMap once there are no whitelisted players or
+// ops. The files the game server copied from it are left in the server files.
+func (r *GameServerReconciler) deleteMinecraftPlayersConfigMap(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: specs.GameServerMinecraftPlayersConfigMapName(gs), Namespace: gs.Namespace}
+	found, err := r.getOptional(ctx, key, configMap)
+	if err != nil || !found || !metav1.IsControlledBy(configMap, gs) {
+		return err
+	}
+	if err := r.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Minecraft players ConfigMap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/minecraft"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServer Minecraft players", func() {
+	const name = "players-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	configMapKey := types.NamespacedName{Name: name + "-minecraft-players", Namespace: testNamespace}
+	uuids := map[string]string{
+		"Steve":     "8667ba71b85a4004af54457a9734eed7",
+		"Alex":      "ec561538f3fd461daff5086b22154bce",
+		"Herobrine": "f84c6a790a4e45e0879bcd49ebd4c4e2",
+	}
+
+	var (
+		reconciler *GameServerReconciler
+		mojang     *httptest.Server
+		executor   *fakeExecutor
+	)
+
+	reconcileGameServer := func() (reconcile.Result, *gamesv1alpha1.GameServer) {
+		GinkgoHelper()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		return result, gs
+	}
+
+	players := func(file string) []string {
+		GinkgoHelper()
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+		names, err := specs.ParseMinecraftPlayers(configMap.Data[file])
+		Expect(err).NotTo(HaveOccurred())
+		return names
+	}
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/profiles/minecraft/{name}", func(w http.ResponseWriter, r *http.Request) {
+			id, ok := uuids[r.PathValue("name")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": id, "name": r.PathValue("name")})
+		})
+		mojang = httptest.NewServer(mux)
+
+		executor = &fakeExecutor{}
+		reconciler = &GameServerReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			Recorder:       &events.FakeRecorder{},
+			Executor:       executor,
+			PlayerResolver: &minecraft.PlayerResolver{MojangAPIURL: mojang.URL, Client: mojang.Client()},
+		}
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Whitelist: []string{"Steve", "Alex"},
+				Ops:       []string{"Steve"},
+			}}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		mojang.Close()
+
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: configMapKey.Name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("writes the resolved whitelist and ops for the game server to copy when it starts", func() {
+		result, gs := reconcileGameServer()
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(gs.Status.Minecraft).NotTo(BeNil())
+		Expect(gs.Status.Minecraft.Players).To(HaveLen(2))
+		Expect(gs.Status.Minecraft.Players[0].Name).To(Equal("Steve"))
+		Expect(gs.Status.Minecraft.Players[0].UUID).To(Equal("8667ba71-b85a-4004-af54-457a9734eed7"))
+
+		Expect(players(specs.MinecraftWhitelistKey)).To(Equal([]string{"Steve", "Alex"}))
+		Expect(players(specs.MinecraftOpsKey)).To(Equal([]string{"Steve"}))
+
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		Expect(sts.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("ConfigMap.Name", configMapKey.Name)))
+		Expect(sts.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring("white-list 'true'"))
+
+		By("resolving offline UUIDs once online mode is disabled")
+		gs.Spec.GameConfigs.Minecraft.Properties = &gamesv1alpha1.MinecraftProperties{OnlineMode: new(false)}
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(gs.Status.Minecraft.Players[0].Offline).To(BeTrue())
+		Expect(gs.Status.Minecraft.Players[0].UUID).To(Equal(minecraft.OfflinePlayer("Steve").UUID))
+
+		By("removing the ConfigMap once no players are listed")
+		gs.Spec.GameConfigs.Minecraft.Whitelist = nil
+		gs.Spec.GameConfigs.Minecraft.Ops = nil
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		_, gs = reconcileGameServer()
+		Expect(gs.Status.Minecraft.Players).To(BeEmpty())
+		Expect(k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{})).NotTo(Succeed())
+	})
+
+	It("updates the whitelist and ops of the running game server through its console", func() {
+		_, gs := reconcileGameServer()
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "gameserver",
+				Image: "gameservermanagers/gameserver:mc",
+			}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		gs.Spec.GameConfigs.Minecraft.Whitelist = []string{"Steve", "Herobrine"}
+		gs.Spec.GameConfigs.Minecraft.Ops = []string{"Herobrine"}
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		reconcileGameServer()
+
+		Expect(executor.commands).To(Equal([]string{
+			"/app/mcserver send whitelist add Herobrine",
+			"/app/mcserver send whitelist remove Alex",
+			"/app/mcserver send op Herobrine",
+			"/app/mcserver send deop Steve",
+		}))
+		Expect(players(specs.MinecraftWhitelistKey)).To(Equal([]string{"Steve", "Herobrine"}))
+		Expect(players(specs.MinecraftOpsKey)).To(Equal([]string{"Herobrine"}))
+	})
+
+	It("leaves players that cannot be resolved out and retries them later", func() {
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.GameConfigs.Minecraft.Whitelist = append(gs.Spec.GameConfigs.Minecraft.Whitelist, "Nobody")
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+
+		result, gs := reconcileGameServer()
+		Expect(result.RequeueAfter).To(BeNumerically("~", playerResolveRetryInterval, time.Second))
+		Expect(gs.Status.Minecraft.Players).To(HaveLen(3))
+		Expect(gs.Status.Minecraft.Players[2].UUID).To(BeEmpty())
+		Expect(gs.Status.Minecraft.Players[2].Message).To(Equal("there is no Minecraft account named Nobody"))
+		Expect(players(specs.MinecraftWhitelistKey)).To(Equal([]string{"Steve", "Alex"}))
+	})
+})
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const (
+	// serverQueryInterval is how often running game servers are queried, unless spec.idle sets the interval.
+	serverQueryInterval = time.Minute
+
+	// serverQueryTimeout bounds a single query. Queries are not answered while the game is still starting.
+	serverQueryTimeout = 5 * time.Second
+)
+
+// reconcileGameServerQuery queries the running game server through its Service and records what it reports
+// in status.server. It returns when the next query is due, or zero when the game server is not queried.
+//
+// A failed query keeps the last reported values and is retried on the interval, so a game server that is
+// still loading its world is not reported as empty.
+func (r *GameServerReconciler) reconcileGameServerQuery(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	protocol, address, err := r.gameServerQueryAddress(ctx, gs)
+	if err != nil {
+		return 0, err
+	}
+	if address == "" {
+		// Nothing is reported for a game server that is not running.
+		forgetQueryDuration(gs)
+		return 0, r.patchServerStatus(ctx, gs, nil)
+	}
+
+	interval := serverQueryInterval
+	if gs.Spec.Idle != nil {
+		_, interval = specs.GameServerIdleTimeouts(gs)
+	}
+
+	now := time.Now()
+	if gs.Status.Server != nil && gs.Status.Server.LastQueryTime != nil {
+		if next := gs.Status.Server.LastQueryTime.Add(interval); now.Before(next) {
+			return next.Sub(now), nil
+		}
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, serverQueryTimeout)
+	defer cancel()
+
+	var status *gamesv1alpha1.ServerStatus
+	info, err := r.Querier(queryCtx, protocol, address)
+	observeQueryDuration(gs, time.Since(now))
+	if err != nil {
+		status = &gamesv1alpha1.ServerStatus{}
+		if gs.Status.Server != nil {
+			status = gs.Status.Server.DeepCopy()
+		}
+		status.Message = fmt.Sprintf("Query failed: %v", err)
+	} else {
+		status = buildServerStatus(info)
+	}
+	status.LastQueryTime = &metav1.Time{Time: now}
+
+	return interval, r.patchServerStatus(ctx, gs, status)
+}
+
+// gameServerQueryAddress returns the query protocol of the game and the address of the Service port answering
+// it. The address is empty when the game cannot be queried, is not running or its query port is not exposed.
+func (r *GameServerReconciler) gameServerQueryAddress(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (query.Protocol, string, error) {
+	q, ok := linuxgsm.LookupQuery(gs.Spec.GameName)
+	if !ok || r.Querier == nil || gs.Spec.Service == nil {
+		return "", "", nil
+	}
+
+	pod := &corev1.Pod{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}, pod)
+	if err != nil || !found || !podReady(pod) {
+		return "", "", err
+	}
+
+	svc := &corev1.Service{}
+	found, err = r.getOptional(ctx, types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace}, svc)
+	if err != nil || !found || svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", "", err
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.Name == q.PortName && strings.EqualFold(string(port.Protocol), q.Protocol.Network()) {
+			return q.Protocol, net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(port.Port))), nil
+		}
+	}
+	return "", "", nil
+}
+
+func (r *GameServerReconciler) patchServerStatus(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	status *gamesv1alpha1.ServerStatus,
+) error {
+	if equality.Semantic.DeepEqual(gs.Status.Server, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status.Server = status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer server status: %w", err)
+	}
+	return nil
+}
+
+func buildServerStatus(info query.Info) *gamesv1alpha1.ServerStatus {
+	return &gamesv1alpha1.ServerStatus{
+		Name:        info.Name,
+		Map:         info.Map,
+		Version:     info.Version,
+		Players:     int32(info.Players),
+		MaxPlayers:  int32(info.MaxPlayers),
+		PlayerNames: info.PlayerNames,
+	}
+}
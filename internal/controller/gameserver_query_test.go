@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+)
+
+// fakeQuerier answers queries with canned info and records the addresses it queried.
+type fakeQuerier struct {
+	info      query.Info
+	err       error
+	addresses []string
+}
+
+func (q *fakeQuerier) Query(_ context.Context, protocol query.Protocol, address string) (query.Info, error) {
+	q.addresses = append(q.addresses, string(protocol)+" "+address)
+	return q.info, q.err
+}
+
+var _ = Describe("GameServer queries", func() {
+	const name = "query-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+	var (
+		reconciler *GameServerReconciler
+		querier    *fakeQuerier
+	)
+
+	reconcileGameServer := func() (reconcile.Result, *gamesv1alpha1.GameServer) {
+		GinkgoHelper()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		return result, gs
+	}
+
+	BeforeEach(func() {
+		querier = &fakeQuerier{info: query.Info{
+			Name:        "Example server",
+			Version:     "1.21.4",
+			Players:     2,
+			MaxPlayers:  20,
+			PlayerNames: []string{"alice", "bob"},
+		}}
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+			Querier:  querier.Query,
+		}
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+			gs.Spec.Service = &gamesv1alpha1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []gamesv1alpha1.ServicePort{{Name: "game", Port: 25565, Protocol: corev1.ProtocolTCP}},
+			}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "gameserver",
+				Image: "gameservermanagers/gameserver:mc",
+			}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("queries the running game server through its Service", func() {
+		result, gs := reconcileGameServer()
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, key, svc)).To(Succeed())
+		Expect(querier.addresses).To(Equal([]string{"Minecraft " + net.JoinHostPort(svc.Spec.ClusterIP, "25565")}))
+		Expect(result.RequeueAfter).To(BeNumerically("<=", serverQueryInterval))
+
+		Expect(gs.Status.Server).NotTo(BeNil())
+		Expect(gs.Status.Server.LastQueryTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(gs.Status.Server.Name).To(Equal("Example server"))
+		Expect(gs.Status.Server.Version).To(Equal("1.21.4"))
+		Expect(gs.Status.Server.Players).To(BeEquivalentTo(2))
+		Expect(gs.Status.Server.MaxPlayers).To(BeEquivalentTo(20))
+		Expect(gs.Status.Server.PlayerNames).To(Equal([]string{"alice", "bob"}))
+		Expect(gs.Status.Server.Message).To(BeEmpty())
+
+		By("not querying again before the interval elapsed")
+		reconcileGameServer()
+		Expect(querier.addresses).To(HaveLen(1))
+	})
+
+	It("keeps the last reported values when a query fails", func() {
+		_, gs := reconcileGameServer()
+		gs.Status.Server.LastQueryTime = &metav1.Time{Time: time.Now().Add(-2 * serverQueryInterval)}
+		Expect(k8sClient.Status().Update(ctx, gs)).To(Succeed())
+
+		querier.err = errors.New("i/o timeout")
+		_, gs = reconcileGameServer()
+		Expect(querier.addresses).To(HaveLen(2))
+		Expect(gs.Status.Server.Players).To(BeEquivalentTo(2))
+		Expect(gs.Status.Server.Message).To(Equal("Query failed: i/o timeout"))
+		Expect(gs.Status.Server.LastQueryTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("clears the reported values once the game server stopped", func() {
+		_, gs := reconcileGameServer()
+		Expect(gs.Status.Server).NotTo(BeNil())
+
+		Expect(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0))).To(Succeed())
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Name: name + "-0", Namespace: testNamespace}, &corev1.Pod{})
+		}).Should(MatchError(ContainSubstring("not found")))
+
+		_, gs = reconcileGameServer()
+		Expect(gs.Status.Server).To(BeNil())
+	})
+})
@@ -2,6 +2,10 @@ package controller
 
 import (
 	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -11,7 +15,27 @@ import (
 )
 
 func (r *GameServerReconciler) reconcileGameServer(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
-	if err := r.reconcileGameServerStatefulSet(ctx, gs); err != nil {
+	backupJobs, err := r.listBackupJobs(ctx, gs)
+	if err != nil {
+		return err
+	}
+
+	restoring, err := r.restoreInProgress(ctx, gs)
+	if err != nil {
+		return err
+	}
+
+	// The game server is stopped while a backup or restore runs, so the world is saved and not modified
+	// while the data volume is read or replaced.
+	desired := gs
+	if restoring || backupInProgress(backupJobs, time.Now()) {
+		desired = withState(gs, gamesv1alpha1.GameServerStateStopped)
+	}
+	if err := r.reconcileGameServerStatefulSet(ctx, desired); err != nil {
+		return err
+	}
+
+	if err := r.reconcileGameServerVolumeExpansion(ctx, gs); err != nil {
 		return err
 	}
 
@@ -19,6 +43,10 @@ func (r *GameServerReconciler) reconcileGameServer(ctx context.Context, gs *game
 		return err
 	}
 
+	if err := r.reconcileGameServerBackupCronJob(ctx, gs); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -32,15 +60,17 @@ func (r *GameServerReconciler) reconcileGameServerStatefulSet(ctx context.Contex
 }
 
 func (r *GameServerReconciler) reconcileLinuxGSMGameServerStatefulSet(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	existing := &appsv1.StatefulSet{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace}, existing)
+	if err != nil {
+		return err
+	}
+	if found {
+		gs = withVolumeClaimTemplateSize(gs, existing)
+	}
+
 	stsApply := specs.BuildLinuxGSMGameServerStatefulSet(gs)
-	stsApply.WithOwnerReferences(metav1ac.OwnerReference().
-		WithAPIVersion(gs.APIVersion).
-		WithKind(gs.Kind).
-		WithName(gs.Name).
-		WithUID(gs.UID).
-		WithController(true).
-		WithBlockOwnerDeletion(true),
-	)
+	stsApply.WithOwnerReferences(gameServerOwnerReference(gs))
 
 	if err := r.Apply(ctx, stsApply,
 		client.FieldOwner(fieldManagerGameServer),
@@ -58,14 +88,7 @@ func (r *GameServerReconciler) reconcileGameServerService(ctx context.Context, g
 	}
 
 	svcApply := specs.BuildGameServerService(gs)
-	svcApply.WithOwnerReferences(metav1ac.OwnerReference().
-		WithAPIVersion(gs.APIVersion).
-		WithKind(gs.Kind).
-		WithName(gs.Name).
-		WithUID(gs.UID).
-		WithController(true).
-		WithBlockOwnerDeletion(true),
-	)
+	svcApply.WithOwnerReferences(gameServerOwnerReference(gs))
 
 	if err := r.Apply(ctx, svcApply,
 		client.FieldOwner(fieldManagerGameServer),
@@ -76,3 +99,23 @@ func (r *GameServerReconciler) reconcileGameServerService(ctx context.Context, g
 
 	return nil
 }
+
+// withState returns a copy of the GameServer with the given run state, used to stop the game server
+// for backups and deletion without changing the spec.
+func withState(gs *gamesv1alpha1.GameServer, state gamesv1alpha1.GameServerState) *gamesv1alpha1.GameServer {
+	gs = gs.DeepCopy()
+	gs.Spec.State = state
+	return gs
+}
+
+// gameServerOwnerReference makes the GameServer the controlling owner of an applied object,
+// so the object is garbage collected with the GameServer.
+func gameServerOwnerReference(gs *gamesv1alpha1.GameServer) *metav1ac.OwnerReferenceApplyConfiguration {
+	return metav1ac.OwnerReference().
+		WithAPIVersion(gs.APIVersion).
+		WithKind(gs.Kind).
+		WithName(gs.Name).
+		WithUID(gs.UID).
+		WithController(true).
+		WithBlockOwnerDeletion(true)
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+// restoreInProgress reports whether a GameServerRestore stopped, or is stopping, the game server
+// to replace its data.
+func (r *GameServerReconciler) restoreInProgress(ctx context.Context, gs *gamesv1alpha1.GameServer) (bool, error) {
+	restores := &gamesv1alpha1.GameServerRestoreList{}
+	if err := r.List(ctx, restores, client.InNamespace(gs.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list GameServerRestores: %w", err)
+	}
+
+	for i := range restores.Items {
+		if restores.Items[i].Spec.GameServerRef.Name == gs.Name && restoreActive(&restores.Items[i]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// gameServerForRestore maps a GameServerRestore to the GameServer it restores.
+func gameServerForRestore(_ context.Context, obj client.Object) []reconcile.Request {
+	restore, ok := obj.(*gamesv1alpha1.GameServerRestore)
+	if !ok {
+		return nil
+	}
+	name := types.NamespacedName{Name: restore.Spec.GameServerRef.Name, Namespace: restore.Namespace}
+	return []reconcile.Request{{NamespacedName: name}}
+}
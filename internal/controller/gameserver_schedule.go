@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const (
+	// reasonScheduled is the reason of the events of the game server being started and stopped by its schedule.
+	reasonScheduled = "Scheduled"
+
+	// maxScheduleTransitionSteps bounds the window starts and stops stepped through looking for the next time the
+	// schedule changes state, e.g. when windows overlap.
+	maxScheduleTransitionSteps = 1000
+)
+
+// scheduleLookbacks are how far back the most recent start and stop of a window are looked for, growing so that
+// frequent schedules are not stepped through for a year.
+var scheduleLookbacks = []time.Duration{time.Hour, 24 * time.Hour, 8 * 24 * time.Hour, 32 * 24 * time.Hour,
+	367 * 24 * time.Hour}
+
+type scheduleWindow struct {
+	start cron.Schedule
+	stop  cron.Schedule
+}
+
+// reconcileGameServerSchedule reports in status.schedule whether spec.schedule runs the game server, which
+// reconcileGameServer scales the StatefulSet by. It returns when the schedule changes state next, or zero when it
+// never does.
+func (r *GameServerReconciler) reconcileGameServerSchedule(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	if gs.Spec.Schedule == nil {
+		return 0, r.patchScheduleStatus(ctx, gs, nil)
+	}
+
+	windows, err := parseScheduleWindows(gs.Spec.Schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	status := buildScheduleStatus(windows, now)
+
+	previous := gs.Status.Schedule
+	if previous != nil && previous.State != status.State &&
+		specs.GameServerDesiredState(gs) != gamesv1alpha1.GameServerStateStopped {
+		if status.State == gamesv1alpha1.GameServerStateRunning {
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonScheduled, "Start",
+				"Starting the game server, a window of its schedule opened")
+		} else {
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonScheduled, "Stop",
+				"Stopping the game server, the windows of its schedule are closed")
+		}
+	}
+
+	var next time.Duration
+	if status.NextTransitionTime != nil {
+		next = status.NextTransitionTime.Sub(now)
+	}
+	return next, r.patchScheduleStatus(ctx, gs, status)
+}
+
+func parseScheduleWindows(schedule *gamesv1alpha1.ScheduleSpec) ([]scheduleWindow, error) {
+	windows := make([]scheduleWindow, 0, len(schedule.Windows))
+	for _, window := range schedule.Windows {
+		start, err := parseCronSchedule(window.Start, schedule.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseCronSchedule(window.Stop, schedule.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, scheduleWindow{start: start, stop: stop})
+	}
+	return windows, nil
+}
+
+// buildScheduleStatus returns the state of the windows at now and when that changes next.
+// A window is open when it started more recently than it stopped, where a stop at the same time as a start wins.
+func buildScheduleStatus(windows []scheduleWindow, now time.Time) *gamesv1alpha1.ScheduleStatus {
+	open := make([]bool, len(windows))
+	for i, window := range windows {
+		started, ok := lastActivation(window.start, now)
+		if !ok {
+			continue
+		}
+		stopped, ok := lastActivation(window.stop, now)
+		open[i] = !ok || started.After(stopped)
+	}
+
+	running := anyOpen(open)
+	status := &gamesv1alpha1.ScheduleStatus{State: scheduleState(running)}
+	if next, ok := nextScheduleTransition(windows, open, now); ok {
+		status.NextTransitionTime = &metav1.Time{Time: next}
+		status.NextState = scheduleState(!running)
+	}
+	return status
+}
+
+// nextScheduleTransition steps through the starts and stops of the windows after now until any window being open
+// changes. It updates open to the state of the windows at that time.
+func nextScheduleTransition(windows []scheduleWindow, open []bool, now time.Time) (time.Time, bool) {
+	running := anyOpen(open)
+	t := now
+	for range maxScheduleTransitionSteps {
+		var next time.Time
+		for _, window := range windows {
+			for _, activation := range []time.Time{window.start.Next(t), window.stop.Next(t)} {
+				if !activation.IsZero() && (next.IsZero() || activation.Before(next)) {
+					next = activation
+				}
+			}
+		}
+		if next.IsZero() {
+			return time.Time{}, false
+		}
+
+		for i, window := range windows {
+			switch {
+			case window.stop.Next(t).Equal(next):
+				open[i] = false
+			case window.start.Next(t).Equal(next):
+				open[i] = true
+			}
+		}
+		if anyOpen(open) != running {
+			return next, true
+		}
+		t = next
+	}
+	return time.Time{}, false
+}
+
+// lastActivation returns the most recent activation of the schedule at or before now, looking back at most a year.
+func lastActivation(schedule cron.Schedule, now time.Time) (time.Time, bool) {
+	for _, lookback := range scheduleLookbacks {
+		last := schedule.Next(now.Add(-lookback))
+		if last.IsZero() || last.After(now) {
+			continue
+		}
+		for {
+			next := schedule.Next(last)
+			if next.IsZero() || next.After(now) {
+				return last, true
+			}
+			last = next
+		}
+	}
+	return time.Time{}, false
+}
+
+func anyOpen(open []bool) bool {
+	for _, o := range open {
+		if o {
+			return true
+		}
+	}
+	return false
+}
+
+func scheduleState(running bool) gamesv1alpha1.GameServerState {
+	if running {
+		return gamesv1alpha1.GameServerStateRunning
+	}
+	return gamesv1alpha1.GameServerStateStopped
+}
+
+// patchScheduleStatus sets status.schedule, or removes it when status is nil.
+func (r *GameServerReconciler) patchScheduleStatus(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	status *gamesv1alpha1.ScheduleStatus,
+) error {
+	if equality.Semantic.DeepEqual(gs.Status.Schedule, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status.Schedule = status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer schedule status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer schedule", func() {
+	// 2 January 2026 is a Friday.
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2026, 1, 2, hour, minute, 0, 0, time.UTC)
+	}
+
+	evenings := &gamesv1alpha1.ScheduleSpec{Windows: []gamesv1alpha1.ScheduleWindow{
+		{Start: "0 18 * * 1-5", Stop: "0 23 * * 1-5"},
+		{Start: "0 10 * * 6,0", Stop: "0 23 * * 6,0"},
+	}}
+
+	scheduleStatus := func(schedule *gamesv1alpha1.ScheduleSpec, now time.Time) *gamesv1alpha1.ScheduleStatus {
+		GinkgoHelper()
+		windows, err := parseScheduleWindows(schedule)
+		Expect(err).NotTo(HaveOccurred())
+		return buildScheduleStatus(windows, now)
+	}
+
+	It("runs the game server within its windows", func() {
+		status := scheduleStatus(evenings, friday(20, 0))
+		Expect(status.State).To(Equal(gamesv1alpha1.GameServerStateRunning))
+		Expect(status.NextState).To(Equal(gamesv1alpha1.GameServerStateStopped))
+		Expect(status.NextTransitionTime.Time).To(BeTemporally("==", friday(23, 0)))
+
+		status = scheduleStatus(evenings, friday(23, 30))
+		Expect(status.State).To(Equal(gamesv1alpha1.GameServerStateStopped))
+		Expect(status.NextState).To(Equal(gamesv1alpha1.GameServerStateRunning))
+		Expect(status.NextTransitionTime.Time).To(BeTemporally("==", friday(23, 0).Add(11*time.Hour)))
+	})
+
+	It("keeps running while overlapping windows are open", func() {
+		status := scheduleStatus(&gamesv1alpha1.ScheduleSpec{Windows: []gamesv1alpha1.ScheduleWindow{
+			{Start: "0 18 * * *", Stop: "0 22 * * *"},
+			{Start: "0 20 * * *", Stop: "0 23 * * *"},
+		}}, friday(19, 0))
+		Expect(status.State).To(Equal(gamesv1alpha1.GameServerStateRunning))
+		Expect(status.NextTransitionTime.Time).To(BeTemporally("==", friday(23, 0)))
+	})
+
+	It("interprets the windows in their time zone", func() {
+		schedule := evenings.DeepCopy()
+		schedule.TimeZone = new("Europe/Amsterdam")
+		status := scheduleStatus(schedule, friday(17, 30))
+		Expect(status.State).To(Equal(gamesv1alpha1.GameServerStateRunning))
+		Expect(status.NextTransitionTime.Time).To(BeTemporally("==", friday(22, 0)))
+	})
+
+	It("reports no transition for windows that never open", func() {
+		status := scheduleStatus(&gamesv1alpha1.ScheduleSpec{Windows: []gamesv1alpha1.ScheduleWindow{
+			{Start: "0 0 30 2 *", Stop: "0 1 30 2 *"},
+		}}, friday(12, 0))
+		Expect(status.State).To(Equal(gamesv1alpha1.GameServerStateStopped))
+		Expect(status.NextTransitionTime).To(BeNil())
+		Expect(status.NextState).To(BeEmpty())
+	})
+
+	Context("when reconciling", func() {
+		const name = "schedule-test"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+		var reconciler *GameServerReconciler
+
+		reconcileGameServer := func() (reconcile.Result, *gamesv1alpha1.GameServer) {
+			GinkgoHelper()
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			return result, gs
+		}
+
+		replicas := func() int32 {
+			GinkgoHelper()
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+			return *sts.Spec.Replicas
+		}
+
+		BeforeEach(func() {
+			reconciler = &GameServerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &events.FakeRecorder{},
+			}
+
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = name
+				gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+				// The window only opens for a minute on New Year's Day.
+				gs.Spec.Schedule = &gamesv1alpha1.ScheduleSpec{Windows: []gamesv1alpha1.ScheduleWindow{
+					{Start: "0 0 1 1 *", Stop: "1 0 1 1 *"},
+				}}
+			})
+			Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			}))).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			}))).To(Succeed())
+		})
+
+		It("stops the game server outside of its windows until the next one opens", func() {
+			before := time.Now()
+			result, gs := reconcileGameServer()
+			Expect(gs.Status.Schedule).NotTo(BeNil())
+			Expect(gs.Status.Schedule.State).To(Equal(gamesv1alpha1.GameServerStateStopped))
+			Expect(gs.Status.Schedule.NextState).To(Equal(gamesv1alpha1.GameServerStateRunning))
+			Expect(gs.Status.Schedule.NextTransitionTime).NotTo(BeNil())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", gs.Status.Schedule.NextTransitionTime.Sub(before)))
+			Expect(replicas()).To(BeEquivalentTo(0))
+
+			By("starting the game server once the schedule is removed")
+			gs.Spec.Schedule = nil
+			Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+			_, gs = reconcileGameServer()
+			Expect(gs.Status.Schedule).To(BeNil())
+			Expect(replicas()).To(BeEquivalentTo(1))
+		})
+	})
+})
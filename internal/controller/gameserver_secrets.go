@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+const reasonPasswordsGenerated = "PasswordsGenerated"
+
+// reconcileGameServerSecrets generates the passwords of spec.secrets with generate set into the Secret of the
+// GameServer, which the game server container reads them from. Passwords that were generated before are kept, so
+// they only change when generate is unset or the Secret is deleted. The Secret is deleted once no password is
+// generated. A Secret of the same name that the GameServer does not control is left alone and reported as an error.
+//
+// The Secret is read from the API server, as a cache that has not seen a Secret created by an earlier reconcile yet
+// would replace the passwords that were handed out, and restart the game server.
+func (r *GameServerReconciler) reconcileGameServerSecrets(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	keys := specs.GameServerGeneratedSecretKeys(gs)
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: specs.GameServerSecretName(gs), Namespace: gs.Namespace}
+	found, err := r.getFromAPI(ctx, key, secret)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		if !found || !metav1.IsControlledBy(secret, gs) {
+			return nil
+		}
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete GameServer Secret: %w", err)
+		}
+		return nil
+	}
+
+	// The Secret of another owner is not taken over, as its keys would be overwritten.
+	if found && !metav1.IsControlledBy(secret, gs) {
+		return fmt.Errorf("secret %s exists and is not controlled by the GameServer", key.Name)
+	}
+
+	data := make(map[string][]byte, len(keys))
+	var generated []string
+	for _, k := range keys {
+		if password := secret.Data[k]; len(password) > 0 {
+			data[k] = password
+			continue
+		}
+		data[k] = []byte(rand.Text())
+		generated = append(generated, k)
+	}
+
+	secretApply := specs.BuildGameServerSecret(gs, data).
+		WithOwnerReferences(gameServerOwnerReference(gs))
+	if err := r.Apply(ctx, secretApply,
+		client.FieldOwner(fieldManagerGameServer),
+		client.ForceOwnership,
+	); err != nil {
+		return fmt.Errorf("failed to apply GameServer Secret: %w", err)
+	}
+	if len(generated) > 0 {
+		r.recordEvent(gs, corev1.EventTypeNormal, reasonPasswordsGenerated, "GeneratePasswords",
+			"Generated %s in Secret %s", strings.Join(generated, ", "), key.Name)
+	}
+	return nil
+}
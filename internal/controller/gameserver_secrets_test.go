@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServer secrets", func() {
+	const name = "secrets-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	secretKey := types.NamespacedName{Name: name + "-secrets", Namespace: testNamespace}
+
+	var reconciler *GameServerReconciler
+
+	reconcileGameServer := func() {
+		GinkgoHelper()
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+		}
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "vh"
+			gs.Spec.Secrets = &gamesv1alpha1.SecretsSpec{
+				ServerPassword: &gamesv1alpha1.SecretSource{Generate: true},
+			}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("generates passwords into a Secret owned by the GameServer", func() {
+		reconcileGameServer()
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(ConsistOf(HaveField("Name", name)))
+		Expect(secret.Data).To(HaveLen(1))
+		password := secret.Data[specs.SecretKeyServerPassword]
+		Expect(password).To(HaveLen(26))
+
+		By("keeping the generated passwords")
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.Secrets.AdminPassword = &gamesv1alpha1.SecretSource{Generate: true}
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		reconcileGameServer()
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue(specs.SecretKeyServerPassword, password))
+		Expect(secret.Data[specs.SecretKeyAdminPassword]).To(HaveLen(26))
+
+		sts := &appsv1.StatefulSet{}
+		Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+		Expect(sts.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: "SERVER_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretKey.Name},
+				Key:                  specs.SecretKeyServerPassword,
+			}},
+		}))
+	})
+
+	It("keeps the generated passwords while the cache has not seen the Secret", func() {
+		reconcileGameServer()
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		password := secret.Data[specs.SecretKeyServerPassword]
+
+		reconciler.Client = staleCacheClient{Client: k8sClient}
+		reconciler.APIReader = k8sClient
+		reconcileGameServer()
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue(specs.SecretKeyServerPassword, password))
+	})
+
+	It("does not take over a Secret it does not control", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: testNamespace},
+			Data:       map[string][]byte{"other": []byte("value")},
+		})).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(MatchError(ContainSubstring("is not controlled by the GameServer")))
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(BeEmpty())
+		Expect(secret.Data).To(Equal(map[string][]byte{"other": []byte("value")}))
+	})
+
+	It("deletes the Secret once no password is generated", func() {
+		reconcileGameServer()
+		Expect(k8sClient.Get(ctx, secretKey, &corev1.Secret{})).To(Succeed())
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.Secrets = nil
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+		reconcileGameServer()
+		err := k8sClient.Get(ctx, secretKey, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected the Secret to be deleted, got %v", err)
+	})
+})
+
+// staleCacheClient is a client whose cache has not seen any ConfigMap or Secret yet.
+type staleCacheClient struct {
+	ctrlclient.Client
+}
+
+func (c staleCacheClient) Get(
+	ctx context.Context,
+	key ctrlclient.ObjectKey,
+	obj ctrlclient.Object,
+	opts ...ctrlclient.GetOption,
+) error {
+	switch obj.(type) {
+	case *corev1.ConfigMap:
+		return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+	case *corev1.Secret:
+		return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
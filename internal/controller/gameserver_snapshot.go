@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for snapshot events.
+const (
+	reasonSnapshotCreated      = "SnapshotCreated"
+	reasonFinalSnapshotStarted = "FinalSnapshotStarted"
+	reasonFinalSnapshotFailed  = "FinalSnapshotFailed"
+)
+
+// reconcileGameServerSnapshots takes the scheduled VolumeSnapshots of the game server and removes the ones
+// beyond retention. There is no CronJob for Snapshot backups, so it returns when the next snapshot is due,
+// or zero when no snapshots are scheduled.
+func (r *GameServerReconciler) reconcileGameServerSnapshots(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	backup := gs.Spec.Backup
+	if specs.GameServerBackupMode(gs) != gamesv1alpha1.BackupModeSnapshot || backup.Suspend ||
+		!specs.LinuxGSMStorageEnabled(gs) {
+		return 0, nil
+	}
+
+	schedule, err := parseBackupSchedule(backup)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshots, err := r.listSnapshots(ctx, gs)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	next := nextSnapshotTime(schedule, gs.CreationTimestamp.Time, snapshots)
+	if !now.Before(next) {
+		// The game server keeps running while the volume is snapshotted, so the world is saved first.
+		r.saveWorld(ctx, gs)
+
+		// The snapshot is named after its scheduled time, so a retried reconcile does not take it twice.
+		snapshot := specs.BuildGameServerVolumeSnapshot(gs, specs.GameServerSnapshotName(gs, next))
+		switch err := r.Create(ctx, snapshot); {
+		case err == nil:
+			r.recordEvent(gs, corev1.EventTypeNormal, reasonSnapshotCreated, "Backup",
+				"Created VolumeSnapshot %s", snapshot.GetName())
+			snapshots = append(snapshots, *snapshot)
+		case !apierrors.IsAlreadyExists(err):
+			return 0, fmt.Errorf("failed to create VolumeSnapshot: %w", err)
+		}
+		next = schedule.Next(now)
+	}
+
+	retention := int(backup.Retention)
+	if retention < 1 {
+		retention = 1
+	}
+	for _, snapshot := range snapshotsBeyondRetention(snapshots, retention) {
+		if err := r.Delete(ctx, &snapshot); err != nil && !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete VolumeSnapshot %s: %w", snapshot.GetName(), err)
+		}
+	}
+
+	return next.Sub(now), nil
+}
+
+// reconcileFinalSnapshot snapshots the data volume of a deleted GameServer once it has stopped,
+// and reports whether the snapshot is done. A failed snapshot is reported as an event but does not
+// block the deletion.
+func (r *GameServerReconciler) reconcileFinalSnapshot(ctx context.Context, gs *gamesv1alpha1.GameServer) (bool, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(specs.VolumeSnapshotGroupVersionKind)
+	key := types.NamespacedName{Name: specs.GameServerFinalSnapshotName(gs), Namespace: gs.Namespace}
+	found, err := r.getOptional(ctx, key, snapshot)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		if err := r.Create(ctx, specs.BuildGameServerFinalVolumeSnapshot(gs)); err != nil {
+			return false, fmt.Errorf("failed to create final VolumeSnapshot: %w", err)
+		}
+		r.recordEvent(gs, corev1.EventTypeNormal, reasonFinalSnapshotStarted, "Delete",
+			"Taking final VolumeSnapshot %s before deletion", key.Name)
+		return false, nil
+	}
+
+	if message, failed, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); failed {
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonFinalSnapshotFailed, "Delete",
+			"Final VolumeSnapshot %s failed, continuing with the deletion: %s", key.Name, message)
+		return true, nil
+	}
+
+	return snapshotReady(snapshot), nil
+}
+
+// listSnapshots returns the VolumeSnapshots of the game server.
+func (r *GameServerReconciler) listSnapshots(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) ([]unstructured.Unstructured, error) {
+	snapshots := &unstructured.UnstructuredList{}
+	snapshots.SetGroupVersionKind(specs.VolumeSnapshotGroupVersionKind.GroupVersion().WithKind("VolumeSnapshotList"))
+	if err := r.List(ctx, snapshots,
+		client.InNamespace(gs.Namespace),
+		client.MatchingLabels(specs.GameServerSnapshotLabels(gs)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to list VolumeSnapshots, are the snapshot CRDs installed? %w", err)
+	}
+	return snapshots.Items, nil
+}
+
+// buildSnapshotBackupStatus derives the backup status from the VolumeSnapshots of the game server.
+func buildSnapshotBackupStatus(
+	current *gamesv1alpha1.BackupStatus,
+	snapshots []unstructured.Unstructured,
+) *gamesv1alpha1.BackupStatus {
+	if len(snapshots) == 0 {
+		return current
+	}
+
+	status := &gamesv1alpha1.BackupStatus{}
+	if current != nil {
+		status = current.DeepCopy()
+	}
+
+	for i := range snapshots {
+		created := snapshots[i].GetCreationTimestamp()
+		if status.LastScheduleTime == nil || status.LastScheduleTime.Before(&created) {
+			status.LastScheduleTime = &created
+		}
+		if !snapshotReady(&snapshots[i]) {
+			continue
+		}
+		if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(&created) {
+			status.LastSuccessfulTime = &created
+			status.LastSnapshot = snapshots[i].GetName()
+		}
+	}
+
+	return status
+}
+
+// parseBackupSchedule parses the cron schedule of the backups in their time zone.
+func parseBackupSchedule(backup *gamesv1alpha1.BackupSpec) (cron.Schedule, error) {
+	return parseCronSchedule(backup.Schedule, backup.TimeZone)
+}
+
+// parseCronSchedule parses a standard cron schedule, interpreted in the time zone when set and UTC otherwise.
+func parseCronSchedule(schedule string, timeZone *string) (cron.Schedule, error) {
+	spec := schedule
+	if timeZone != nil {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", *timeZone, spec)
+	}
+	parsed, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return parsed, nil
+}
+
+// nextSnapshotTime returns when the next snapshot is due, based on the most recent snapshot or, without
+// snapshots, the creation of the GameServer. A snapshot missed while the operator was down is due immediately.
+func nextSnapshotTime(schedule cron.Schedule, created time.Time, snapshots []unstructured.Unstructured) time.Time {
+	last := created
+	for i := range snapshots {
+		if t := snapshots[i].GetCreationTimestamp().Time; t.After(last) {
+			last = t
+		}
+	}
+	return schedule.Next(last)
+}
+
+// snapshotsBeyondRetention returns all but the most recent retention ready snapshots. Snapshots that are not ready
+// do not count, so failed snapshots never replace good ones, and the final snapshot is never pruned.
+func snapshotsBeyondRetention(snapshots []unstructured.Unstructured, retention int) []unstructured.Unstructured {
+	var sorted []unstructured.Unstructured
+	for i := range snapshots {
+		if snapshotReady(&snapshots[i]) && snapshots[i].GetLabels()[specs.SnapshotFinalLabel] == "" {
+			sorted = append(sorted, snapshots[i])
+		}
+	}
+	if len(sorted) <= retention {
+		return nil
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].GetCreationTimestamp(), sorted[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return sorted[i].GetName() > sorted[j].GetName()
+	})
+	return sorted[retention:]
+}
+
+func snapshotReady(snapshot *unstructured.Unstructured) bool {
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServer snapshots", func() {
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newSnapshot := func(name string, at time.Time, ready bool) unstructured.Unstructured {
+		snapshot := unstructured.Unstructured{Object: map[string]any{}}
+		snapshot.SetName(name)
+		snapshot.SetCreationTimestamp(metav1.NewTime(at))
+		Expect(unstructured.SetNestedField(snapshot.Object, ready, "status", "readyToUse")).To(Succeed())
+		return snapshot
+	}
+
+	It("schedules the next snapshot after the most recent one", func() {
+		schedule, err := parseBackupSchedule(&gamesv1alpha1.BackupSpec{Schedule: "0 4 * * *"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(nextSnapshotTime(schedule, created, nil)).To(Equal(time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)))
+
+		snapshots := []unstructured.Unstructured{newSnapshot("a", created.Add(16*time.Hour+time.Second), true)}
+		Expect(nextSnapshotTime(schedule, created, snapshots)).
+			To(BeTemporally("==", time.Date(2026, 1, 3, 4, 0, 0, 0, time.UTC)))
+	})
+
+	It("interprets the schedule in its time zone", func() {
+		schedule, err := parseBackupSchedule(&gamesv1alpha1.BackupSpec{
+			Schedule: "0 4 * * *",
+			TimeZone: new("Europe/Amsterdam"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nextSnapshotTime(schedule, created, nil).UTC()).To(Equal(time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)))
+	})
+
+	It("keeps the most recent snapshots", func() {
+		snapshots := []unstructured.Unstructured{
+			newSnapshot("b", created.Add(24*time.Hour), true),
+			newSnapshot("a", created, true),
+			newSnapshot("c", created.Add(48*time.Hour), true),
+		}
+
+		pruned := snapshotsBeyondRetention(snapshots, 2)
+		Expect(pruned).To(HaveLen(1))
+		Expect(pruned[0].GetName()).To(Equal("a"))
+		Expect(snapshotsBeyondRetention(snapshots, 3)).To(BeEmpty())
+	})
+
+	It("only prunes ready snapshots that are not final", func() {
+		final := newSnapshot("final", created.Add(-time.Hour), true)
+		final.SetLabels(map[string]string{specs.SnapshotFinalLabel: "true"})
+		snapshots := []unstructured.Unstructured{
+			final,
+			newSnapshot("a", created, true),
+			newSnapshot("b", created.Add(24*time.Hour), false),
+			newSnapshot("c", created.Add(48*time.Hour), false),
+		}
+
+		Expect(snapshotsBeyondRetention(snapshots, 1)).To(BeEmpty())
+
+		snapshots = append(snapshots, newSnapshot("d", created.Add(72*time.Hour), true))
+		pruned := snapshotsBeyondRetention(snapshots, 1)
+		Expect(pruned).To(HaveLen(1))
+		Expect(pruned[0].GetName()).To(Equal("a"))
+	})
+
+	It("reports the most recent ready snapshot", func() {
+		status := buildSnapshotBackupStatus(nil, []unstructured.Unstructured{
+			newSnapshot("a", created, true),
+			newSnapshot("b", created.Add(24*time.Hour), false),
+		})
+
+		Expect(status.LastScheduleTime.Time).To(BeTemporally("==", created.Add(24*time.Hour)))
+		Expect(status.LastSuccessfulTime.Time).To(BeTemporally("==", created))
+		Expect(status.LastSnapshot).To(Equal("a"))
+	})
+})
@@ -0,0 +1,414 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for the GameServer status conditions.
+const (
+	reasonStatefulSetMissing = "StatefulSetMissing"
+	reasonStopped            = "Stopped"
+	reasonStopping           = "Stopping"
+	reasonPodMissing         = "PodMissing"
+	reasonPodPending         = "PodPending"
+	reasonUnschedulable      = "Unschedulable"
+	reasonVolumeClaimPending = "PersistentVolumeClaimPending"
+	reasonImagePullError     = "ImagePullError"
+	reasonCrashLoopBackOff   = "CrashLoopBackOff"
+	reasonContainerError     = "ContainerError"
+	reasonPodFailed          = "PodFailed"
+	reasonInstalling         = "Installing"
+	reasonRollingUpdate      = "RollingUpdate"
+	reasonReady              = "Ready"
+	reasonAsExpected         = "AsExpected"
+	reasonReconcileError     = "ReconcileError"
+	reasonMultipleReplicas   = "MultipleReplicasUnsupported"
+)
+
+// gameServerObservation holds the owned objects the GameServer status is derived from.
+// Any of the fields may be nil when the object does not exist (yet).
+type gameServerObservation struct {
+	statefulSet *appsv1.StatefulSet
+	pod         *corev1.Pod
+	pvc         *corev1.PersistentVolumeClaim
+	service     *corev1.Service
+	node        *corev1.Node
+
+	backupJobs    []batchv1.Job
+	lastBackupPod *corev1.Pod
+	snapshots     []unstructured.Unstructured
+	restoring     bool
+
+	// volumeExpansionAllowed is only looked up when the data volume is smaller than requested.
+	volumeExpansionAllowed bool
+}
+
+// conditionState is the desired state of a single condition, without bookkeeping fields.
+type conditionState struct {
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+}
+
+// gameServerState is the phase and conditions derived from a gameServerObservation.
+type gameServerState struct {
+	phase       gamesv1alpha1.GameServerPhase
+	available   conditionState
+	progressing conditionState
+	degraded    conditionState
+}
+
+func (r *GameServerReconciler) reconcileGameServerStatus(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	obs, err := r.observeGameServer(ctx, gs)
+	if err != nil {
+		return err
+	}
+
+	status := gs.Status.DeepCopy()
+	applyGameServerState(status, gs.Generation, deriveGameServerState(obs))
+	applyReplicasIgnoredCondition(status, gs)
+	applyStorageResizedCondition(status, gs, obs)
+	status.Storage = buildStorageStatus(obs.pvc)
+	status.Endpoints = buildGameServerEndpoints(obs)
+	if specs.GameServerBackupMode(gs) == gamesv1alpha1.BackupModeSnapshot {
+		status.Backup = buildSnapshotBackupStatus(status.Backup, obs.snapshots)
+	} else {
+		status.Backup = buildBackupStatus(status.Backup, obs.backupJobs, obs.lastBackupPod)
+	}
+
+	if equality.Semantic.DeepEqual(&gs.Status, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status = *status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *GameServerReconciler) observeGameServer(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (*gameServerObservation, error) {
+	obs := &gameServerObservation{}
+
+	sts := &appsv1.StatefulSet{}
+	if found, err := r.getOptional(ctx, types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace}, sts); err != nil {
+		return nil, err
+	} else if found {
+		obs.statefulSet = sts
+	}
+
+	pod := &corev1.Pod{}
+	podName := specs.GameServerPodName(gs)
+	if found, err := r.getOptional(ctx, types.NamespacedName{Name: podName, Namespace: gs.Namespace}, pod); err != nil {
+		return nil, err
+	} else if found {
+		obs.pod = pod
+	}
+
+	if specs.LinuxGSMStorageEnabled(gs) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvcName := specs.GameServerDataVolumeClaimName(gs)
+		if found, err := r.getOptional(ctx, types.NamespacedName{Name: pvcName, Namespace: gs.Namespace}, pvc); err != nil {
+			return nil, err
+		} else if found {
+			obs.pvc = pvc
+		}
+	}
+
+	if obs.pvc != nil && volumeExpansionRequested(gs, obs.pvc) {
+		allowed, err := r.volumeExpansionAllowed(ctx, obs.pvc)
+		if err != nil {
+			return nil, err
+		}
+		obs.volumeExpansionAllowed = allowed
+	}
+
+	if gs.Spec.Service != nil {
+		svc := &corev1.Service{}
+		if found, err := r.getOptional(ctx, types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace}, svc); err != nil {
+			return nil, err
+		} else if found {
+			obs.service = svc
+		}
+	}
+
+	// The node is only needed to resolve the external address of node ports.
+	if obs.service != nil && obs.service.Spec.Type == corev1.ServiceTypeNodePort &&
+		obs.pod != nil && obs.pod.Spec.NodeName != "" {
+		node := &corev1.Node{}
+		if found, err := r.getOptional(ctx, types.NamespacedName{Name: obs.pod.Spec.NodeName}, node); err != nil {
+			return nil, err
+		} else if found {
+			obs.node = node
+		}
+	}
+
+	backupJobs, err := r.listBackupJobs(ctx, gs)
+	if err != nil {
+		return nil, err
+	}
+	obs.backupJobs = backupJobs
+	if obs.lastBackupPod, err = r.lastSuccessfulBackupPod(ctx, gs, backupJobs); err != nil {
+		return nil, err
+	}
+	if obs.restoring, err = r.restoreInProgress(ctx, gs); err != nil {
+		return nil, err
+	}
+
+	if specs.GameServerBackupMode(gs) == gamesv1alpha1.BackupModeSnapshot && specs.LinuxGSMStorageEnabled(gs) {
+		if obs.snapshots, err = r.listSnapshots(ctx, gs); err != nil {
+			return nil, err
+		}
+	}
+
+	return obs, nil
+}
+
+// getOptional fetches an object and reports whether it exists.
+func (r *GameServerReconciler) getOptional(ctx context.Context, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := r.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %T %s: %w", obj, key, err)
+	}
+	return true, nil
+}
+
+// apiReader returns the reader of objects that are read from the API server rather than the cache.
+func (r *GameServerReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// setReconcileErrorStatus marks the GameServer as degraded after reconciling its owned objects failed.
+// Errors while updating the status are ignored, as the original error is returned to the caller anyway.
+func (r *GameServerReconciler) setReconcileErrorStatus(ctx context.Context, gs *gamesv1alpha1.GameServer, reconcileErr error) {
+	patch := client.MergeFrom(gs.DeepCopy())
+	meta.SetStatusCondition(&gs.Status.Conditions, metav1.Condition{
+		Type:               gamesv1alpha1.GameServerConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonReconcileError,
+		Message:            reconcileErr.Error(),
+		ObservedGeneration: gs.Generation,
+	})
+	_ = r.Status().Patch(ctx, gs, patch)
+}
+
+func applyGameServerState(status *gamesv1alpha1.GameServerStatus, generation int64, state gameServerState) {
+	status.ObservedGeneration = generation
+	status.Phase = state.phase
+
+	for _, c := range []struct {
+		condType string
+		state    conditionState
+	}{
+		{gamesv1alpha1.GameServerConditionAvailable, state.available},
+		{gamesv1alpha1.GameServerConditionProgressing, state.progressing},
+		{gamesv1alpha1.GameServerConditionDegraded, state.degraded},
+	} {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               c.condType,
+			Status:             c.state.status,
+			Reason:             c.state.reason,
+			Message:            c.state.message,
+			ObservedGeneration: generation,
+		})
+	}
+}
+
+// applyReplicasIgnoredCondition reports when the deprecated spec.replicas requests more than the single
+// instance a GameServer runs, and clears the condition again once it no longer does.
+func applyReplicasIgnoredCondition(status *gamesv1alpha1.GameServerStatus, gs *gamesv1alpha1.GameServer) {
+	if gs.Spec.Replicas <= 1 {
+		meta.RemoveStatusCondition(&status.Conditions, gamesv1alpha1.GameServerConditionReplicasIgnored)
+		return
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:   gamesv1alpha1.GameServerConditionReplicasIgnored,
+		Status: metav1.ConditionTrue,
+		Reason: reasonMultipleReplicas,
+		Message: fmt.Sprintf("spec.replicas is %d, but a GameServer always runs a single instance; "+
+			"use spec.state to start or stop the game server", gs.Spec.Replicas),
+		ObservedGeneration: gs.Generation,
+	})
+}
+
+// deriveGameServerState maps the observed StatefulSet, Pod and PVC onto a phase and conditions.
+// Checks are ordered from most to least specific, so e.g. a crash looping container
+// is reported as such rather than as a pod that is not ready.
+func deriveGameServerState(obs *gameServerObservation) gameServerState {
+	notDegraded := conditionState{status: metav1.ConditionFalse, reason: reasonAsExpected}
+
+	sts := obs.statefulSet
+	if sts == nil {
+		msg := "Waiting for the StatefulSet to be created"
+		return pendingState(reasonStatefulSetMissing, msg)
+	}
+
+	if obs.restoring {
+		msg := "Restoring the game server data from a backup, the game server is started again afterwards"
+		return gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseStopped,
+			available:   conditionState{status: metav1.ConditionFalse, reason: reasonRestoring, message: msg},
+			progressing: conditionState{status: metav1.ConditionTrue, reason: reasonRestoring, message: msg},
+			degraded:    notDegraded,
+		}
+	}
+
+	if backupInProgress(obs.backupJobs, time.Now()) {
+		msg := "Backing up the game server data, the game server is started again afterwards"
+		backingUp := conditionState{status: metav1.ConditionTrue, reason: reasonBackingUp, message: msg}
+		return gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseStopped,
+			available:   conditionState{status: metav1.ConditionFalse, reason: reasonBackingUp, message: msg},
+			progressing: backingUp,
+			degraded:    notDegraded,
+		}
+	}
+
+	if sts.Spec.Replicas != nil && *sts.Spec.Replicas == 0 {
+		if sts.Status.Replicas == 0 {
+			stopped := conditionState{status: metav1.ConditionFalse, reason: reasonStopped, message: "Game server is stopped"}
+			return gameServerState{
+				phase:       gamesv1alpha1.GameServerPhaseStopped,
+				available:   stopped,
+				progressing: stopped,
+				degraded:    notDegraded,
+			}
+		}
+		stopping := conditionState{status: metav1.ConditionTrue, reason: reasonStopping, message: "Game server is stopping"}
+		return gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseRunning,
+			available:   conditionState{status: metav1.ConditionFalse, reason: reasonStopping, message: stopping.message},
+			progressing: stopping,
+			degraded:    notDegraded,
+		}
+	}
+
+	pod := obs.pod
+	if pod == nil {
+		return pendingState(reasonPodMissing, "Waiting for the game server pod to be created")
+	}
+
+	if reason, msg, failed := podFailure(pod); failed {
+		degraded := conditionState{status: metav1.ConditionTrue, reason: reason, message: msg}
+		return gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseFailed,
+			available:   conditionState{status: metav1.ConditionFalse, reason: reason, message: msg},
+			progressing: conditionState{status: metav1.ConditionFalse, reason: reason, message: msg},
+			degraded:    degraded,
+		}
+	}
+
+	if obs.pvc != nil && obs.pvc.Status.Phase == corev1.ClaimPending {
+		msg := fmt.Sprintf("Waiting for PersistentVolumeClaim %s to be bound", obs.pvc.Name)
+		return pendingState(reasonVolumeClaimPending, msg)
+	}
+
+	if cond := podCondition(pod, corev1.PodScheduled); cond != nil && cond.Status == corev1.ConditionFalse {
+		return pendingState(reasonUnschedulable, cond.Message)
+	}
+
+	if cond := podCondition(pod, corev1.PodReady); cond != nil && cond.Status == corev1.ConditionTrue {
+		state := gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseRunning,
+			available:   conditionState{status: metav1.ConditionTrue, reason: reasonReady, message: "Game server is ready"},
+			progressing: conditionState{status: metav1.ConditionFalse, reason: reasonReady, message: "Game server is ready"},
+			degraded:    notDegraded,
+		}
+		if sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+			state.progressing = conditionState{
+				status:  metav1.ConditionTrue,
+				reason:  reasonRollingUpdate,
+				message: fmt.Sprintf("Rolling out revision %s", sts.Status.UpdateRevision),
+			}
+		}
+		return state
+	}
+
+	if pod.Status.Phase == corev1.PodRunning {
+		msg := "Game server is running but not ready yet, it may still be installing or updating"
+		return gameServerState{
+			phase:       gamesv1alpha1.GameServerPhaseInstalling,
+			available:   conditionState{status: metav1.ConditionFalse, reason: reasonInstalling, message: msg},
+			progressing: conditionState{status: metav1.ConditionTrue, reason: reasonInstalling, message: msg},
+			degraded:    notDegraded,
+		}
+	}
+
+	return pendingState(reasonPodPending, fmt.Sprintf("Game server pod is %s", pod.Status.Phase))
+}
+
+func pendingState(reason, msg string) gameServerState {
+	return gameServerState{
+		phase:       gamesv1alpha1.GameServerPhasePending,
+		available:   conditionState{status: metav1.ConditionFalse, reason: reason, message: msg},
+		progressing: conditionState{status: metav1.ConditionTrue, reason: reason, message: msg},
+		degraded:    conditionState{status: metav1.ConditionFalse, reason: reasonAsExpected},
+	}
+}
+
+// podFailure reports whether the pod is stuck in a state that will not resolve without intervention.
+func podFailure(pod *corev1.Pod) (reason, message string, failed bool) {
+	if pod.Status.Phase == corev1.PodFailed {
+		return reasonPodFailed, pod.Status.Message, true
+	}
+
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		waiting := cs.State.Waiting
+		msg := fmt.Sprintf("Container %s: %s", cs.Name, waiting.Reason)
+		if waiting.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, waiting.Message)
+		}
+
+		switch waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+			return reasonImagePullError, msg, true
+		case "CrashLoopBackOff":
+			return reasonCrashLoopBackOff, msg, true
+		case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+			return reasonContainerError, msg, true
+		}
+	}
+
+	return "", "", false
+}
+
+func podCondition(pod *corev1.Pod, condType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == condType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
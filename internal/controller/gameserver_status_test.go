@@ -0,0 +1,131 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer status", func() {
+	newStatefulSet := func(replicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			Spec:   appsv1.StatefulSetSpec{Replicas: ptr.To(replicas)},
+			Status: appsv1.StatefulSetStatus{Replicas: replicas},
+		}
+	}
+
+	newPod := func(phase corev1.PodPhase, conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{Phase: phase, Conditions: conditions}}
+	}
+
+	waitingPod := func(reason string) *corev1.Pod {
+		pod := newPod(corev1.PodPending)
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "gameserver",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "boom"}},
+		}}
+		return pod
+	}
+
+	DescribeTable("deriving the phase and conditions",
+		func(obs *gameServerObservation, phase gamesv1alpha1.GameServerPhase, available metav1.ConditionStatus, reason string) {
+			state := deriveGameServerState(obs)
+			Expect(state.phase).To(Equal(phase))
+			Expect(state.available.status).To(Equal(available))
+			Expect(state.available.reason).To(Equal(reason))
+		},
+		Entry("without a StatefulSet",
+			&gameServerObservation{},
+			gamesv1alpha1.GameServerPhasePending, metav1.ConditionFalse, reasonStatefulSetMissing),
+		Entry("scaled to zero",
+			&gameServerObservation{statefulSet: newStatefulSet(0)},
+			gamesv1alpha1.GameServerPhaseStopped, metav1.ConditionFalse, reasonStopped),
+		Entry("without a pod",
+			&gameServerObservation{statefulSet: newStatefulSet(1)},
+			gamesv1alpha1.GameServerPhasePending, metav1.ConditionFalse, reasonPodMissing),
+		Entry("with an image pull error",
+			&gameServerObservation{statefulSet: newStatefulSet(1), pod: waitingPod("ImagePullBackOff")},
+			gamesv1alpha1.GameServerPhaseFailed, metav1.ConditionFalse, reasonImagePullError),
+		Entry("with a crash looping container",
+			&gameServerObservation{statefulSet: newStatefulSet(1), pod: waitingPod("CrashLoopBackOff")},
+			gamesv1alpha1.GameServerPhaseFailed, metav1.ConditionFalse, reasonCrashLoopBackOff),
+		Entry("with a pending PVC",
+			&gameServerObservation{
+				statefulSet: newStatefulSet(1),
+				pod:         newPod(corev1.PodPending),
+				pvc: &corev1.PersistentVolumeClaim{
+					Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+				},
+			},
+			gamesv1alpha1.GameServerPhasePending, metav1.ConditionFalse, reasonVolumeClaimPending),
+		Entry("with an unschedulable pod",
+			&gameServerObservation{
+				statefulSet: newStatefulSet(1),
+				pod: newPod(corev1.PodPending, corev1.PodCondition{
+					Type:   corev1.PodScheduled,
+					Status: corev1.ConditionFalse,
+				}),
+			},
+			gamesv1alpha1.GameServerPhasePending, metav1.ConditionFalse, reasonUnschedulable),
+		Entry("with a running pod that is not ready",
+			&gameServerObservation{statefulSet: newStatefulSet(1), pod: newPod(corev1.PodRunning)},
+			gamesv1alpha1.GameServerPhaseInstalling, metav1.ConditionFalse, reasonInstalling),
+		Entry("with a ready pod",
+			&gameServerObservation{
+				statefulSet: newStatefulSet(1),
+				pod: newPod(corev1.PodRunning, corev1.PodCondition{
+					Type:   corev1.PodReady,
+					Status: corev1.ConditionTrue,
+				}),
+			},
+			gamesv1alpha1.GameServerPhaseRunning, metav1.ConditionTrue, reasonReady),
+	)
+
+	It("marks a failing game server as degraded", func() {
+		state := deriveGameServerState(&gameServerObservation{
+			statefulSet: newStatefulSet(1),
+			pod:         waitingPod("ErrImagePull"),
+		})
+		Expect(state.degraded.status).To(Equal(metav1.ConditionTrue))
+		Expect(state.degraded.message).To(ContainSubstring("boom"))
+	})
+
+	It("records the observed generation on the status and every condition", func() {
+		status := &gamesv1alpha1.GameServerStatus{}
+		applyGameServerState(status, 3, deriveGameServerState(&gameServerObservation{}))
+
+		Expect(status.ObservedGeneration).To(Equal(int64(3)))
+		Expect(status.Phase).To(Equal(gamesv1alpha1.GameServerPhasePending))
+		Expect(status.Conditions).To(HaveLen(3))
+		for _, condType := range []string{
+			gamesv1alpha1.GameServerConditionAvailable,
+			gamesv1alpha1.GameServerConditionProgressing,
+			gamesv1alpha1.GameServerConditionDegraded,
+		} {
+			cond := meta.FindStatusCondition(status.Conditions, condType)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.ObservedGeneration).To(Equal(int64(3)))
+		}
+	})
+
+	It("reports replicas that are not honored until they are lowered again", func() {
+		gs := &gamesv1alpha1.GameServer{Spec: gamesv1alpha1.GameServerSpec{Replicas: 3}}
+		status := &gamesv1alpha1.GameServerStatus{}
+
+		applyReplicasIgnoredCondition(status, gs)
+		cond := meta.FindStatusCondition(status.Conditions, gamesv1alpha1.GameServerConditionReplicasIgnored)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(reasonMultipleReplicas))
+
+		gs.Spec.Replicas = 1
+		applyReplicasIgnoredCondition(status, gs)
+		Expect(meta.FindStatusCondition(status.Conditions, gamesv1alpha1.GameServerConditionReplicasIgnored)).To(BeNil())
+	})
+})
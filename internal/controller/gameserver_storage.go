@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for the StorageResized condition and volume expansion events.
+const (
+	reasonVolumeResized           = "Resized"
+	reasonVolumeResizing          = "Resizing"
+	reasonExpandingVolume         = "ExpandingVolume"
+	reasonFileSystemResizePending = "FileSystemResizePending"
+	reasonVolumeResizeFailed      = "ResizeFailed"
+	reasonExpansionNotSupported   = "ExpansionNotSupported"
+)
+
+// reconcileGameServerVolumeExpansion grows the data volume when spec.storage.size was increased.
+// The volume claim templates of a StatefulSet are immutable, so the existing claim is patched directly.
+func (r *GameServerReconciler) reconcileGameServerVolumeExpansion(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	if !specs.LinuxGSMStorageEnabled(gs) {
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: specs.GameServerDataVolumeClaimName(gs), Namespace: gs.Namespace}
+	found, err := r.getOptional(ctx, key, pvc)
+	if err != nil || !found {
+		return err
+	}
+
+	if !volumeExpansionRequested(gs, pvc) {
+		return nil
+	}
+
+	// The StorageResized condition explains why the volume is not expanded.
+	allowed, err := r.volumeExpansionAllowed(ctx, pvc)
+	if err != nil || !allowed {
+		return err
+	}
+
+	desired := specs.GameServerStorageSize(gs)
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+	if err := r.Patch(ctx, pvc, patch); err != nil {
+		return fmt.Errorf("failed to expand PersistentVolumeClaim %s: %w", pvc.Name, err)
+	}
+
+	r.recordEvent(gs, corev1.EventTypeNormal, reasonExpandingVolume, "Resize",
+		"Expanding PersistentVolumeClaim %s from %s to %s", pvc.Name, requested.String(), desired.String())
+	return nil
+}
+
+// volumeExpansionRequested reports whether spec.storage.size is larger than the size requested on the claim.
+func volumeExpansionRequested(gs *gamesv1alpha1.GameServer, pvc *corev1.PersistentVolumeClaim) bool {
+	desired := specs.GameServerStorageSize(gs)
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return desired.Cmp(requested) > 0
+}
+
+// volumeExpansionAllowed reports whether the StorageClass of the claim allows volume expansion.
+func (r *GameServerReconciler) volumeExpansionAllowed(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+
+	sc := &storagev1.StorageClass{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc)
+	if err != nil || !found {
+		return false, err
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// withVolumeClaimTemplateSize returns a copy of the GameServer requesting the storage size of the existing
+// StatefulSet, as its volume claim templates cannot be changed. An increased size is applied to the claim instead.
+func withVolumeClaimTemplateSize(gs *gamesv1alpha1.GameServer, sts *appsv1.StatefulSet) *gamesv1alpha1.GameServer {
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		size, ok := template.Spec.Resources.Requests[corev1.ResourceStorage]
+		if !ok {
+			continue
+		}
+		gs = gs.DeepCopy()
+		if gs.Spec.Storage == nil {
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{}
+		}
+		gs.Spec.Storage.Size = size.String()
+		return gs
+	}
+	return gs
+}
+
+// buildStorageStatus reports the requested and actual size of the data volume.
+func buildStorageStatus(pvc *corev1.PersistentVolumeClaim) *gamesv1alpha1.StorageStatus {
+	if pvc == nil {
+		return nil
+	}
+
+	status := &gamesv1alpha1.StorageStatus{}
+	if size, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		status.Size = &size
+	}
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		status.Capacity = &capacity
+	}
+	return status
+}
+
+// applyStorageResizedCondition reports whether the data volume has the requested size, and if not, why.
+func applyStorageResizedCondition(status *gamesv1alpha1.GameServerStatus, gs *gamesv1alpha1.GameServer, obs *gameServerObservation) {
+	pvc := obs.pvc
+	if pvc == nil {
+		meta.RemoveStatusCondition(&status.Conditions, gamesv1alpha1.GameServerConditionStorageResized)
+		return
+	}
+
+	state := storageResizeState(specs.GameServerStorageSize(gs), pvc, obs.volumeExpansionAllowed)
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               gamesv1alpha1.GameServerConditionStorageResized,
+		Status:             state.status,
+		Reason:             state.reason,
+		Message:            state.message,
+		ObservedGeneration: gs.Generation,
+	})
+}
+
+func storageResizeState(desired resource.Quantity, pvc *corev1.PersistentVolumeClaim, expansionAllowed bool) conditionState {
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if desired.Cmp(requested) > 0 && !expansionAllowed {
+		return conditionState{
+			status: metav1.ConditionFalse,
+			reason: reasonExpansionNotSupported,
+			message: fmt.Sprintf("The StorageClass of PersistentVolumeClaim %s does not allow volume expansion, "+
+				"the volume stays at %s instead of %s", pvc.Name, requested.String(), desired.String()),
+		}
+	}
+
+	for _, c := range pvc.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError:
+			return conditionState{status: metav1.ConditionFalse, reason: reasonVolumeResizeFailed, message: c.Message}
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return conditionState{
+				status: metav1.ConditionFalse,
+				reason: reasonFileSystemResizePending,
+				message: "The volume was expanded, the file system is resized once the game server pod " +
+					"(re)starts with the volume mounted",
+			}
+		case corev1.PersistentVolumeClaimResizing:
+			return conditionState{status: metav1.ConditionFalse, reason: reasonVolumeResizing, message: "Expanding the volume"}
+		}
+	}
+
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if desired.Cmp(requested) > 0 || (ok && capacity.Cmp(requested) < 0) {
+		return conditionState{status: metav1.ConditionFalse, reason: reasonVolumeResizing, message: "Expanding the volume"}
+	}
+
+	return conditionState{
+		status:  metav1.ConditionTrue,
+		reason:  reasonVolumeResized,
+		message: fmt.Sprintf("The volume has the requested size of %s", requested.String()),
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer volume expansion", func() {
+	newPVC := func(requested, capacity string, conditions ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-example-0"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(requested)},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		}
+		for _, c := range conditions {
+			pvc.Status.Conditions = append(pvc.Status.Conditions,
+				corev1.PersistentVolumeClaimCondition{Type: c, Status: corev1.ConditionTrue})
+		}
+		return pvc
+	}
+
+	It("reports the progress of a resize", func() {
+		desired := resource.MustParse("20Gi")
+
+		Expect(storageResizeState(desired, newPVC("20Gi", "20Gi"), true).reason).To(Equal(reasonVolumeResized))
+		Expect(storageResizeState(desired, newPVC("10Gi", "10Gi"), false).reason).To(Equal(reasonExpansionNotSupported))
+		Expect(storageResizeState(desired, newPVC("20Gi", "10Gi"), true).reason).To(Equal(reasonVolumeResizing))
+		Expect(storageResizeState(desired, newPVC("20Gi", "10Gi", corev1.PersistentVolumeClaimFileSystemResizePending), true).
+			reason).To(Equal(reasonFileSystemResizePending))
+		Expect(storageResizeState(desired, newPVC("20Gi", "10Gi", corev1.PersistentVolumeClaimControllerResizeError), true).
+			reason).To(Equal(reasonVolumeResizeFailed))
+	})
+
+	Context("When spec.storage.size is increased", func() {
+		const name = "expand-test"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+		var reconciler *GameServerReconciler
+
+		createPVC := func(storageClassName string) {
+			GinkgoHelper()
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-" + name + "-0", Namespace: testNamespace},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: &storageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+			// Only bound claims can be expanded.
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+		}
+
+		growTo := func(size string) {
+			GinkgoHelper()
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			gs.Spec.Storage.Size = size
+			Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			reconciler = &GameServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: &events.FakeRecorder{}}
+
+			for _, sc := range []struct {
+				name       string
+				expandable bool
+			}{{"expandable", true}, {"fixed", false}} {
+				storageClass := &storagev1.StorageClass{
+					ObjectMeta:           metav1.ObjectMeta{Name: sc.name},
+					Provisioner:          "example.com/csi",
+					AllowVolumeExpansion: &sc.expandable,
+				}
+				Expect(ctrlclient.IgnoreAlreadyExists(k8sClient.Create(ctx, storageClass))).To(Succeed())
+			}
+
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = name
+				gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Size: "10Gi"}
+			})
+			Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			pvc := &corev1.PersistentVolumeClaim{}
+			if k8sClient.Get(ctx, types.NamespacedName{Name: "data-" + name + "-0", Namespace: testNamespace}, pvc) == nil {
+				// Without a controller manager the protection finalizer is never removed.
+				pvc.Finalizers = nil
+				Expect(k8sClient.Update(ctx, pvc)).To(Succeed())
+				Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, pvc))).To(Succeed())
+			}
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			}))).To(Succeed())
+		})
+
+		It("expands the claim and keeps the volume claim template", func() {
+			createPVC("expandable")
+			growTo("20Gi")
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "data-" + name + "-0", Namespace: testNamespace}, pvc)).
+				To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("20Gi"))
+
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, key, sts)).To(Succeed())
+			Expect(sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(gs.Status.Storage.Size.String()).To(Equal("20Gi"))
+			Expect(gs.Status.Storage.Capacity.String()).To(Equal("10Gi"))
+			Expect(meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionStorageResized).Reason).
+				To(Equal(reasonVolumeResizing))
+		})
+
+		It("reports when the StorageClass does not allow expansion", func() {
+			createPVC("fixed")
+			growTo("20Gi")
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "data-" + name + "-0", Namespace: testNamespace}, pvc)).
+				To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			cond := meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionStorageResized)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(reasonExpansionNotSupported))
+		})
+	})
+})
@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for update events.
+const (
+	reasonUpdateAvailable = "UpdateAvailable"
+	reasonUpdating        = "Updating"
+	reasonUpdated         = "Updated"
+	reasonUpdateFailed    = "UpdateFailed"
+)
+
+const (
+	// updateTimeout bounds a check or update, which may download a new build of the game.
+	updateTimeout = 30 * time.Minute
+
+	// updateWhenEmptyRetryInterval is how often an available update is retried while players are connected.
+	updateWhenEmptyRetryInterval = 5 * time.Minute
+)
+
+// reconcileGameServerUpdates checks for updates of the running game server on its schedule and installs them
+// according to the update strategy. It returns when the next check is due, or zero when no updates are scheduled.
+//
+// The LinuxGSM commands run in the game server container, in the background, as they may download a new build of the
+// game. The check is tracked in status.updates while it runs and its outcome is recorded once it is done. LinuxGSM
+// stops the game, installs the new build and starts the game again, so the game server is only restarted when a new
+// build was installed.
+func (r *GameServerReconciler) reconcileGameServerUpdates(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (time.Duration, error) {
+	status := &gamesv1alpha1.UpdateStatus{}
+	if gs.Status.Updates != nil {
+		status = gs.Status.Updates.DeepCopy()
+	}
+
+	// A check that finished is recorded even when updates were suspended while it ran.
+	result, found, done := r.updates.result(gs.UID)
+	switch {
+	case found && !done:
+		return backgroundPollInterval, nil
+	case done:
+		if err := r.patchUpdateStatus(ctx, gs, result); err != nil {
+			return 0, err
+		}
+		r.updates.forget(gs.UID)
+	case status.Running:
+		// The check is not known to this operator, so it was interrupted, e.g. by a restart of the operator.
+		// It is not retried before the next scheduled check, as the update may have restarted the game server.
+		status.Running = false
+		status.Message = "The check was interrupted before it finished"
+		if err := r.patchUpdateStatus(ctx, gs, status); err != nil {
+			return 0, err
+		}
+	}
+
+	updates := gs.Spec.Updates
+	if updates == nil || updates.Suspend || r.Executor == nil {
+		return 0, nil
+	}
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return 0, nil
+	}
+
+	schedule, err := parseCronSchedule(updates.Schedule, updates.TimeZone)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if next := nextUpdateCheckTime(schedule, gs); now.Before(next) {
+		return next.Sub(now), nil
+	}
+
+	// A check that is due while the game server is stopped or starting runs once it is ready,
+	// the pod becoming ready triggers a reconcile.
+	pod := &corev1.Pod{}
+	found, err = r.getOptional(ctx, types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}, pod)
+	if err != nil || !found || !podReady(pod) {
+		return 0, err
+	}
+
+	// LinuxGSM commands of a GameServerCommand, e.g. an update or a restart, would interfere with the check.
+	command, err := r.runningCommand(ctx, gs)
+	if err != nil {
+		return 0, err
+	}
+	if command != "" {
+		logf.FromContext(ctx).Info("Waiting for a GameServerCommand to finish before checking for updates",
+			"gameServerCommand", command)
+		return commandRequeueInterval, nil
+	}
+
+	status = &gamesv1alpha1.UpdateStatus{}
+	if gs.Status.Updates != nil {
+		status = gs.Status.Updates.DeepCopy()
+	}
+	status.LastCheckTime = &metav1.Time{Time: now}
+	status.Running = true
+	status.Message = "Checking for updates"
+	if err := r.patchUpdateStatus(ctx, gs, status); err != nil {
+		return 0, err
+	}
+
+	checked, task := gs.DeepCopy(), status.DeepCopy()
+	r.updates.start(ctx, gs.UID, func(ctx context.Context) *gamesv1alpha1.UpdateStatus {
+		r.runGameServerUpdate(ctx, checked, game, task)
+		task.Running = false
+		return task
+	})
+	return backgroundPollInterval, nil
+}
+
+// runningCommand returns the name of a GameServerCommand that runs in the game server, or an empty string when none
+// does.
+func (r *GameServerReconciler) runningCommand(ctx context.Context, gs *gamesv1alpha1.GameServer) (string, error) {
+	commands := &gamesv1alpha1.GameServerCommandList{}
+	if err := r.List(ctx, commands, client.InNamespace(gs.Namespace)); err != nil {
+		return "", fmt.Errorf("failed to list GameServerCommands: %w", err)
+	}
+	for i := range commands.Items {
+		cmd := &commands.Items[i]
+		if cmd.Spec.GameServerRef.Name == gs.Name && cmd.Status.Phase == gamesv1alpha1.GameServerCommandPhaseRunning {
+			return cmd.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// patchUpdateStatus sets status.updates of the GameServer.
+func (r *GameServerReconciler) patchUpdateStatus(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	status *gamesv1alpha1.UpdateStatus,
+) error {
+	patch := client.MergeFrom(gs.DeepCopy())
+	gs.Status.Updates = status
+	if err := r.Status().Patch(ctx, gs, patch); err != nil {
+		return fmt.Errorf("failed to update GameServer update status: %w", err)
+	}
+	return nil
+}
+
+// runGameServerUpdate checks for an update and installs it when the update strategy allows to,
+// recording the outcome in the status. Failures are reported in the status and as events rather than
+// returned, so a failing update is retried on the schedule instead of right away.
+func (r *GameServerReconciler) runGameServerUpdate(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	game linuxgsm.Game,
+	status *gamesv1alpha1.UpdateStatus,
+) {
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	output, err := r.execLinuxGSM(ctx, gs, game, linuxgsm.CommandCheckUpdate)
+	if err != nil {
+		status.Message = fmt.Sprintf("Checking for updates failed: %v", err)
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonUpdateFailed, "Update", "%s", status.Message)
+		return
+	}
+
+	// The event is only recorded when an update becomes available, not on every check.
+	announced := status.UpdateAvailable
+	check := linuxgsm.ParseUpdateOutput(output)
+	recordUpdateCheck(status, check)
+	if !check.UpdateAvailable {
+		status.Message = "No update available"
+		return
+	}
+	if !announced {
+		r.recordEvent(gs, corev1.EventTypeNormal, reasonUpdateAvailable, "Update",
+			"Update available: %s", describeBuild(check.RemoteBuild))
+	}
+
+	switch updateStrategy(gs) {
+	case gamesv1alpha1.UpdateStrategyCheckOnly:
+		status.Message = fmt.Sprintf("Update available: %s", describeBuild(check.RemoteBuild))
+		return
+	case gamesv1alpha1.UpdateStrategyUpdateWhenEmpty:
+		output, err := r.execLinuxGSM(ctx, gs, game, linuxgsm.CommandDetails)
+		if err != nil {
+			status.Message = fmt.Sprintf("Looking up the connected players failed: %v", err)
+			return
+		}
+		players, known := linuxgsm.ParsePlayers(output)
+		switch {
+		case !known:
+			status.Message = "Update available, but LinuxGSM does not report the connected players of this game"
+			return
+		case players > 0:
+			status.Message = fmt.Sprintf("Update available, waiting for %d connected players to leave", players)
+			return
+		}
+	case gamesv1alpha1.UpdateStrategyForce:
+		// LinuxGSM restarts the game server to install the build, disconnecting the connected players.
+		r.warnPlayers(ctx, gs, "restarts")
+	}
+
+	r.recordEvent(gs, corev1.EventTypeNormal, reasonUpdating, "Update", "Updating the game server")
+	output, err = r.execLinuxGSM(ctx, gs, game, linuxgsm.CommandUpdate)
+	if err != nil {
+		status.Message = fmt.Sprintf("Updating failed: %v", err)
+		r.recordEvent(gs, corev1.EventTypeWarning, reasonUpdateFailed, "Update", "%s", status.Message)
+		return
+	}
+
+	check = linuxgsm.ParseUpdateOutput(output)
+	if !check.UpdateAvailable {
+		recordUpdateCheck(status, check)
+		status.Message = "No update available"
+		return
+	}
+
+	if check.RemoteBuild != "" {
+		status.InstalledBuild = check.RemoteBuild
+	}
+	status.AvailableBuild = status.InstalledBuild
+	status.UpdateAvailable = false
+	status.LastUpdateTime = status.LastCheckTime.DeepCopy()
+	status.Message = fmt.Sprintf("Installed %s", describeBuild(check.RemoteBuild))
+	r.recordEvent(gs, corev1.EventTypeNormal, reasonUpdated, "Update", "%s", status.Message)
+}
+
+// execLinuxGSM runs a LinuxGSM command in the game server container and returns its output.
+// A non-zero exit code is returned as an error with the last line of output, which holds the LinuxGSM error.
+func (r *GameServerReconciler) execLinuxGSM(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	game linuxgsm.Game,
+	command string,
+) (string, error) {
+	result, err := r.Executor.Exec(ctx, gs.Namespace, specs.GameServerPodName(gs), specs.GameServerContainerName,
+		game.Command(command))
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		output := linuxgsm.CleanOutput(result.Stdout + result.Stderr)
+		return "", fmt.Errorf("%s exited with code %d: %s", command, result.ExitCode, lastLine(output))
+	}
+	return result.Stdout, nil
+}
+
+// nextUpdateCheckTime returns when updates are checked for next, based on the last check or, without one,
+// the creation of the GameServer. An available update waiting for players to leave is retried sooner.
+func nextUpdateCheckTime(schedule cron.Schedule, gs *gamesv1alpha1.GameServer) time.Time {
+	last := gs.CreationTimestamp.Time
+	status := gs.Status.Updates
+	if status != nil && status.LastCheckTime != nil {
+		last = status.LastCheckTime.Time
+	}
+
+	next := schedule.Next(last)
+	if status != nil && status.UpdateAvailable && updateStrategy(gs) == gamesv1alpha1.UpdateStrategyUpdateWhenEmpty {
+		if retry := last.Add(updateWhenEmptyRetryInterval); retry.Before(next) {
+			next = retry
+		}
+	}
+	return next
+}
+
+// recordUpdateCheck records the builds reported by LinuxGSM, keeping the known ones when a game does not report them.
+func recordUpdateCheck(status *gamesv1alpha1.UpdateStatus, check linuxgsm.UpdateCheck) {
+	if check.LocalBuild != "" {
+		status.InstalledBuild = check.LocalBuild
+	}
+	if check.RemoteBuild != "" {
+		status.AvailableBuild = check.RemoteBuild
+	}
+	status.UpdateAvailable = check.UpdateAvailable
+}
+
+func updateStrategy(gs *gamesv1alpha1.GameServer) gamesv1alpha1.UpdateStrategy {
+	if gs.Spec.Updates == nil || gs.Spec.Updates.Strategy == "" {
+		return gamesv1alpha1.UpdateStrategyCheckOnly
+	}
+	return gs.Spec.Updates.Strategy
+}
+
+func describeBuild(build string) string {
+	if build == "" {
+		return "the latest build"
+	}
+	return fmt.Sprintf("build %s", build)
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func podReady(pod *corev1.Pod) bool {
+	cond := podCondition(pod, corev1.PodReady)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
+// soonestRequeue returns the shortest of the given requeue intervals, ignoring zero intervals.
+func soonestRequeue(intervals ...time.Duration) time.Duration {
+	var soonest time.Duration
+	for _, interval := range intervals {
+		if interval > 0 && (soonest == 0 || interval < soonest) {
+			soonest = interval
+		}
+	}
+	return soonest
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/podexec"
+)
+
+// fakeExecutor answers LinuxGSM commands with canned output and exit codes and records the commands it ran.
+type fakeExecutor struct {
+	outputs   map[string]string
+	exitCodes map[string]int
+	commands  []string
+}
+
+func (e *fakeExecutor) Exec(_ context.Context, _, _, _ string, command []string) (podexec.Result, error) {
+	cmd := strings.Join(command, " ")
+	e.commands = append(e.commands, cmd)
+	return podexec.Result{Stdout: e.outputs[cmd], ExitCode: e.exitCodes[cmd]}, nil
+}
+
+var _ = Describe("GameServer updates", func() {
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	It("schedules the next check after the last one", func() {
+		schedule, err := parseCronSchedule("0 5 * * *", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.CreationTimestamp = metav1.NewTime(created)
+			gs.Spec.Updates = &gamesv1alpha1.UpdatesSpec{Schedule: "0 5 * * *"}
+		})
+		Expect(nextUpdateCheckTime(schedule, gs)).To(Equal(time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC)))
+
+		gs.Status.Updates = &gamesv1alpha1.UpdateStatus{
+			LastCheckTime:   &metav1.Time{Time: time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC)},
+			UpdateAvailable: true,
+		}
+		Expect(nextUpdateCheckTime(schedule, gs)).To(Equal(time.Date(2026, 1, 3, 5, 0, 0, 0, time.UTC)))
+
+		By("retrying an available update sooner while waiting for players to leave")
+		gs.Spec.Updates.Strategy = gamesv1alpha1.UpdateStrategyUpdateWhenEmpty
+		Expect(nextUpdateCheckTime(schedule, gs)).To(Equal(time.Date(2026, 1, 2, 5, 5, 0, 0, time.UTC)))
+	})
+
+	It("requeues at the soonest interval", func() {
+		Expect(soonestRequeue(0, 0)).To(BeZero())
+		Expect(soonestRequeue(time.Hour, 0, time.Minute)).To(Equal(time.Minute))
+	})
+
+	Context("When an update check is due", func() {
+		const name = "update-test"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: name, Namespace: testNamespace}
+
+		var (
+			reconciler *GameServerReconciler
+			executor   *fakeExecutor
+		)
+
+		checkOutput := "Update available\n* Local build: 1234\n* Remote build: 1240\n"
+
+		// createGameServer creates a ready game server with a check that is overdue.
+		createGameServer := func(strategy gamesv1alpha1.UpdateStrategy) *gamesv1alpha1.GameServer {
+			GinkgoHelper()
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = name
+				gs.Spec.GameName = "vh"
+				gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+				gs.Spec.Updates = &gamesv1alpha1.UpdatesSpec{Schedule: "0 5 * * *", Strategy: strategy}
+			})
+			Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+			gs.Status.Updates = &gamesv1alpha1.UpdateStatus{LastCheckTime: &metav1.Time{Time: time.Now().Add(-48 * time.Hour)}}
+			Expect(k8sClient.Status().Update(ctx, gs)).To(Succeed())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "gameserver",
+					Image: "gameservermanagers/gameserver:vh",
+				}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			return gs
+		}
+
+		// runUpdate creates a ready game server with a check that is overdue and reconciles it until the check finished.
+		runUpdate := func(strategy gamesv1alpha1.UpdateStrategy) *gamesv1alpha1.GameServer {
+			GinkgoHelper()
+			gs := createGameServer(strategy)
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(backgroundPollInterval))
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(gs.Status.Updates.Running).To(BeTrue())
+
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+				g.Expect(gs.Status.Updates.Running).To(BeFalse())
+			}).Should(Succeed())
+			Expect(gs.Status.Updates.LastCheckTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			return gs
+		}
+
+		BeforeEach(func() {
+			executor = &fakeExecutor{outputs: map[string]string{
+				"/app/vhserver check-update": checkOutput,
+				"/app/vhserver update":       checkOutput + "Update complete\n",
+				"/app/vhserver details":      "Players:\t0 / 10\n",
+			}}
+			reconciler = &GameServerReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &events.FakeRecorder{},
+				Executor: executor,
+			}
+		})
+
+		AfterEach(func() {
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+			}))).To(Succeed())
+		})
+
+		It("only reports the available update with the CheckOnly strategy", func() {
+			gs := runUpdate(gamesv1alpha1.UpdateStrategyCheckOnly)
+
+			Expect(executor.commands).To(Equal([]string{"/app/vhserver check-update"}))
+			Expect(gs.Status.Updates.InstalledBuild).To(Equal("1234"))
+			Expect(gs.Status.Updates.AvailableBuild).To(Equal("1240"))
+			Expect(gs.Status.Updates.UpdateAvailable).To(BeTrue())
+			Expect(gs.Status.Updates.LastUpdateTime).To(BeNil())
+		})
+
+		It("installs the update once the game server is empty", func() {
+			gs := runUpdate(gamesv1alpha1.UpdateStrategyUpdateWhenEmpty)
+
+			Expect(executor.commands).To(Equal([]string{
+				"/app/vhserver check-update",
+				"/app/vhserver details",
+				"/app/vhserver update",
+			}))
+			Expect(gs.Status.Updates.InstalledBuild).To(Equal("1240"))
+			Expect(gs.Status.Updates.UpdateAvailable).To(BeFalse())
+			Expect(gs.Status.Updates.LastUpdateTime).NotTo(BeNil())
+		})
+
+		It("waits for connected players to leave", func() {
+			executor.outputs["/app/vhserver details"] = "Players:\t2 / 10\n"
+			gs := runUpdate(gamesv1alpha1.UpdateStrategyUpdateWhenEmpty)
+
+			Expect(executor.commands).NotTo(ContainElement("/app/vhserver update"))
+			Expect(gs.Status.Updates.UpdateAvailable).To(BeTrue())
+			Expect(gs.Status.Updates.Message).To(ContainSubstring("waiting for 2 connected players"))
+		})
+
+		It("waits for a running GameServerCommand before checking", func() {
+			gs := createGameServer(gamesv1alpha1.UpdateStrategyForce)
+			cmd := &gamesv1alpha1.GameServerCommand{
+				ObjectMeta: metav1.ObjectMeta{Name: name + "-restart", Namespace: testNamespace},
+				Spec: gamesv1alpha1.GameServerCommandSpec{
+					GameServerRef: corev1.LocalObjectReference{Name: name},
+					Command:       gamesv1alpha1.GameServerCommandRestart,
+				},
+			}
+			Expect(k8sClient.Create(ctx, cmd)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, cmd))).To(Succeed())
+			})
+			cmd.Status.Phase = gamesv1alpha1.GameServerCommandPhaseRunning
+			Expect(k8sClient.Status().Update(ctx, cmd)).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(commandRequeueInterval))
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(gs.Status.Updates.Running).To(BeFalse())
+			Expect(executor.commands).To(BeEmpty())
+
+			By("checking once the command finished")
+			cmd.Status.Phase = gamesv1alpha1.GameServerCommandPhaseSucceeded
+			Expect(k8sClient.Status().Update(ctx, cmd)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(gs.Status.Updates.Running).To(BeTrue())
+			Eventually(func(g Gomega) {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+				g.Expect(gs.Status.Updates.Running).To(BeFalse())
+			}).Should(Succeed())
+		})
+
+		It("reports a check that was interrupted while it ran", func() {
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = name
+				gs.Spec.GameName = "vh"
+				gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+				gs.Spec.Updates = &gamesv1alpha1.UpdatesSpec{Schedule: "0 5 * * *"}
+			})
+			Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+			gs.Status.Updates = &gamesv1alpha1.UpdateStatus{LastCheckTime: &metav1.Time{Time: time.Now()}, Running: true}
+			Expect(k8sClient.Status().Update(ctx, gs)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+			Expect(gs.Status.Updates.Running).To(BeFalse())
+			Expect(gs.Status.Updates.Message).To(ContainSubstring("interrupted"))
+			Expect(executor.commands).To(BeEmpty())
+		})
+	})
+})
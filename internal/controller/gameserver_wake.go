@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// reconcileGameServerWakeProxy routes the Service of a game server with spec.idle.wakeOnConnect to the wake proxy
+// while the game does not accept players, so the first player connecting to the idle game server starts it.
+//
+// The proxy is added to the endpoints of the Service with an EndpointSlice of its own. The Service keeps selecting
+// the game server pod, so players are routed to the game as soon as it is ready, and to the proxy until then.
+func (r *GameServerReconciler) reconcileGameServerWakeProxy(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	if r.wakeProxy == nil {
+		return nil
+	}
+
+	key := client.ObjectKeyFromObject(gs)
+	routed, err := r.wakeProxyRouted(ctx, gs)
+	if err != nil {
+		return err
+	}
+	if !routed {
+		r.wakeProxy.Close(key)
+		return r.deleteWakeEndpointSlice(ctx, gs)
+	}
+
+	ports, err := r.wakeProxy.Listen(key, specs.WakePorts(gs))
+	if err != nil {
+		return err
+	}
+	sliceApply := specs.BuildWakeEndpointSlice(gs, r.wakeProxy.Address(), r.wakeProxy.NodeName(), ports)
+	sliceApply.WithOwnerReferences(gameServerOwnerReference(gs))
+
+	return r.Apply(ctx, sliceApply,
+		client.FieldOwner(fieldManagerGameServer),
+		client.ForceOwnership,
+	)
+}
+
+// wakeProxyRouted reports whether players are routed to the wake proxy: the game server wakes on connect, is not
+// stopped by its spec or schedule and its pod is not ready.
+func (r *GameServerReconciler) wakeProxyRouted(ctx context.Context, gs *gamesv1alpha1.GameServer) (bool, error) {
+	if !specs.GameServerWakeOnConnect(gs) || specs.GameServerScheduledState(gs) == gamesv1alpha1.GameServerStateStopped {
+		return false, nil
+	}
+
+	pod := &corev1.Pod{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}, pod)
+	if err != nil {
+		return false, err
+	}
+	return !found || !podReady(pod), nil
+}
+
+func (r *GameServerReconciler) deleteWakeEndpointSlice(ctx context.Context, gs *gamesv1alpha1.GameServer) error {
+	slice := &discoveryv1.EndpointSlice{}
+	found, err := r.getOptional(ctx, types.NamespacedName{Name: specs.WakeEndpointSliceName(gs), Namespace: gs.Namespace},
+		slice)
+	if err != nil || !found {
+		return err
+	}
+	if err := client.IgnoreNotFound(r.Delete(ctx, slice)); err != nil {
+		return fmt.Errorf("failed to delete wake proxy EndpointSlice: %w", err)
+	}
+	return nil
+}
+
+// requestWake requests the idle game server to start, called by the wake proxy when a player connects.
+func (r *GameServerReconciler) requestWake(ctx context.Context, key types.NamespacedName) error {
+	gs := &gamesv1alpha1.GameServer{}
+	if err := r.Get(ctx, key, gs); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(gs.DeepCopy())
+	if gs.Annotations == nil {
+		gs.Annotations = map[string]string{}
+	}
+	gs.Annotations[gamesv1alpha1.AnnotationWakeRequestedAt] = time.Now().UTC().Format(time.RFC3339)
+	return r.Patch(ctx, gs, patch)
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/wake"
+)
+
+var _ = Describe("GameServer wake on connect", func() {
+	const name = "wake-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	sliceKey := types.NamespacedName{Name: name + "-wake", Namespace: testNamespace}
+
+	var reconciler *GameServerReconciler
+
+	reconcileGameServer := func() {
+		GinkgoHelper()
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		reconciler = &GameServerReconciler{
+			Client:   k8sClient,
+			Scheme:   k8sClient.Scheme(),
+			Recorder: &events.FakeRecorder{},
+		}
+		reconciler.wakeProxy = wake.NewProxy("10.0.0.1", "node-a", reconciler.requestWake)
+
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Name = name
+			gs.Spec.GameName = "mc"
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+			gs.Spec.Service = &gamesv1alpha1.ServiceSpec{
+				Type: corev1.ServiceTypeClusterIP,
+				Ports: []gamesv1alpha1.ServicePort{
+					{Name: "game", Port: 25565, Protocol: corev1.ProtocolTCP},
+					{Name: "query", Port: 25565, Protocol: corev1.ProtocolUDP},
+				},
+			}
+			gs.Spec.Idle = &gamesv1alpha1.IdleSpec{WakeOnConnect: true}
+		})
+		Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+		}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+		reconcileGameServer()
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-wake", Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+		Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		}))).To(Succeed())
+	})
+
+	It("routes the Service to the wake proxy until the game server is ready", func() {
+		reconcileGameServer()
+
+		slice := &discoveryv1.EndpointSlice{}
+		Expect(k8sClient.Get(ctx, sliceKey, slice)).To(Succeed())
+		Expect(slice.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, name))
+		Expect(slice.AddressType).To(Equal(discoveryv1.AddressTypeIPv4))
+		Expect(slice.Endpoints).To(HaveLen(1))
+		Expect(slice.Endpoints[0].Addresses).To(Equal([]string{"10.0.0.1"}))
+		Expect(slice.Endpoints[0].NodeName).To(HaveValue(Equal("node-a")))
+		Expect(slice.Endpoints[0].Conditions.Ready).To(HaveValue(BeTrue()))
+		Expect(slice.Ports).To(HaveLen(2))
+		Expect(slice.Ports[0].Name).To(HaveValue(Equal("game")))
+		Expect(slice.Ports[0].Protocol).To(HaveValue(Equal(corev1.ProtocolTCP)))
+		Expect(slice.Ports[1].Name).To(HaveValue(Equal("query")))
+		Expect(slice.Ports[1].Protocol).To(HaveValue(Equal(corev1.ProtocolUDP)))
+
+		By("removing the route once the game server is ready")
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: testNamespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "gameserver",
+				Image: "gameservermanagers/gameserver:mc",
+			}}},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+		reconcileGameServer()
+		Expect(k8sClient.Get(ctx, sliceKey, slice)).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("does not route game servers stopped by their spec", func() {
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		gs.Spec.State = gamesv1alpha1.GameServerStateStopped
+		Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+
+		reconcileGameServer()
+		Expect(k8sClient.Get(ctx, sliceKey, &discoveryv1.EndpointSlice{})).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("requests a wake up with the annotation", func() {
+		Expect(reconciler.requestWake(ctx, key)).To(Succeed())
+
+		gs := &gamesv1alpha1.GameServer{}
+		Expect(k8sClient.Get(ctx, key, gs)).To(Succeed())
+		requestedAt, ok := wakeRequestedAt(gs)
+		Expect(ok).To(BeTrue())
+		Expect(requestedAt).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+})
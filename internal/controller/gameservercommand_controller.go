@@ -0,0 +1,480 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/podexec"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for the GameServerCommand status conditions and events.
+const (
+	reasonCommandQueued      = "Queued"
+	reasonWaitingForPod      = "WaitingForGameServer"
+	reasonGameServerStopped  = "GameServerStopped"
+	reasonUnknownGame        = "UnknownGame"
+	reasonMinecraftInstalled = "MinecraftServerInstalled"
+	reasonCommandRunning     = "Running"
+	reasonCommandSucceeded   = "Succeeded"
+	reasonCommandFailed      = "CommandFailed"
+	reasonCommandTimeout     = "Timeout"
+	reasonCommandExecFailed  = "ExecFailed"
+	reasonCommandInterrupted = "Interrupted"
+)
+
+const (
+	defaultCommandTimeout = 300 * time.Second
+
+	// commandTimeoutKillAfter is how long a timed out command gets to stop before it is killed.
+	commandTimeoutKillAfter = 10 * time.Second
+
+	// commandTimeoutExitCode is the exit code of timeout when the command timed out.
+	commandTimeoutExitCode = 124
+
+	// commandRequeueInterval is how often a pending command is rechecked while waiting for the game server
+	// or earlier commands.
+	commandRequeueInterval = 5 * time.Second
+
+	// maxCommandOutputBytes bounds the output kept in the status, which is stored in etcd.
+	maxCommandOutputBytes = 16 * 1024
+
+	maxConcurrentGameServerCommandReconciles = 4
+)
+
+// commandOutput is what a command wrote and how it exited.
+type commandOutput struct {
+	stdout   string
+	stderr   string
+	exitCode *int32
+}
+
+// commandResult is the outcome of a command that finished, which is recorded in its status.
+type commandResult struct {
+	phase   gamesv1alpha1.GameServerCommandPhase
+	reason  string
+	message string
+	output  *commandOutput
+}
+
+// GameServerCommandReconciler reconciles a GameServerCommand object
+type GameServerCommandReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+
+	// Executor runs the commands in the game server container.
+	Executor podexec.Executor
+
+	// RCONDialer connects to the remote consoles of game servers, to warn players before a restart.
+	RCONDialer rcon.Dialer
+
+	// commands runs the commands in the background, as they run for up to their timeout.
+	commands backgroundTasks[commandResult]
+
+	// runningUIDs holds the UIDs of the commands running in the background by name, as a deleted command is only
+	// known by its name when it is reconciled.
+	runningMu   sync.Mutex
+	runningUIDs map[types.NamespacedName]types.UID
+}
+
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservercommands,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservercommands/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservercommands/finalizers,verbs=update
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=get;create
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+// Reconcile runs a GameServerCommand once in the game server container. Commands of the same game server
+// run one at a time, in the order they were created.
+func (r *GameServerCommandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cmd := &gamesv1alpha1.GameServerCommand{}
+	if err := r.Get(ctx, req.NamespacedName, cmd); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forgetCommand(req.NamespacedName, "")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// A command that was deleted and created again with the same name leaves the task of the old one behind.
+	r.forgetCommand(req.NamespacedName, cmd.UID)
+
+	switch cmd.Status.Phase {
+	case gamesv1alpha1.GameServerCommandPhaseSucceeded, gamesv1alpha1.GameServerCommandPhaseFailed:
+		return ctrl.Result{}, nil
+	case gamesv1alpha1.GameServerCommandPhaseRunning:
+		result, found, done := r.commands.result(cmd.UID)
+		switch {
+		case found && !done:
+			return ctrl.Result{RequeueAfter: backgroundPollInterval}, nil
+		case done:
+			if err := r.finishCommand(ctx, cmd, result); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.forgetCommand(req.NamespacedName, "")
+			return ctrl.Result{}, nil
+		}
+		// A command that is Running but not known to this operator was interrupted, e.g. by a restart of the
+		// operator. It is not run again, as commands are not necessarily idempotent.
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonCommandInterrupted,
+			"The command was interrupted before it finished, its outcome is unknown", nil)
+	}
+
+	gs := &gamesv1alpha1.GameServer{}
+	gsKey := types.NamespacedName{Name: cmd.Spec.GameServerRef.Name, Namespace: cmd.Namespace}
+	if err := r.Get(ctx, gsKey, gs); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get GameServer: %w", err)
+		}
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonGameServerMissing,
+			fmt.Sprintf("GameServer %s does not exist", gsKey.Name), nil)
+	}
+	if !gs.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonGameServerMissing,
+			fmt.Sprintf("GameServer %s is being deleted", gs.Name), nil)
+	}
+	if specs.GameServerStopped(gs) {
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonGameServerStopped,
+			fmt.Sprintf("GameServer %s is stopped, start it to run commands", gs.Name), nil)
+	}
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonUnknownGame,
+			fmt.Sprintf("%s is not a game supported by LinuxGSM", gs.Spec.GameName), nil)
+	}
+	if commandReplacesServer(cmd) && specs.GameServerMinecraftServer(gs) != nil {
+		return ctrl.Result{}, r.failCommand(ctx, cmd, reasonMinecraftInstalled,
+			fmt.Sprintf("The Minecraft server of GameServer %s is installed by the operator, %s would replace it with "+
+				"the vanilla server of LinuxGSM, change spec.gameConfigs.minecraft instead", gs.Name, cmd.Spec.Command),
+			nil)
+	}
+
+	// Two commands fighting over the same game server, e.g. a restart during an update, could leave it
+	// in an undefined state, so later commands wait for earlier ones and for the scheduled update check.
+	commands := &gamesv1alpha1.GameServerCommandList{}
+	if err := r.List(ctx, commands, client.InNamespace(cmd.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list GameServerCommands: %w", err)
+	}
+	if other := precedingCommand(cmd, commands.Items); other != nil {
+		err := r.setCommandState(ctx, cmd, gamesv1alpha1.GameServerCommandPhasePending, reasonCommandQueued,
+			fmt.Sprintf("Waiting for GameServerCommand %s to finish", other.Name), nil)
+		return ctrl.Result{RequeueAfter: commandRequeueInterval}, err
+	}
+	if gs.Status.Updates != nil && gs.Status.Updates.Running {
+		err := r.setCommandState(ctx, cmd, gamesv1alpha1.GameServerCommandPhasePending, reasonCommandQueued,
+			fmt.Sprintf("Waiting for the scheduled update check of GameServer %s to finish", gs.Name), nil)
+		return ctrl.Result{RequeueAfter: commandRequeueInterval}, err
+	}
+
+	pod := &corev1.Pod{}
+	podKey := types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}
+	if err := r.Get(ctx, podKey, pod); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get game server pod: %w", err)
+	} else if err != nil || !podReady(pod) {
+		err := r.setCommandState(ctx, cmd, gamesv1alpha1.GameServerCommandPhasePending, reasonWaitingForPod,
+			fmt.Sprintf("Waiting for GameServer %s to be ready", gs.Name), nil)
+		return ctrl.Result{RequeueAfter: commandRequeueInterval}, err
+	}
+
+	// The Running phase is stored before the command runs, so it is not run again when interrupted.
+	if err := r.setCommandState(ctx, cmd, gamesv1alpha1.GameServerCommandPhaseRunning, reasonCommandRunning,
+		fmt.Sprintf("Running %s in GameServer %s", cmd.Spec.Command, gs.Name), nil); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordEvent(cmd, corev1.EventTypeNormal, reasonCommandRunning, "Run",
+		"Running %s in GameServer %s", cmd.Spec.Command, gs.Name)
+
+	// The command runs in the background, as the warnings before a restart and the command itself take a while.
+	running := cmd.DeepCopy()
+	r.trackCommand(req.NamespacedName, cmd.UID)
+	r.commands.start(ctx, cmd.UID, func(ctx context.Context) commandResult {
+		if commandRestartsGameServer(running) {
+			console := gameServerConsole{client: r.Client, executor: r.Executor, rconDialer: r.RCONDialer}
+			if err := console.warnPlayers(ctx, gs, "restarts"); err != nil {
+				r.recordEvent(running, corev1.EventTypeWarning, reasonConsoleFailed, "Run",
+					"Warning players failed: %v", err)
+			}
+		}
+		return r.runCommand(ctx, running, game, pod.Name)
+	})
+	return ctrl.Result{RequeueAfter: backgroundPollInterval}, nil
+}
+
+// trackCommand remembers the UID of the command that starts running in the background.
+func (r *GameServerCommandReconciler) trackCommand(key types.NamespacedName, uid types.UID) {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	if r.runningUIDs == nil {
+		r.runningUIDs = map[types.NamespacedName]types.UID{}
+	}
+	r.runningUIDs[key] = uid
+}
+
+// forgetCommand drops the background task of the command with the name, unless it belongs to the command with the
+// UID that is kept.
+func (r *GameServerCommandReconciler) forgetCommand(key types.NamespacedName, keep types.UID) {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	uid, ok := r.runningUIDs[key]
+	if !ok || uid == keep {
+		return
+	}
+	r.commands.forget(uid)
+	delete(r.runningUIDs, key)
+}
+
+// runCommand runs the command in the game server container and returns its outcome.
+// The command is wrapped in timeout, so it is also stopped in the container when it runs too long.
+func (r *GameServerCommandReconciler) runCommand(
+	ctx context.Context,
+	cmd *gamesv1alpha1.GameServerCommand,
+	game linuxgsm.Game,
+	podName string,
+) commandResult {
+	timeout := commandTimeout(cmd)
+	command := append([]string{
+		"timeout",
+		"--kill-after=" + commandTimeoutKillAfter.String(),
+		strconv.Itoa(int(timeout.Seconds())) + "s",
+	}, game.Command(string(cmd.Spec.Command), cmd.Spec.Args...)...)
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout+2*commandTimeoutKillAfter)
+	defer cancel()
+	result, err := r.Executor.Exec(execCtx, cmd.Namespace, podName, specs.GameServerContainerName, command)
+
+	output := &commandOutput{
+		stdout: truncateOutput(linuxgsm.CleanOutput(result.Stdout)),
+		stderr: truncateOutput(linuxgsm.CleanOutput(result.Stderr)),
+	}
+	failed := func(reason, message string) commandResult {
+		return commandResult{gamesv1alpha1.GameServerCommandPhaseFailed, reason, message, output}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || (err == nil && result.ExitCode == commandTimeoutExitCode):
+		return failed(reasonCommandTimeout, fmt.Sprintf("The command did not finish within %s", timeout))
+	case err != nil:
+		return failed(reasonCommandExecFailed, err.Error())
+	}
+
+	exitCode := int32(result.ExitCode)
+	output.exitCode = &exitCode
+	if exitCode != 0 {
+		return failed(reasonCommandFailed, fmt.Sprintf("The command exited with code %d", exitCode))
+	}
+	return commandResult{gamesv1alpha1.GameServerCommandPhaseSucceeded, reasonCommandSucceeded,
+		"The command exited with code 0", output}
+}
+
+// finishCommand records the outcome of a command that finished.
+func (r *GameServerCommandReconciler) finishCommand(
+	ctx context.Context,
+	cmd *gamesv1alpha1.GameServerCommand,
+	result commandResult,
+) error {
+	if result.phase == gamesv1alpha1.GameServerCommandPhaseFailed {
+		return r.failCommand(ctx, cmd, result.reason, result.message, result.output)
+	}
+	r.recordEvent(cmd, corev1.EventTypeNormal, reasonCommandSucceeded, "Run", "%s succeeded", cmd.Spec.Command)
+	return r.setCommandState(ctx, cmd, result.phase, result.reason, result.message, result.output)
+}
+
+func (r *GameServerCommandReconciler) failCommand(
+	ctx context.Context,
+	cmd *gamesv1alpha1.GameServerCommand,
+	reason, message string,
+	output *commandOutput,
+) error {
+	r.recordEvent(cmd, corev1.EventTypeWarning, reason, "Run", "%s", message)
+	return r.setCommandState(ctx, cmd, gamesv1alpha1.GameServerCommandPhaseFailed, reason, message, output)
+}
+
+// setCommandState sets the phase, the matching conditions and the output, if any, of the GameServerCommand
+// and patches its status. The optimistic lock makes sure a stale GameServerCommand from the cache never runs twice.
+func (r *GameServerCommandReconciler) setCommandState(
+	ctx context.Context,
+	cmd *gamesv1alpha1.GameServerCommand,
+	phase gamesv1alpha1.GameServerCommandPhase,
+	reason, message string,
+	output *commandOutput,
+) error {
+	patch := client.MergeFromWithOptions(cmd.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	applyCommandState(cmd, phase, reason, message, output)
+	if err := r.Status().Patch(ctx, cmd, patch); err != nil {
+		return fmt.Errorf("failed to update GameServerCommand status: %w", err)
+	}
+	return nil
+}
+
+func applyCommandState(
+	cmd *gamesv1alpha1.GameServerCommand,
+	phase gamesv1alpha1.GameServerCommandPhase,
+	reason, message string,
+	output *commandOutput,
+) {
+	status := &cmd.Status
+	status.Phase = phase
+	if output != nil {
+		status.Stdout = output.stdout
+		status.Stderr = output.stderr
+		status.ExitCode = output.exitCode
+	}
+
+	now := metav1.Now()
+	switch phase {
+	case gamesv1alpha1.GameServerCommandPhaseRunning:
+		status.StartTime = &now
+	case gamesv1alpha1.GameServerCommandPhaseSucceeded, gamesv1alpha1.GameServerCommandPhaseFailed:
+		status.CompletionTime = &now
+	}
+
+	progressing := phase == gamesv1alpha1.GameServerCommandPhasePending ||
+		phase == gamesv1alpha1.GameServerCommandPhaseRunning
+
+	for _, c := range []struct {
+		condType string
+		status   bool
+	}{
+		{gamesv1alpha1.GameServerCommandConditionProgressing, progressing},
+		{gamesv1alpha1.GameServerCommandConditionComplete, phase == gamesv1alpha1.GameServerCommandPhaseSucceeded},
+		{gamesv1alpha1.GameServerCommandConditionFailed, phase == gamesv1alpha1.GameServerCommandPhaseFailed},
+	} {
+		condStatus := metav1.ConditionFalse
+		if c.status {
+			condStatus = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               c.condType,
+			Status:             condStatus,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cmd.Generation,
+		})
+	}
+}
+
+// commandFinished reports whether the command succeeded or failed, after which it is never run again.
+func commandFinished(cmd *gamesv1alpha1.GameServerCommand) bool {
+	return cmd.Status.Phase == gamesv1alpha1.GameServerCommandPhaseSucceeded ||
+		cmd.Status.Phase == gamesv1alpha1.GameServerCommandPhaseFailed
+}
+
+// precedingCommand returns another unfinished command of the same game server that runs first:
+// one that is already running, or one that was created earlier.
+func precedingCommand(
+	cmd *gamesv1alpha1.GameServerCommand,
+	commands []gamesv1alpha1.GameServerCommand,
+) *gamesv1alpha1.GameServerCommand {
+	for i := range commands {
+		other := &commands[i]
+		if other.UID == cmd.UID || other.Spec.GameServerRef.Name != cmd.Spec.GameServerRef.Name ||
+			commandFinished(other) {
+			continue
+		}
+		if other.Status.Phase == gamesv1alpha1.GameServerCommandPhaseRunning || createdBefore(other, cmd) {
+			return other
+		}
+	}
+	return nil
+}
+
+// commandRestartsGameServer reports whether LinuxGSM always stops the game to run the command,
+// so players are warned first.
+func commandRestartsGameServer(cmd *gamesv1alpha1.GameServerCommand) bool {
+	switch cmd.Spec.Command {
+	case gamesv1alpha1.GameServerCommandRestart, gamesv1alpha1.GameServerCommandBackup,
+		gamesv1alpha1.GameServerCommandValidate, gamesv1alpha1.GameServerCommandForceUpdate:
+		return true
+	}
+	return false
+}
+
+// commandReplacesServer reports whether LinuxGSM installs its own build of the game to run the command.
+func commandReplacesServer(cmd *gamesv1alpha1.GameServerCommand) bool {
+	switch cmd.Spec.Command {
+	case gamesv1alpha1.GameServerCommandUpdate, gamesv1alpha1.GameServerCommandForceUpdate,
+		gamesv1alpha1.GameServerCommandValidate:
+		return true
+	}
+	return false
+}
+
+func commandTimeout(cmd *gamesv1alpha1.GameServerCommand) time.Duration {
+	if cmd.Spec.TimeoutSeconds > 0 {
+		return time.Duration(cmd.Spec.TimeoutSeconds) * time.Second
+	}
+	return defaultCommandTimeout
+}
+
+// truncateOutput keeps the end of long output, which usually holds the outcome of a LinuxGSM command.
+func truncateOutput(output string) string {
+	if len(output) <= maxCommandOutputBytes {
+		return output
+	}
+	return strings.ToValidUTF8("[truncated]\n"+output[len(output)-maxCommandOutputBytes:], "")
+}
+
+// recordEvent records an event on the GameServerCommand, if the reconciler has an event recorder.
+func (r *GameServerCommandReconciler) recordEvent(
+	cmd *gamesv1alpha1.GameServerCommand,
+	eventType, reason, action, note string,
+	args ...any,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(cmd, nil, eventType, reason, action, note, args...)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GameServerCommandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gamesv1alpha1.GameServerCommand{}).
+		Named("gameservercommand").
+		// Commands run in the background, the workers only wait on the API server.
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentGameServerCommandReconciles}).
+		Complete(r)
+}
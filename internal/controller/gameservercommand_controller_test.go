@@ -0,0 +1,317 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServerCommand Controller", func() {
+	It("keeps the end of long output", func() {
+		output := strings.Repeat("a", maxCommandOutputBytes) + "done\n"
+		truncated := truncateOutput(output)
+		Expect(truncated).To(HavePrefix("[truncated]\n"))
+		Expect(truncated).To(HaveSuffix("done\n"))
+		Expect(truncateOutput("done\n")).To(Equal("done\n"))
+	})
+
+	Context("When reconciling a resource", func() {
+		const gameServerName = "command-test"
+
+		ctx := context.Background()
+
+		var (
+			reconciler *GameServerCommandReconciler
+			executor   *fakeExecutor
+		)
+
+		newCommand := func(name string, command gamesv1alpha1.GameServerCommandName, args ...string) {
+			GinkgoHelper()
+			Expect(k8sClient.Create(ctx, &gamesv1alpha1.GameServerCommand{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+				Spec: gamesv1alpha1.GameServerCommandSpec{
+					GameServerRef: corev1.LocalObjectReference{Name: gameServerName},
+					Command:       command,
+					Args:          args,
+				},
+			})).To(Succeed())
+		}
+
+		// reconcileCommand reconciles the command until it no longer runs in the background.
+		reconcileCommand := func(name string) (*gamesv1alpha1.GameServerCommand, reconcile.Result) {
+			GinkgoHelper()
+			key := types.NamespacedName{Name: name, Namespace: testNamespace}
+			cmd := &gamesv1alpha1.GameServerCommand{}
+			var result reconcile.Result
+			Eventually(func(g Gomega) {
+				var err error
+				result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, key, cmd)).To(Succeed())
+				g.Expect(cmd.Status.Phase).NotTo(Equal(gamesv1alpha1.GameServerCommandPhaseRunning))
+			}).Should(Succeed())
+			return cmd, result
+		}
+
+		createGameServer := func() {
+			GinkgoHelper()
+			Expect(k8sClient.Create(ctx, newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = gameServerName
+				gs.Spec.GameName = "vh"
+			}))).To(Succeed())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: gameServerName + "-0", Namespace: testNamespace},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "gameserver",
+					Image: "gameservermanagers/gameserver:vh",
+				}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			executor = &fakeExecutor{
+				outputs: map[string]string{
+					"timeout --kill-after=10s 300s /app/vhserver details": "\x1b[94mPlayers:\x1b[0m\t0 / 10\n",
+				},
+				exitCodes: map[string]int{
+					"timeout --kill-after=10s 300s /app/vhserver validate": 3,
+				},
+			}
+			reconciler = &GameServerCommandReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &events.FakeRecorder{},
+				Executor: executor,
+			}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &gamesv1alpha1.GameServerCommand{},
+				ctrlclient.InNamespace(testNamespace))).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: gameServerName + "-0", Namespace: testNamespace},
+			}, ctrlclient.GracePeriodSeconds(0)))).To(Succeed())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &gamesv1alpha1.GameServer{
+				ObjectMeta: metav1.ObjectMeta{Name: gameServerName, Namespace: testNamespace},
+			}))).To(Succeed())
+		})
+
+		It("runs the command and captures its output", func() {
+			createGameServer()
+			newCommand("details", gamesv1alpha1.GameServerCommandDetails)
+
+			By("reporting the command as running while it runs in the background")
+			key := types.NamespacedName{Name: "details", Namespace: testNamespace}
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(backgroundPollInterval))
+			cmd := &gamesv1alpha1.GameServerCommand{}
+			Expect(k8sClient.Get(ctx, key, cmd)).To(Succeed())
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseRunning))
+
+			cmd, _ = reconcileCommand("details")
+			Expect(executor.commands).To(Equal([]string{"timeout --kill-after=10s 300s /app/vhserver details"}))
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseSucceeded))
+			Expect(cmd.Status.ExitCode).To(Equal(new(int32(0))))
+			Expect(cmd.Status.Stdout).To(Equal("Players:\t0 / 10\n"))
+			Expect(cmd.Status.StartTime).NotTo(BeNil())
+			Expect(cmd.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(cmd.Status.Conditions,
+				gamesv1alpha1.GameServerCommandConditionComplete)).To(BeTrue())
+
+			By("never running a finished command again")
+			reconcileCommand("details")
+			Expect(executor.commands).To(HaveLen(1))
+		})
+
+		It("forgets a command that is deleted while it runs", func() {
+			createGameServer()
+			newCommand("deleted", gamesv1alpha1.GameServerCommandDetails)
+
+			key := types.NamespacedName{Name: "deleted", Namespace: testNamespace}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			cmd := &gamesv1alpha1.GameServerCommand{}
+			Expect(k8sClient.Get(ctx, key, cmd)).To(Succeed())
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseRunning))
+			_, found, _ := reconciler.commands.result(cmd.UID)
+			Expect(found).To(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, cmd)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			_, found, _ = reconciler.commands.result(cmd.UID)
+			Expect(found).To(BeFalse())
+			Expect(reconciler.runningUIDs).To(BeEmpty())
+		})
+
+		It("passes the arguments of the command", func() {
+			createGameServer()
+			newCommand("send", gamesv1alpha1.GameServerCommandSend, "say hello")
+
+			cmd, _ := reconcileCommand("send")
+			Expect(executor.commands).To(Equal([]string{"timeout --kill-after=10s 300s /app/vhserver send say hello"}))
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseSucceeded))
+		})
+
+		It("fails when the command exits with a non-zero exit code", func() {
+			createGameServer()
+			newCommand("validate", gamesv1alpha1.GameServerCommandValidate)
+
+			cmd, _ := reconcileCommand("validate")
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseFailed))
+			Expect(cmd.Status.ExitCode).To(Equal(new(int32(3))))
+			cond := meta.FindStatusCondition(cmd.Status.Conditions, gamesv1alpha1.GameServerCommandConditionFailed)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(reasonCommandFailed))
+		})
+
+		It("runs the commands of a game server one at a time", func() {
+			createGameServer()
+			newCommand("first", gamesv1alpha1.GameServerCommandDetails)
+			first := &gamesv1alpha1.GameServerCommand{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "first", Namespace: testNamespace}, first)).To(Succeed())
+			first.Status.Phase = gamesv1alpha1.GameServerCommandPhaseRunning
+			Expect(k8sClient.Status().Update(ctx, first)).To(Succeed())
+			newCommand("second", gamesv1alpha1.GameServerCommandDetails)
+
+			cmd, result := reconcileCommand("second")
+			Expect(executor.commands).To(BeEmpty())
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhasePending))
+			Expect(result.RequeueAfter).To(Equal(commandRequeueInterval))
+			cond := meta.FindStatusCondition(cmd.Status.Conditions, gamesv1alpha1.GameServerCommandConditionProgressing)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(reasonCommandQueued))
+
+			By("failing the command that was interrupted while running")
+			cmd, _ = reconcileCommand("first")
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseFailed))
+			Expect(executor.commands).To(BeEmpty())
+
+			cmd, _ = reconcileCommand("second")
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseSucceeded))
+			Expect(executor.commands).To(HaveLen(1))
+		})
+
+		It("waits for the scheduled update check of the game server", func() {
+			createGameServer()
+			gs := &gamesv1alpha1.GameServer{}
+			gsKey := types.NamespacedName{Name: gameServerName, Namespace: testNamespace}
+			Expect(k8sClient.Get(ctx, gsKey, gs)).To(Succeed())
+			gs.Status.Updates = &gamesv1alpha1.UpdateStatus{Running: true}
+			Expect(k8sClient.Status().Update(ctx, gs)).To(Succeed())
+			newCommand("during-update", gamesv1alpha1.GameServerCommandRestart)
+
+			cmd, result := reconcileCommand("during-update")
+			Expect(executor.commands).To(BeEmpty())
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhasePending))
+			Expect(result.RequeueAfter).To(Equal(commandRequeueInterval))
+			cond := meta.FindStatusCondition(cmd.Status.Conditions, gamesv1alpha1.GameServerCommandConditionProgressing)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(reasonCommandQueued))
+			Expect(cond.Message).To(ContainSubstring("update check"))
+
+			By("running the command once the check finished")
+			Expect(k8sClient.Get(ctx, gsKey, gs)).To(Succeed())
+			gs.Status.Updates.Running = false
+			Expect(k8sClient.Status().Update(ctx, gs)).To(Succeed())
+			cmd, _ = reconcileCommand("during-update")
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseSucceeded))
+		})
+
+		It("warns players before restarting the game server", func() {
+			createGameServer()
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: gameServerName, Namespace: testNamespace}, gs)).To(Succeed())
+			gs.Spec.GameName = "mc"
+			gs.Spec.Shutdown = &gamesv1alpha1.ShutdownSpec{Warnings: []metav1.Duration{{Duration: 10 * time.Millisecond}}}
+			Expect(k8sClient.Update(ctx, gs)).To(Succeed())
+			newCommand("restart", gamesv1alpha1.GameServerCommandRestart)
+
+			cmd, _ := reconcileCommand("restart")
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseSucceeded))
+			Expect(executor.commands).To(HaveLen(3))
+			Expect(executor.commands[0]).To(HavePrefix("/app/mcserver send say The server restarts in"))
+			Expect(executor.commands[1:]).To(Equal([]string{
+				"/app/mcserver send save-all flush",
+				"timeout --kill-after=10s 300s /app/mcserver restart",
+			}))
+		})
+
+		It("does not replace a Minecraft server installed by the operator", func() {
+			Expect(k8sClient.Create(ctx, newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = gameServerName
+				gs.Spec.GameName = "mc"
+				gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+					Version: "1.21.4",
+				}}
+			}))).To(Succeed())
+
+			for _, command := range []gamesv1alpha1.GameServerCommandName{
+				gamesv1alpha1.GameServerCommandUpdate,
+				gamesv1alpha1.GameServerCommandForceUpdate,
+				gamesv1alpha1.GameServerCommandValidate,
+			} {
+				newCommand("minecraft-"+string(command), command)
+				cmd, _ := reconcileCommand("minecraft-" + string(command))
+				Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseFailed))
+				cond := meta.FindStatusCondition(cmd.Status.Conditions, gamesv1alpha1.GameServerCommandConditionFailed)
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Reason).To(Equal(reasonMinecraftInstalled))
+				Expect(cond.Message).To(ContainSubstring("spec.gameConfigs.minecraft"))
+			}
+			Expect(executor.commands).To(BeEmpty())
+		})
+
+		It("fails when the game server does not exist", func() {
+			newCommand("missing", gamesv1alpha1.GameServerCommandRestart)
+
+			cmd, _ := reconcileCommand("missing")
+			Expect(executor.commands).To(BeEmpty())
+			Expect(cmd.Status.Phase).To(Equal(gamesv1alpha1.GameServerCommandPhaseFailed))
+			Expect(meta.FindStatusCondition(cmd.Status.Conditions,
+				gamesv1alpha1.GameServerCommandConditionFailed).Reason).To(Equal(reasonGameServerMissing))
+		})
+	})
+})
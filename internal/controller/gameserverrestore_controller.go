@@ -0,0 +1,522 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// Reasons used for the GameServerRestore status conditions and events.
+const (
+	reasonRestorePending     = "Pending"
+	reasonGameServerMissing  = "GameServerNotFound"
+	reasonStorageDisabled    = "StorageDisabled"
+	reasonNoBackupTarget     = "NoBackupTarget"
+	reasonDataVolumeMissing  = "DataVolumeMissing"
+	reasonConcurrentRestore  = "ConcurrentRestore"
+	reasonWaitingForBackup   = "BackupInProgress"
+	reasonStoppingForRestore = "StoppingGameServer"
+	reasonRestoring          = "Restoring"
+	reasonRestored           = "Restored"
+	reasonChecksumMismatch   = "ChecksumMismatch"
+	reasonRestoreJobFailed   = "RestoreJobFailed"
+)
+
+// restoreRequeueInterval is how often a restore is rechecked while waiting for the game server to stop
+// or the restore Job to finish. Job updates also trigger a reconcile, this is only a safety net.
+const restoreRequeueInterval = 10 * time.Second
+
+const fieldManagerGameServerRestore = "gameserverrestore-controller"
+
+// restoreFinalizerName keeps a deleted GameServerRestore, and with it the game server stopped, until its restore Job
+// finished, so the game server never starts on partially restored data.
+const restoreFinalizerName = "gameserverrestore.games.idebeijer.github.io/finalizer"
+
+// GameServerRestoreReconciler reconciles a GameServerRestore object
+type GameServerRestoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameserverrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameserverrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameserverrestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=games.idebeijer.github.io,resources=gameservers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+
+// Reconcile moves a GameServerRestore through its phases. The GameServer controller keeps the game server
+// stopped while a restore is Stopping or Restoring, and starts it again once the restore finished.
+func (r *GameServerRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	restore := &gamesv1alpha1.GameServerRestore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		// Jobs are owned by the GameServerRestore and garbage collected with it, once they finished.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !restore.DeletionTimestamp.IsZero() {
+		return r.reconcileRestoreDeletion(ctx, restore)
+	}
+
+	if restoreFinished(restore) {
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(restore, restoreFinalizerName) {
+		patch := client.MergeFromWithOptions(restore.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.AddFinalizer(restore, restoreFinalizerName)
+		if err := r.Patch(ctx, restore, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	gs := &gamesv1alpha1.GameServer{}
+	gsKey := types.NamespacedName{Name: restore.Spec.GameServerRef.Name, Namespace: restore.Namespace}
+	if err := r.Get(ctx, gsKey, gs); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get GameServer: %w", err)
+		}
+		return ctrl.Result{}, r.failRestore(ctx, restore, reasonGameServerMissing,
+			fmt.Sprintf("GameServer %s does not exist", gsKey.Name))
+	}
+	if !gs.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.failRestore(ctx, restore, reasonGameServerMissing,
+			fmt.Sprintf("GameServer %s is being deleted", gs.Name))
+	}
+
+	if reason, message := restoreUnsupported(gs, restore); reason != "" {
+		return ctrl.Result{}, r.failRestore(ctx, restore, reason, message)
+	}
+
+	switch restore.Status.Phase {
+	case gamesv1alpha1.GameServerRestorePhaseStopping:
+		return r.reconcileStoppingRestore(ctx, gs, restore)
+	case gamesv1alpha1.GameServerRestorePhaseRestoring:
+		return r.reconcileRunningRestore(ctx, gs, restore)
+	default:
+		return r.reconcilePendingRestore(ctx, gs, restore)
+	}
+}
+
+// reconcileRestoreDeletion removes the finalizer of a deleted GameServerRestore once its restore Job is gone and its
+// pods stopped. A Job that has not finished is deleted, so deleting the restore cancels it. The restore keeps its phase
+// meanwhile, so the GameServer controller keeps the game server stopped.
+func (r *GameServerRestoreReconciler) reconcileRestoreDeletion(
+	ctx context.Context,
+	restore *gamesv1alpha1.GameServerRestore,
+) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(restore, restoreFinalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	running, err := r.cancelRestoreJob(ctx, restore)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if running {
+		logf.FromContext(ctx).Info("Waiting for the pods of the restore Job to stop before deletion")
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, nil
+	}
+
+	patch := client.MergeFromWithOptions(restore.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(restore, restoreFinalizerName)
+	if err := r.Patch(ctx, restore, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// cancelRestoreJob deletes the restore Job if it has not finished, and reports whether it still exists or its pods
+// still run.
+func (r *GameServerRestoreReconciler) cancelRestoreJob(
+	ctx context.Context,
+	restore *gamesv1alpha1.GameServerRestore,
+) (bool, error) {
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{Name: specs.GameServerRestoreJobName(restore), Namespace: restore.Namespace}
+	if err := r.Get(ctx, jobKey, job); err == nil {
+		if jobConditionTrue(job, batchv1.JobComplete) || jobConditionTrue(job, batchv1.JobFailed) {
+			return false, nil
+		}
+		if job.DeletionTimestamp.IsZero() {
+			if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+				!apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to delete restore Job: %w", err)
+			}
+		}
+		return true, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get restore Job: %w", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(restore.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: jobKey.Name},
+	); err != nil {
+		return false, fmt.Errorf("failed to list restore pods: %w", err)
+	}
+	for i := range pods.Items {
+		if phase := pods.Items[i].Status.Phase; phase != corev1.PodSucceeded && phase != corev1.PodFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcilePendingRestore checks whether the restore can run and, if so, asks for the game server to be stopped.
+func (r *GameServerRestoreReconciler) reconcilePendingRestore(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) (ctrl.Result, error) {
+	// Restoring two archives at the same time would leave the data in an undefined state.
+	restores := &gamesv1alpha1.GameServerRestoreList{}
+	if err := r.List(ctx, restores, client.InNamespace(restore.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list GameServerRestores: %w", err)
+	}
+	if other := conflictingRestore(restore, restores.Items); other != nil {
+		return ctrl.Result{}, r.failRestore(ctx, restore, reasonConcurrentRestore,
+			fmt.Sprintf("GameServerRestore %s is already restoring GameServer %s", other.Name, gs.Name))
+	}
+
+	// The data volume is created with the game server pod, a restore cannot create it.
+	pvcKey := types.NamespacedName{Name: specs.GameServerDataVolumeClaimName(gs), Namespace: gs.Namespace}
+	if err := r.Get(ctx, pvcKey, &corev1.PersistentVolumeClaim{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get PersistentVolumeClaim: %w", err)
+		}
+		return ctrl.Result{}, r.failRestore(ctx, restore, reasonDataVolumeMissing,
+			fmt.Sprintf("PersistentVolumeClaim %s does not exist, start the game server once to create it", pvcKey.Name))
+	}
+
+	// A running backup reads the data volume, wait for it instead of replacing the data underneath it.
+	backupJobs := &batchv1.JobList{}
+	if err := r.List(ctx, backupJobs,
+		client.InNamespace(gs.Namespace),
+		client.MatchingLabels(specs.GameServerBackupLabels(gs)),
+	); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list backup Jobs: %w", err)
+	}
+	if backupInProgress(backupJobs.Items, time.Now()) {
+		err := r.setRestoreState(ctx, restore, gamesv1alpha1.GameServerRestorePhasePending, reasonWaitingForBackup,
+			"Waiting for the running backup to finish")
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, err
+	}
+
+	r.recordEvent(restore, corev1.EventTypeNormal, reasonStoppingForRestore, "Restore",
+		"Stopping GameServer %s to restore %s", gs.Name, restore.Spec.Backup.Name)
+	err := r.setRestoreState(ctx, restore, gamesv1alpha1.GameServerRestorePhaseStopping, reasonStoppingForRestore,
+		fmt.Sprintf("Waiting for GameServer %s to stop", gs.Name))
+	return ctrl.Result{RequeueAfter: restoreRequeueInterval}, err
+}
+
+// reconcileStoppingRestore starts the restore Job once the game server pod is gone.
+func (r *GameServerRestoreReconciler) reconcileStoppingRestore(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	podKey := types.NamespacedName{Name: specs.GameServerPodName(gs), Namespace: gs.Namespace}
+	if err := r.Get(ctx, podKey, &corev1.Pod{}); err == nil {
+		log.Info("Waiting for the game server to stop before restoring")
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, nil
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to get game server pod: %w", err)
+	}
+
+	if err := r.applyRestoreJob(ctx, gs, restore); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.recordEvent(restore, corev1.EventTypeNormal, reasonRestoring, "Restore",
+		"Restoring %s onto GameServer %s", restore.Spec.Backup.Name, gs.Name)
+	err := r.setRestoreState(ctx, restore, gamesv1alpha1.GameServerRestorePhaseRestoring, reasonRestoring,
+		fmt.Sprintf("Verifying and extracting %s", restore.Spec.Backup.Name))
+	return ctrl.Result{RequeueAfter: restoreRequeueInterval}, err
+}
+
+// reconcileRunningRestore reports the outcome of the restore Job.
+func (r *GameServerRestoreReconciler) reconcileRunningRestore(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) (ctrl.Result, error) {
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{Name: specs.GameServerRestoreJobName(restore), Namespace: restore.Namespace}
+	if err := r.Get(ctx, jobKey, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get restore Job: %w", err)
+		}
+		// The Job was removed before it finished, start it again.
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, r.applyRestoreJob(ctx, gs, restore)
+	}
+
+	switch {
+	case jobConditionTrue(job, batchv1.JobComplete):
+		r.recordEvent(restore, corev1.EventTypeNormal, reasonRestored, "Restore",
+			"Restored %s onto GameServer %s", restore.Spec.Backup.Name, gs.Name)
+		return ctrl.Result{}, r.setRestoreState(ctx, restore, gamesv1alpha1.GameServerRestorePhaseCompleted,
+			reasonRestored, fmt.Sprintf("Restored %s, GameServer %s is started again", restore.Spec.Backup.Name, gs.Name))
+	case jobConditionTrue(job, batchv1.JobFailed):
+		message, err := r.restoreJobMessage(ctx, job)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		reason := reasonRestoreJobFailed
+		if strings.HasPrefix(message, "checksum mismatch") {
+			reason = reasonChecksumMismatch
+		}
+		if message == "" {
+			message = fmt.Sprintf("Restore Job %s failed", job.Name)
+		}
+		return ctrl.Result{}, r.failRestore(ctx, restore, reason, message)
+	default:
+		return ctrl.Result{RequeueAfter: restoreRequeueInterval}, nil
+	}
+}
+
+func (r *GameServerRestoreReconciler) applyRestoreJob(
+	ctx context.Context,
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) error {
+	jobApply := specs.BuildGameServerRestoreJob(gs, restore)
+	if jobApply == nil {
+		return fmt.Errorf("no backup target to restore %s from", restore.Spec.Backup.Name)
+	}
+	jobApply.WithOwnerReferences(restoreOwnerReference(restore))
+
+	if err := r.Apply(ctx, jobApply,
+		client.FieldOwner(fieldManagerGameServerRestore),
+		client.ForceOwnership,
+	); err != nil {
+		return fmt.Errorf("failed to apply restore Job: %w", err)
+	}
+	return nil
+}
+
+// restoreJobMessage returns the termination message of a failed restore pod, which explains
+// e.g. a checksum mismatch.
+func (r *GameServerRestoreReconciler) restoreJobMessage(ctx context.Context, job *batchv1.Job) (string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: job.Name},
+	); err != nil {
+		return "", fmt.Errorf("failed to list restore pods: %w", err)
+	}
+
+	for i := range pods.Items {
+		for _, cs := range pods.Items[i].Status.ContainerStatuses {
+			if cs.State.Terminated != nil && cs.State.Terminated.Message != "" {
+				return strings.TrimSpace(cs.State.Terminated.Message), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (r *GameServerRestoreReconciler) failRestore(
+	ctx context.Context,
+	restore *gamesv1alpha1.GameServerRestore,
+	reason, message string,
+) error {
+	r.recordEvent(restore, corev1.EventTypeWarning, reason, "Restore", "%s", message)
+	return r.setRestoreState(ctx, restore, gamesv1alpha1.GameServerRestorePhaseFailed, reason, message)
+}
+
+// setRestoreState sets the phase and the matching conditions of the GameServerRestore and patches its status.
+func (r *GameServerRestoreReconciler) setRestoreState(
+	ctx context.Context,
+	restore *gamesv1alpha1.GameServerRestore,
+	phase gamesv1alpha1.GameServerRestorePhase,
+	reason, message string,
+) error {
+	patch := client.MergeFrom(restore.DeepCopy())
+	applyRestoreState(restore, phase, reason, message)
+	if err := r.Status().Patch(ctx, restore, patch); err != nil {
+		return fmt.Errorf("failed to update GameServerRestore status: %w", err)
+	}
+	return nil
+}
+
+func applyRestoreState(
+	restore *gamesv1alpha1.GameServerRestore,
+	phase gamesv1alpha1.GameServerRestorePhase,
+	reason, message string,
+) {
+	status := &restore.Status
+	status.Phase = phase
+
+	now := metav1.Now()
+	switch phase {
+	case gamesv1alpha1.GameServerRestorePhaseStopping:
+		status.StartTime = &now
+	case gamesv1alpha1.GameServerRestorePhaseRestoring:
+		status.JobName = specs.GameServerRestoreJobName(restore)
+	case gamesv1alpha1.GameServerRestorePhaseCompleted, gamesv1alpha1.GameServerRestorePhaseFailed:
+		status.CompletionTime = &now
+	}
+
+	progressing := phase == gamesv1alpha1.GameServerRestorePhasePending ||
+		phase == gamesv1alpha1.GameServerRestorePhaseStopping ||
+		phase == gamesv1alpha1.GameServerRestorePhaseRestoring
+
+	for _, c := range []struct {
+		condType string
+		status   bool
+	}{
+		{gamesv1alpha1.GameServerRestoreConditionGameServerStopped, phase == gamesv1alpha1.GameServerRestorePhaseRestoring},
+		{gamesv1alpha1.GameServerRestoreConditionProgressing, progressing},
+		{gamesv1alpha1.GameServerRestoreConditionComplete, phase == gamesv1alpha1.GameServerRestorePhaseCompleted},
+		{gamesv1alpha1.GameServerRestoreConditionFailed, phase == gamesv1alpha1.GameServerRestorePhaseFailed},
+	} {
+		condStatus := metav1.ConditionFalse
+		if c.status {
+			condStatus = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               c.condType,
+			Status:             condStatus,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: restore.Generation,
+		})
+	}
+}
+
+// restoreUnsupported returns why the GameServer cannot be restored, if it cannot.
+func restoreUnsupported(
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) (reason, message string) {
+	switch {
+	case !specs.LinuxGSMStorageEnabled(gs):
+		return reasonStorageDisabled, fmt.Sprintf("GameServer %s has no persistent storage to restore to", gs.Name)
+	case specs.GameServerRestoreTarget(gs, restore) == nil:
+		return reasonNoBackupTarget, fmt.Sprintf(
+			"GameServer %s has no backups configured, set spec.backup.target on the restore", gs.Name)
+	default:
+		return "", ""
+	}
+}
+
+// restoreFinished reports whether the restore completed or failed, after which it is never run again.
+func restoreFinished(restore *gamesv1alpha1.GameServerRestore) bool {
+	return restore.Status.Phase == gamesv1alpha1.GameServerRestorePhaseCompleted ||
+		restore.Status.Phase == gamesv1alpha1.GameServerRestorePhaseFailed
+}
+
+// restoreActive reports whether the restore stopped, or is stopping, the game server to replace its data.
+func restoreActive(restore *gamesv1alpha1.GameServerRestore) bool {
+	return restore.Status.Phase == gamesv1alpha1.GameServerRestorePhaseStopping ||
+		restore.Status.Phase == gamesv1alpha1.GameServerRestorePhaseRestoring
+}
+
+// conflictingRestore returns another unfinished restore of the same game server that takes precedence:
+// one that is already active, or one that was created earlier and is still waiting to start.
+func conflictingRestore(
+	restore *gamesv1alpha1.GameServerRestore,
+	restores []gamesv1alpha1.GameServerRestore,
+) *gamesv1alpha1.GameServerRestore {
+	for i := range restores {
+		other := &restores[i]
+		if other.UID == restore.UID || other.Spec.GameServerRef.Name != restore.Spec.GameServerRef.Name ||
+			restoreFinished(other) {
+			continue
+		}
+		if restoreActive(other) || createdBefore(other, restore) {
+			return other
+		}
+	}
+	return nil
+}
+
+// createdBefore orders objects by creation, falling back to their names for objects created in the same second.
+func createdBefore(a, b metav1.Object) bool {
+	createdA, createdB := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !createdA.Equal(&createdB) {
+		return createdA.Before(&createdB)
+	}
+	return a.GetName() < b.GetName()
+}
+
+// restoreOwnerReference makes the GameServerRestore the controlling owner of its restore Job.
+func restoreOwnerReference(restore *gamesv1alpha1.GameServerRestore) *metav1ac.OwnerReferenceApplyConfiguration {
+	return metav1ac.OwnerReference().
+		WithAPIVersion(gamesv1alpha1.GroupVersion.String()).
+		WithKind("GameServerRestore").
+		WithName(restore.Name).
+		WithUID(restore.UID).
+		WithController(true).
+		WithBlockOwnerDeletion(true)
+}
+
+// recordEvent records an event on the GameServerRestore, if the reconciler has an event recorder.
+func (r *GameServerRestoreReconciler) recordEvent(
+	restore *gamesv1alpha1.GameServerRestore,
+	eventType, reason, action, note string,
+	args ...any,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(restore, nil, eventType, reason, action, note, args...)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GameServerRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gamesv1alpha1.GameServerRestore{}).
+		Named("gameserverrestore").
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,263 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("GameServerRestore Controller", func() {
+	Context("When reconciling a resource", func() {
+		const gameServerName = "restore-test"
+
+		ctx := context.Background()
+		gsKey := types.NamespacedName{Name: gameServerName, Namespace: testNamespace}
+
+		var (
+			gsReconciler      *GameServerReconciler
+			restoreReconciler *GameServerRestoreReconciler
+		)
+
+		newRestore := func(name string) *gamesv1alpha1.GameServerRestore {
+			return &gamesv1alpha1.GameServerRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+				Spec: gamesv1alpha1.GameServerRestoreSpec{
+					GameServerRef: corev1.LocalObjectReference{Name: gameServerName},
+					Backup:        gamesv1alpha1.RestoreBackupSource{Name: "restore-test-20260101040000.tar.gz"},
+				},
+			}
+		}
+
+		reconcileRestore := func(name string) *gamesv1alpha1.GameServerRestore {
+			GinkgoHelper()
+			key := types.NamespacedName{Name: name, Namespace: testNamespace}
+			_, err := restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			restore := &gamesv1alpha1.GameServerRestore{}
+			Expect(k8sClient.Get(ctx, key, restore)).To(Succeed())
+			return restore
+		}
+
+		reconcileGameServer := func() *appsv1.StatefulSet {
+			GinkgoHelper()
+			_, err := gsReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gsKey})
+			Expect(err).NotTo(HaveOccurred())
+			sts := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, gsKey, sts)).To(Succeed())
+			return sts
+		}
+
+		BeforeEach(func() {
+			gsReconciler = &GameServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			restoreReconciler = &GameServerRestoreReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: &events.FakeRecorder{},
+			}
+
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Name = gameServerName
+				gs.Spec.Backup = &gamesv1alpha1.BackupSpec{
+					Schedule: "0 4 * * *",
+					Target: gamesv1alpha1.BackupTarget{
+						PersistentVolumeClaim: &gamesv1alpha1.PersistentVolumeClaimBackupTarget{ClaimName: "backups"},
+					},
+				}
+			})
+			Expect(k8sClient.Create(ctx, gs)).To(Succeed())
+
+			By("creating the data volume the StatefulSet would have created")
+			// The volume is kept between tests, without a controller manager its protection finalizer is never removed.
+			pvcKey := types.NamespacedName{Name: specs.GameServerDataVolumeClaimName(gs), Namespace: testNamespace}
+			if k8sClient.Get(ctx, pvcKey, &corev1.PersistentVolumeClaim{}) == nil {
+				return
+			}
+			Expect(k8sClient.Create(ctx, &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: specs.GameServerDataVolumeClaimName(gs), Namespace: testNamespace},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			})).To(Succeed())
+		})
+
+		AfterEach(func() {
+			restores := &gamesv1alpha1.GameServerRestoreList{}
+			Expect(k8sClient.List(ctx, restores, ctrlclient.InNamespace(testNamespace))).To(Succeed())
+			for i := range restores.Items {
+				restores.Items[i].Finalizers = nil
+				Expect(k8sClient.Update(ctx, &restores.Items[i])).To(Succeed())
+			}
+			Expect(k8sClient.DeleteAllOf(ctx, &gamesv1alpha1.GameServerRestore{},
+				ctrlclient.InNamespace(testNamespace))).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, ctrlclient.InNamespace(testNamespace),
+				ctrlclient.MatchingLabels{"app.kubernetes.io/instance": gameServerName},
+				ctrlclient.PropagationPolicy(metav1.DeletePropagationBackground))).To(Succeed())
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, gsKey, gs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, gs)).To(Succeed())
+			_, err := gsReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gsKey})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctrlclient.IgnoreNotFound(k8sClient.Delete(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: gameServerName, Namespace: testNamespace},
+			}))).To(Succeed())
+		})
+
+		It("stops the game server, restores the archive and starts it again", func() {
+			Expect(k8sClient.Create(ctx, newRestore("restore-first"))).To(Succeed())
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(1))))
+
+			By("stopping the game server")
+			restore := reconcileRestore("restore-first")
+			Expect(restore.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseStopping))
+			Expect(restore.Status.StartTime).NotTo(BeNil())
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(0))))
+
+			gs := &gamesv1alpha1.GameServer{}
+			Expect(k8sClient.Get(ctx, gsKey, gs)).To(Succeed())
+			Expect(gs.Status.Phase).To(Equal(gamesv1alpha1.GameServerPhaseStopped))
+			Expect(meta.FindStatusCondition(gs.Status.Conditions, gamesv1alpha1.GameServerConditionProgressing).Reason).
+				To(Equal(reasonRestoring))
+
+			By("refusing a second restore of the same game server")
+			Expect(k8sClient.Create(ctx, newRestore("restore-second"))).To(Succeed())
+			second := reconcileRestore("restore-second")
+			Expect(second.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseFailed))
+			Expect(meta.FindStatusCondition(second.Status.Conditions, gamesv1alpha1.GameServerRestoreConditionFailed).
+				Reason).To(Equal(reasonConcurrentRestore))
+
+			By("starting the restore Job once the game server pod is gone")
+			restore = reconcileRestore("restore-first")
+			Expect(restore.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseRestoring))
+			Expect(meta.IsStatusConditionTrue(restore.Status.Conditions,
+				gamesv1alpha1.GameServerRestoreConditionGameServerStopped)).To(BeTrue())
+			Expect(restore.Status.JobName).To(Equal("restore-first-restore"))
+
+			job := &batchv1.Job{}
+			jobKey := types.NamespacedName{Name: restore.Status.JobName, Namespace: testNamespace}
+			Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+			Expect(metav1.IsControlledBy(job, restore)).To(BeTrue())
+
+			By("completing the restore Job")
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue},
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+			restore = reconcileRestore("restore-first")
+			Expect(restore.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseCompleted))
+			Expect(restore.Status.CompletionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(restore.Status.Conditions,
+				gamesv1alpha1.GameServerRestoreConditionComplete)).To(BeTrue())
+
+			By("starting the game server again")
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(1))))
+		})
+
+		It("cancels the Job of a deleted restore and keeps the game server stopped until its pods stopped", func() {
+			Expect(k8sClient.Create(ctx, newRestore("restore-deleted"))).To(Succeed())
+			reconcileRestore("restore-deleted")
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(0))))
+			restore := reconcileRestore("restore-deleted")
+			Expect(restore.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseRestoring))
+			Expect(restore.Finalizers).To(ContainElement(restoreFinalizerName))
+			jobKey := types.NamespacedName{Name: restore.Status.JobName, Namespace: testNamespace}
+			Expect(k8sClient.Get(ctx, jobKey, &batchv1.Job{})).To(Succeed())
+
+			By("creating a pod of the Job that does not start")
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "restore-deleted-pod",
+					Namespace: testNamespace,
+					Labels:    map[string]string{batchv1.JobNameLabel: jobKey.Name},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "restore", Image: "restore"}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodPending
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			By("deleting the Job of the deleted restore")
+			Expect(k8sClient.Delete(ctx, restore)).To(Succeed())
+			restore = reconcileRestore("restore-deleted")
+			Expect(restore.DeletionTimestamp).NotTo(BeNil())
+			err := k8sClient.Get(ctx, jobKey, &batchv1.Job{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected the Job to be deleted, got %v", err)
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(0))))
+
+			By("keeping the finalizer while the pod of the Job runs")
+			restore = reconcileRestore("restore-deleted")
+			Expect(restore.Finalizers).To(ContainElement(restoreFinalizerName))
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(0))))
+
+			By("removing the finalizer once the pod stopped")
+			pod.Status.Phase = corev1.PodFailed
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			key := types.NamespacedName{Name: "restore-deleted", Namespace: testNamespace}
+			_, err = restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, key, &gamesv1alpha1.GameServerRestore{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected the restore to be deleted, got %v", err)
+			Expect(reconcileGameServer().Spec.Replicas).To(HaveValue(Equal(int32(1))))
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+		})
+
+		It("fails when the game server does not exist", func() {
+			restore := newRestore("restore-missing")
+			restore.Spec.GameServerRef.Name = "does-not-exist"
+			Expect(k8sClient.Create(ctx, restore)).To(Succeed())
+
+			restore = reconcileRestore("restore-missing")
+			Expect(restore.Status.Phase).To(Equal(gamesv1alpha1.GameServerRestorePhaseFailed))
+			Expect(meta.FindStatusCondition(restore.Status.Conditions, gamesv1alpha1.GameServerRestoreConditionFailed).
+				Reason).To(Equal(reasonGameServerMissing))
+		})
+	})
+})
@@ -0,0 +1,738 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+// log is for logging in this package.
+var gameserverlog = logf.Log.WithName("gameserver-resource")
+
+const (
+	// defaultStorageSize mirrors the default of StorageSpec.Size.
+	defaultStorageSize = "10Gi"
+
+	// nodePortMin and nodePortMax are the bounds of the default kube-apiserver --service-node-port-range.
+	nodePortMin = 30000
+	nodePortMax = 32767
+)
+
+// SetupGameServerWebhookWithManager registers the webhook for GameServer in the manager.
+func SetupGameServerWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &gamesv1alpha1.GameServer{}).
+		WithValidator(&GameServerCustomValidator{}).
+		WithDefaulter(&GameServerCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-games-idebeijer-github-io-v1alpha1-gameserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=games.idebeijer.github.io,resources=gameservers,verbs=create,versions=v1alpha1,name=mgameserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// GameServerCustomDefaulter fills in the recommended ports, resources and storage size of the game
+// from the built-in LinuxGSM catalog when a GameServer is created.
+// Defaults are only applied on creation, so that a catalog change never restarts or resizes existing servers.
+type GameServerCustomDefaulter struct{}
+
+var _ admission.Defaulter[*gamesv1alpha1.GameServer] = &GameServerCustomDefaulter{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type GameServer.
+func (d *GameServerCustomDefaulter) Default(_ context.Context, gs *gamesv1alpha1.GameServer) error {
+	gameserverlog.Info("Defaulting for GameServer", "name", gs.GetName())
+
+	defaults, ok := linuxgsm.LookupDefaults(gs.Spec.GameName)
+	if !ok {
+		return nil
+	}
+	applyGameDefaults(&gs.Spec, defaults)
+
+	return nil
+}
+
+// applyGameDefaults sets the fields the user omitted to the defaults of the game.
+// Explicitly set values are never overridden.
+func applyGameDefaults(spec *gamesv1alpha1.GameServerSpec, defaults linuxgsm.Defaults) {
+	if spec.Service != nil && len(spec.Service.Ports) == 0 {
+		for _, port := range defaults.Ports {
+			spec.Service.Ports = append(spec.Service.Ports, gamesv1alpha1.ServicePort{
+				Name:     port.Name,
+				Port:     port.Port,
+				Protocol: port.Protocol,
+			})
+		}
+	}
+
+	if spec.Resources == nil {
+		spec.Resources = &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    defaults.CPU.DeepCopy(),
+				corev1.ResourceMemory: defaults.Memory.DeepCopy(),
+			},
+		}
+	}
+
+	if spec.Storage == nil {
+		spec.Storage = &gamesv1alpha1.StorageSpec{}
+	}
+	if spec.Storage.Size == "" {
+		spec.Storage.Size = defaults.StorageSize
+	}
+}
+
+// NOTE: The 'path' attribute must follow a specific pattern and should not be modified directly here.
+// Modifying the path for an invalid path can cause API server errors; failing to locate the webhook.
+// +kubebuilder:webhook:path=/validate-games-idebeijer-github-io-v1alpha1-gameserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=games.idebeijer.github.io,resources=gameservers,verbs=create;update,versions=v1alpha1,name=vgameserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// GameServerCustomValidator validates GameServer resources on creation and update.
+type GameServerCustomValidator struct{}
+
+var _ admission.Validator[*gamesv1alpha1.GameServer] = &GameServerCustomValidator{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type GameServer.
+func (v *GameServerCustomValidator) ValidateCreate(
+	_ context.Context,
+	gs *gamesv1alpha1.GameServer,
+) (admission.Warnings, error) {
+	gameserverlog.Info("Validation for GameServer upon creation", "name", gs.GetName())
+
+	return deprecationWarnings(&gs.Spec), toInvalidError(gs, validateGameServerSpec(&gs.Spec))
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type GameServer.
+func (v *GameServerCustomValidator) ValidateUpdate(
+	_ context.Context,
+	oldGS, newGS *gamesv1alpha1.GameServer,
+) (admission.Warnings, error) {
+	gameserverlog.Info("Validation for GameServer upon update", "name", newGS.GetName())
+
+	// Updates of the metadata only, e.g. of the finalizer or the wake annotation, must not be rejected because the
+	// stored spec does not pass rules that were added later, that would leave the GameServer stuck.
+	if newGS.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldGS.Spec, newGS.Spec) {
+		return nil, nil
+	}
+
+	allErrs := validateGameServerSpec(&newGS.Spec)
+	allErrs = append(allErrs, validateGameServerSpecUpdate(&oldGS.Spec, &newGS.Spec)...)
+
+	return deprecationWarnings(&newGS.Spec), toInvalidError(newGS, allErrs)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type GameServer.
+func (v *GameServerCustomValidator) ValidateDelete(
+	_ context.Context,
+	_ *gamesv1alpha1.GameServer,
+) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func toInvalidError(gs *gamesv1alpha1.GameServer, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(gamesv1alpha1.GroupVersion.WithKind("GameServer").GroupKind(), gs.Name, allErrs)
+}
+
+// deprecationWarnings warns about uses of spec.replicas, which is superseded by spec.state.
+// The CRD defaults replicas to 1, so only other values can have been set explicitly.
+func deprecationWarnings(spec *gamesv1alpha1.GameServerSpec) admission.Warnings {
+	switch {
+	case spec.Replicas > 1:
+		return admission.Warnings{fmt.Sprintf(
+			"spec.replicas: a GameServer always runs a single instance, %d replicas will not be honored", spec.Replicas)}
+	case spec.Replicas == 0 && spec.State == "":
+		return admission.Warnings{"spec.replicas is deprecated, use spec.state: Stopped instead"}
+	case spec.Replicas == 0:
+		return admission.Warnings{"spec.replicas is deprecated and ignored because spec.state is set"}
+	}
+	return nil
+}
+
+func validateGameServerSpec(spec *gamesv1alpha1.GameServerSpec) field.ErrorList {
+	specPath := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	if _, ok := linuxgsm.LookupGame(spec.GameName); !ok {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("gameName"), spec.GameName,
+			"must be the shortname of a game supported by LinuxGSM, e.g. 'mc' or 'rust'"))
+	}
+
+	if spec.Service != nil {
+		allErrs = append(allErrs, validateServiceSpec(spec.Service, specPath.Child("service"))...)
+	}
+
+	if spec.Backup != nil && spec.Storage != nil && !enabled(spec.Storage.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("backup"), "requires persistent storage to be enabled"))
+	}
+
+	if spec.Backup != nil {
+		allErrs = append(allErrs, validateBackupSpec(spec.Backup, specPath.Child("backup"))...)
+	}
+
+	if spec.Updates != nil {
+		allErrs = append(allErrs, validateSchedule(spec.Updates.Schedule, spec.Updates.TimeZone, specPath.Child("updates"))...)
+	}
+
+	if spec.RCON != nil {
+		allErrs = append(allErrs, validateRCONSpec(spec.GameName, spec.RCON, specPath.Child("rcon"))...)
+	}
+
+	allErrs = append(allErrs, validateSecrets(spec, specPath)...)
+
+	if spec.Shutdown != nil {
+		allErrs = append(allErrs, validateShutdownSpec(spec.Shutdown, specPath.Child("shutdown"))...)
+	}
+
+	if spec.Probes != nil {
+		allErrs = append(allErrs, validateProbesSpec(spec, specPath.Child("probes"))...)
+	}
+
+	if spec.Idle != nil {
+		allErrs = append(allErrs, validateIdleSpec(spec, specPath.Child("idle"))...)
+	}
+
+	if spec.Schedule != nil {
+		allErrs = append(allErrs, validateScheduleSpec(spec.Schedule, specPath.Child("schedule"))...)
+	}
+
+	if spec.GameConfigs != nil && spec.GameConfigs.Minecraft != nil {
+		allErrs = append(allErrs, validateMinecraftConfig(spec, specPath.Child("gameConfigs", "minecraft"))...)
+	}
+
+	allErrs = append(allErrs, validateConfig(spec, specPath)...)
+
+	if spec.Storage != nil && spec.Storage.FromSnapshot != "" && !enabled(spec.Storage.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("storage", "fromSnapshot"),
+			"requires persistent storage to be enabled"))
+	}
+
+	return allErrs
+}
+
+// validateBackupSpec checks the schedule, which the operator interprets itself for Snapshot backups.
+func validateBackupSpec(backup *gamesv1alpha1.BackupSpec, fldPath *field.Path) field.ErrorList {
+	return validateSchedule(backup.Schedule, backup.TimeZone, fldPath)
+}
+
+// validateSchedule checks a cron schedule and its time zone.
+func validateSchedule(schedule string, timeZone *string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), schedule, err.Error()))
+	}
+
+	if timeZone != nil {
+		if _, err := time.LoadLocation(*timeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), *timeZone, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateScheduleSpec checks the cron schedules of the windows and their time zone.
+func validateScheduleSpec(schedule *gamesv1alpha1.ScheduleSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, window := range schedule.Windows {
+		windowPath := fldPath.Child("windows").Index(i)
+		if _, err := cron.ParseStandard(window.Start); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), window.Start, err.Error()))
+		}
+		if _, err := cron.ParseStandard(window.Stop); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("stop"), window.Stop, err.Error()))
+		}
+	}
+
+	if schedule.TimeZone != nil {
+		if _, err := time.LoadLocation(*schedule.TimeZone); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), *schedule.TimeZone, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateMinecraftConfig requires persistent storage for the loader, modpack and mods, which are installed into the
+// data volume, and checks the mods refer to a file the way their source expects.
+func validateMinecraftConfig(spec *gamesv1alpha1.GameServerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	minecraft := spec.GameConfigs.Minecraft
+	storageDisabled := spec.Storage != nil && !enabled(spec.Storage.Enabled)
+
+	if storageDisabled && minecraft.Loader != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("loader"), "requires persistent storage to be enabled"))
+	}
+	if minecraft.LoaderVersion != "" && minecraft.Loader == gamesv1alpha1.MinecraftLoaderVanilla {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("loaderVersion"), "the Vanilla loader has no version"))
+	}
+	if minecraft.Version == "" && minecraft.Modpack == nil &&
+		(minecraft.Loader == gamesv1alpha1.MinecraftLoaderForge || minecraft.Loader == gamesv1alpha1.MinecraftLoaderNeoForge) {
+		allErrs = append(allErrs, field.Required(fldPath.Child("version"),
+			fmt.Sprintf("the Minecraft version is required for %s without a modpack", minecraft.Loader)))
+	}
+	if minecraft.Modpack != nil {
+		modpackPath := fldPath.Child("modpack")
+		if storageDisabled {
+			allErrs = append(allErrs, field.Forbidden(modpackPath, "requires persistent storage to be enabled"))
+		}
+		allErrs = append(allErrs, validateMinecraftMod(*minecraft.Modpack, modpackPath)...)
+	}
+	// LinuxGSM updates the vanilla server it installs, which would replace the server installed by the operator.
+	if spec.Updates != nil && (minecraft.Version != "" || minecraft.Loader != "" || minecraft.Modpack != nil) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "updates"),
+			"the Minecraft server is installed by the operator when a version, loader or modpack is set, "+
+				"change the version instead"))
+	}
+
+	modsPath := fldPath.Child("mods")
+	if len(minecraft.Mods) > 0 && storageDisabled {
+		allErrs = append(allErrs, field.Forbidden(modsPath, "requires persistent storage to be enabled"))
+	}
+	for i, mod := range minecraft.Mods {
+		allErrs = append(allErrs, validateMinecraftMod(mod, modsPath.Index(i))...)
+	}
+
+	allErrs = append(allErrs, validateMinecraftServerConfig(minecraft, storageDisabled, fldPath)...)
+
+	return allErrs
+}
+
+// validateMinecraftServerConfig checks the properties, whitelist and ops of a Minecraft server, which are written
+// into the server files on the data volume.
+func validateMinecraftServerConfig(
+	minecraft *gamesv1alpha1.MinecraftConfig,
+	storageDisabled bool,
+	fldPath *field.Path,
+) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if storageDisabled {
+		if minecraft.Properties != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("properties"),
+				"requires persistent storage to be enabled"))
+		}
+		if minecraft.Whitelist != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("whitelist"),
+				"requires persistent storage to be enabled"))
+		}
+		if minecraft.Ops != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ops"), "requires persistent storage to be enabled"))
+		}
+	}
+	if minecraft.Properties != nil {
+		extraPath := fldPath.Child("properties", "extra")
+		for _, name := range slices.Sorted(maps.Keys(minecraft.Properties.Extra)) {
+			value := minecraft.Properties.Extra[name]
+			switch {
+			case !minecraftPropertyNamePattern.MatchString(name):
+				allErrs = append(allErrs, field.Invalid(extraPath.Key(name), name,
+					"must be the name of a property in server.properties"))
+			case specs.MinecraftPropertyReserved(name):
+				allErrs = append(allErrs, field.Forbidden(extraPath.Key(name),
+					"is set by the operator, use the typed properties, the whitelist or spec.rcon instead"))
+			case strings.ContainsAny(value, "\r\n"):
+				allErrs = append(allErrs, field.Invalid(extraPath.Key(name), value, "must be a single line"))
+			}
+		}
+		if motd := minecraft.Properties.MOTD; motd != nil && strings.ContainsAny(*motd, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("properties", "motd"), *motd,
+				"must be a single line"))
+		}
+		if seed := minecraft.Properties.Seed; seed != nil && strings.ContainsAny(*seed, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("properties", "seed"), *seed,
+				"must be a single line"))
+		}
+	}
+
+	return allErrs
+}
+
+// linuxGSMSettingPattern matches the names of settings in LinuxGSM configs, which are bash variables.
+var linuxGSMSettingPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateConfig checks spec.linuxgsm.config are LinuxGSM settings, and spec.configFiles are files on the data volume
+// with a single source.
+func validateConfig(spec *gamesv1alpha1.GameServerSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.LinuxGSM != nil {
+		configPath := specPath.Child("linuxgsm", "config")
+		for _, name := range slices.Sorted(maps.Keys(spec.LinuxGSM.Config)) {
+			if !linuxGSMSettingPattern.MatchString(name) {
+				allErrs = append(allErrs, field.Invalid(configPath.Key(name), name,
+					"must be the name of a LinuxGSM setting"))
+			}
+		}
+	}
+
+	filesPath := specPath.Child("configFiles")
+	if len(spec.ConfigFiles) > 0 && spec.Storage != nil && !enabled(spec.Storage.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(filesPath, "requires persistent storage to be enabled"))
+	}
+	for i, file := range spec.ConfigFiles {
+		filePath := filesPath.Index(i)
+		if (file.ConfigMapKeyRef == nil) == (file.SecretKeyRef == nil) {
+			allErrs = append(allErrs, field.Invalid(filePath, file.Path,
+				"exactly one of configMapKeyRef and secretKeyRef must be set"))
+		}
+		if strings.HasSuffix(file.Path, "/") || slices.Contains(strings.Split(file.Path, "/"), "..") {
+			allErrs = append(allErrs, field.Invalid(filePath.Child("path"), file.Path,
+				"must be the path of a file in the data volume"))
+		}
+	}
+
+	return allErrs
+}
+
+// minecraftPropertyNamePattern matches the names of properties in server.properties.
+var minecraftPropertyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// validateMinecraftMod checks a mod or modpack refers to a file the way its source expects.
+func validateMinecraftMod(mod gamesv1alpha1.MinecraftMod, modPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch mod.Source {
+	case gamesv1alpha1.MinecraftModSourceURL:
+		if u, err := url.Parse(mod.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(modPath.Child("url"), mod.URL, "must be an http or https URL"))
+		}
+		if mod.Project != "" || mod.Version != "" {
+			allErrs = append(allErrs, field.Forbidden(modPath.Child("project"),
+				"mods downloaded from a URL have no project or version"))
+		}
+	default:
+		if mod.Project == "" {
+			allErrs = append(allErrs, field.Required(modPath.Child("project"),
+				fmt.Sprintf("the project of mods from %s is required", mod.Source)))
+		}
+		if mod.URL != "" {
+			allErrs = append(allErrs, field.Forbidden(modPath.Child("url"),
+				fmt.Sprintf("mods from %s are downloaded from the URL reported by %s", mod.Source, mod.Source)))
+		}
+	}
+	if mod.Source == gamesv1alpha1.MinecraftModSourceCurseForge {
+		if _, err := strconv.Atoi(mod.Project); mod.Project != "" && err != nil {
+			allErrs = append(allErrs, field.Invalid(modPath.Child("project"), mod.Project,
+				"must be the numeric ID of the CurseForge mod"))
+		}
+		if _, err := strconv.Atoi(mod.Version); mod.Version != "" && err != nil {
+			allErrs = append(allErrs, field.Invalid(modPath.Child("version"), mod.Version,
+				"must be the numeric ID of the CurseForge file"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRCONSpec requires the port for games the operator does not know the RCON port of.
+func validateRCONSpec(gameName string, rcon *gamesv1alpha1.RCONSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, ok := linuxgsm.LookupRCON(gameName); !ok && rcon.Port == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("port"),
+			fmt.Sprintf("the RCON port of %s is not known, enable RCON in the config of the game and set its port", gameName)))
+	}
+
+	if rcon.PasswordSecretRef != nil && rcon.PasswordSecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("passwordSecretRef", "name"), ""))
+	}
+
+	return allErrs
+}
+
+// validateSecrets requires a single source for each password, and exactly one RCON password when RCON is enabled.
+func validateSecrets(spec *gamesv1alpha1.GameServerSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	secrets := spec.Secrets
+	if secrets == nil {
+		secrets = &gamesv1alpha1.SecretsSpec{}
+	}
+	secretsPath := specPath.Child("secrets")
+
+	switch {
+	case spec.RCON == nil && secrets.RCONPassword != nil:
+		allErrs = append(allErrs, field.Forbidden(secretsPath.Child("rconPassword"), "requires spec.rcon to be set"))
+	case spec.RCON != nil && spec.RCON.PasswordSecretRef == nil && secrets.RCONPassword == nil:
+		allErrs = append(allErrs, field.Required(specPath.Child("rcon", "passwordSecretRef"),
+			"either passwordSecretRef or spec.secrets.rconPassword must be set"))
+	case spec.RCON != nil && spec.RCON.PasswordSecretRef != nil && secrets.RCONPassword != nil:
+		allErrs = append(allErrs, field.Forbidden(secretsPath.Child("rconPassword"),
+			"may not be set together with spec.rcon.passwordSecretRef"))
+	}
+
+	for _, password := range []struct {
+		name   string
+		source *gamesv1alpha1.SecretSource
+	}{
+		{"serverPassword", secrets.ServerPassword},
+		{"rconPassword", secrets.RCONPassword},
+		{"adminPassword", secrets.AdminPassword},
+	} {
+		source := password.source
+		if source == nil {
+			continue
+		}
+		sourcePath := secretsPath.Child(password.name)
+		switch {
+		case source.SecretKeyRef == nil && !source.Generate:
+			allErrs = append(allErrs, field.Required(sourcePath, "one of secretKeyRef and generate must be set"))
+		case source.SecretKeyRef != nil && source.Generate:
+			allErrs = append(allErrs, field.Forbidden(sourcePath.Child("generate"),
+				"may not be set together with secretKeyRef"))
+		case source.SecretKeyRef != nil && source.SecretKeyRef.Name == "":
+			allErrs = append(allErrs, field.Required(sourcePath.Child("secretKeyRef", "name"), ""))
+		}
+	}
+	if secrets.GSLT != nil && secrets.GSLT.Name == "" {
+		allErrs = append(allErrs, field.Required(secretsPath.Child("gslt", "name"), ""))
+	}
+
+	return allErrs
+}
+
+// maxShutdownWarning bounds the warnings, which delay the termination of the game server pod.
+const maxShutdownWarning = time.Hour
+
+// validateShutdownSpec checks the warnings, which the CRD can only check to be durations.
+func validateShutdownSpec(shutdown *gamesv1alpha1.ShutdownSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, warning := range shutdown.Warnings {
+		if warning.Duration < time.Second || warning.Duration > maxShutdownWarning {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("warnings").Index(i), warning.Duration.String(),
+				fmt.Sprintf("must be between 1s and %s", maxShutdownWarning)))
+		}
+	}
+
+	return allErrs
+}
+
+type protocolPort struct {
+	protocol corev1.Protocol
+	port     int32
+}
+
+// minStartupTimeout is the shortest startup timeout, as the startup probe runs every 10 seconds.
+const minStartupTimeout = time.Minute
+
+// minIdleTimeout and minIdleCheckInterval keep empty game servers from being stopped before players had a chance
+// to join, and the game servers from being queried too often.
+const (
+	minIdleTimeout       = time.Minute
+	minIdleCheckInterval = 10 * time.Second
+)
+
+// validateIdleSpec requires a game the operator can count the players of, a Service to wake the game server up
+// through and checks the durations.
+func validateIdleSpec(spec *gamesv1alpha1.GameServerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if _, ok := linuxgsm.LookupQuery(spec.GameName); !ok {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			fmt.Sprintf("the players of %s cannot be counted, the operator does not know how to query it", spec.GameName)))
+	}
+
+	if spec.Idle.WakeOnConnect && spec.Service == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("wakeOnConnect"),
+			"requires spec.service, players connect to the Service to wake the game server up"))
+	}
+
+	timeout, checkInterval := specs.GameServerIdleTimeouts(&gamesv1alpha1.GameServer{Spec: *spec})
+	if timeout < minIdleTimeout {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), timeout.String(),
+			fmt.Sprintf("must be at least %s", minIdleTimeout)))
+	}
+	if checkInterval < minIdleCheckInterval || checkInterval > timeout {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("checkInterval"), checkInterval.String(),
+			fmt.Sprintf("must be between %s and the timeout", minIdleCheckInterval)))
+	}
+
+	return allErrs
+}
+
+// validateProbesSpec requires the port for probes of games the operator does not know the port of.
+func validateProbesSpec(spec *gamesv1alpha1.GameServerSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	probeType, port := specs.GameServerProbe(&gamesv1alpha1.GameServer{Spec: *spec})
+	switch probeType {
+	case gamesv1alpha1.ProbeTypeTCP, gamesv1alpha1.ProbeTypeA2S, gamesv1alpha1.ProbeTypeMinecraft:
+		if port == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("port"),
+				fmt.Sprintf("the port of %s to probe with %s is not known", spec.GameName, probeType)))
+		}
+	}
+
+	if timeout := spec.Probes.StartupTimeout; timeout != nil && timeout.Duration < minStartupTimeout {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("startupTimeout"), timeout.Duration.String(),
+			fmt.Sprintf("must be at least %s", minStartupTimeout)))
+	}
+
+	return allErrs
+}
+
+func validateServiceSpec(svc *gamesv1alpha1.ServiceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	portsPath := fldPath.Child("ports")
+
+	names := map[string]bool{}
+	ports := map[protocolPort]bool{}
+	nodePorts := map[protocolPort]bool{}
+
+	// Container ports are only created for ports with a numeric target port and are named after
+	// the service port, so a named target port can only refer to one of those.
+	containerPortNames := map[string]bool{}
+	for _, port := range svc.Ports {
+		if port.TargetPort.Type == intstr.Int && port.Name != "" {
+			containerPortNames[port.Name] = true
+		}
+	}
+
+	for i, port := range svc.Ports {
+		idxPath := portsPath.Index(i)
+
+		if port.Name == "" && len(svc.Ports) > 1 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "must be set when exposing more than one port"))
+		} else if port.Name != "" {
+			if names[port.Name] {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), port.Name))
+			}
+			names[port.Name] = true
+		}
+
+		key := protocolPort{protocol: port.Protocol, port: port.Port}
+		if ports[key] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("port"), fmt.Sprintf("%d/%s", port.Port, port.Protocol)))
+		}
+		ports[key] = true
+
+		if port.NodePort != 0 {
+			nodePortPath := idxPath.Child("nodePort")
+			switch {
+			case svc.Type != corev1.ServiceTypeNodePort && svc.Type != corev1.ServiceTypeLoadBalancer:
+				allErrs = append(allErrs, field.Forbidden(nodePortPath,
+					fmt.Sprintf("may only be set when service type is %s or %s",
+						corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)))
+			case port.NodePort < nodePortMin || port.NodePort > nodePortMax:
+				allErrs = append(allErrs, field.Invalid(nodePortPath, port.NodePort,
+					fmt.Sprintf("must be in the range %d-%d", nodePortMin, nodePortMax)))
+			}
+
+			nodePortKey := protocolPort{protocol: port.Protocol, port: port.NodePort}
+			if nodePorts[nodePortKey] {
+				allErrs = append(allErrs, field.Duplicate(nodePortPath, fmt.Sprintf("%d/%s", port.NodePort, port.Protocol)))
+			}
+			nodePorts[nodePortKey] = true
+		}
+
+		if port.TargetPort.Type == intstr.String && port.TargetPort.StrVal != "" &&
+			!containerPortNames[port.TargetPort.StrVal] {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("targetPort"), port.TargetPort.StrVal,
+				"must be the name of a port with a numeric targetPort, as only those are exposed as container ports"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateGameServerSpecUpdate(oldSpec, newSpec *gamesv1alpha1.GameServerSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	storagePath := field.NewPath("spec", "storage")
+
+	oldStorage, newStorage := oldSpec.Storage, newSpec.Storage
+	if oldStorage == nil {
+		oldStorage = &gamesv1alpha1.StorageSpec{}
+	}
+	if newStorage == nil {
+		newStorage = &gamesv1alpha1.StorageSpec{}
+	}
+
+	if enabled(oldStorage.Enabled) != enabled(newStorage.Enabled) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("enabled"), "field is immutable"))
+	}
+
+	if ptrValue(oldStorage.StorageClassName) != ptrValue(newStorage.StorageClassName) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("storageClassName"), "field is immutable"))
+	}
+
+	if oldStorage.FromSnapshot != newStorage.FromSnapshot {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("fromSnapshot"), "field is immutable"))
+	}
+
+	oldSize, oldErr := storageSize(oldStorage)
+	newSize, newErr := storageSize(newStorage)
+	if newErr != nil {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("size"), newStorage.Size, newErr.Error()))
+	} else if oldErr == nil && newSize.Cmp(oldSize) < 0 {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("size"),
+			fmt.Sprintf("may not be decreased from %s to %s", oldSize.String(), newSize.String())))
+	}
+
+	return allErrs
+}
+
+func storageSize(storage *gamesv1alpha1.StorageSpec) (resource.Quantity, error) {
+	size := storage.Size
+	if size == "" {
+		size = defaultStorageSize
+	}
+	return resource.ParseQuantity(size)
+}
+
+func enabled(b *bool) bool {
+	return b == nil || *b
+}
+
+func ptrValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
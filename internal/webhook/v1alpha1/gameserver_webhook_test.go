@@ -0,0 +1,512 @@
+/*
+The MIT License (MIT)
+
+Copyright © 2025 Igor de Beijer
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+var _ = Describe("GameServer Webhook", func() {
+	var (
+		obj       *gamesv1alpha1.GameServer
+		oldObj    *gamesv1alpha1.GameServer
+		validator GameServerCustomValidator
+		defaulter GameServerCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &gamesv1alpha1.GameServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-gameserver", Namespace: "default"},
+			Spec: gamesv1alpha1.GameServerSpec{
+				GameName: "mc",
+				Replicas: 1,
+				Service: &gamesv1alpha1.ServiceSpec{
+					Type: corev1.ServiceTypeNodePort,
+					Ports: []gamesv1alpha1.ServicePort{
+						{Name: "game", Port: 25565, TargetPort: intstr.FromInt32(25565), Protocol: corev1.ProtocolTCP},
+						{Name: "query", Port: 25565, TargetPort: intstr.FromString("game"), Protocol: corev1.ProtocolUDP},
+					},
+				},
+				Storage: &gamesv1alpha1.StorageSpec{Size: "20Gi"},
+			},
+		}
+		oldObj = obj.DeepCopy()
+		validator = GameServerCustomValidator{}
+		defaulter = GameServerCustomDefaulter{}
+	})
+
+	expectInvalid := func(err error, fieldPath string) {
+		GinkgoHelper()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+		Expect(err.Error()).To(ContainSubstring(fieldPath))
+	}
+
+	Context("When defaulting a GameServer", func() {
+		It("fills the ports, resources and storage size of the game", func() {
+			obj.Spec.GameName = "rust"
+			obj.Spec.Service = &gamesv1alpha1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}
+			obj.Spec.Storage = nil
+
+			Expect(defaulter.Default(context.Background(), obj)).To(Succeed())
+			Expect(obj.Spec.Service.Ports).To(Equal([]gamesv1alpha1.ServicePort{
+				{Name: "game", Port: 28015, Protocol: corev1.ProtocolUDP},
+				{Name: "rcon", Port: 28016, Protocol: corev1.ProtocolTCP},
+			}))
+			Expect(obj.Spec.Resources).NotTo(BeNil())
+			Expect(obj.Spec.Resources.Requests.Cpu().String()).To(Equal("2"))
+			Expect(obj.Spec.Resources.Requests.Memory().String()).To(Equal("8Gi"))
+			Expect(obj.Spec.Storage).To(Equal(&gamesv1alpha1.StorageSpec{Size: "20Gi"}))
+
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("never overrides explicit values", func() {
+			obj.Spec.Resources = &corev1.ResourceRequirements{}
+			expected := obj.Spec.DeepCopy()
+
+			Expect(defaulter.Default(context.Background(), obj)).To(Succeed())
+			Expect(obj.Spec).To(Equal(*expected))
+		})
+
+		It("does not create a service", func() {
+			obj.Spec.Service = nil
+
+			Expect(defaulter.Default(context.Background(), obj)).To(Succeed())
+			Expect(obj.Spec.Service).To(BeNil())
+		})
+
+		It("leaves games without defaults untouched", func() {
+			obj.Spec.GameName = "jk2"
+			obj.Spec.Service.Ports = nil
+			obj.Spec.Storage = nil
+			expected := obj.Spec.DeepCopy()
+
+			Expect(defaulter.Default(context.Background(), obj)).To(Succeed())
+			Expect(obj.Spec).To(Equal(*expected))
+		})
+	})
+
+	Context("When creating a GameServer", func() {
+		It("admits a valid GameServer", func() {
+			warnings, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("warns about the deprecated replicas field", func() {
+			obj.Spec.Replicas = 0
+			warnings, err := validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("spec.state: Stopped")))
+
+			obj.Spec.Replicas = 3
+			warnings, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("will not be honored")))
+		})
+
+		It("rejects a game that is not supported by LinuxGSM", func() {
+			obj.Spec.GameName = "minecraft"
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameName")
+		})
+
+		It("requires port names when exposing more than one port", func() {
+			obj.Spec.Service.Ports[1].Name = ""
+			obj.Spec.Service.Ports[1].TargetPort = intstr.FromInt32(25565)
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[1].name")
+		})
+
+		It("rejects duplicate port names", func() {
+			obj.Spec.Service.Ports[1].Name = "game"
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[1].name")
+		})
+
+		It("rejects duplicate ports with the same protocol", func() {
+			obj.Spec.Service.Ports[1].Protocol = corev1.ProtocolTCP
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[1].port")
+		})
+
+		It("rejects node ports outside of the node port range", func() {
+			obj.Spec.Service.Ports[0].NodePort = 25565
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[0].nodePort")
+		})
+
+		It("rejects node ports for ClusterIP services", func() {
+			obj.Spec.Service.Type = corev1.ServiceTypeClusterIP
+			obj.Spec.Service.Ports[0].NodePort = 30565
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[0].nodePort")
+		})
+
+		It("rejects backups without persistent storage", func() {
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			obj.Spec.Backup = &gamesv1alpha1.BackupSpec{
+				Schedule: "0 4 * * *",
+				Target: gamesv1alpha1.BackupTarget{
+					PersistentVolumeClaim: &gamesv1alpha1.PersistentVolumeClaimBackupTarget{ClaimName: "backups"},
+				},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.backup")
+		})
+
+		It("rejects invalid backup schedules and time zones", func() {
+			obj.Spec.Backup = &gamesv1alpha1.BackupSpec{
+				Schedule: "every night",
+				TimeZone: ptr.To("Mars/Olympus_Mons"),
+				Mode:     gamesv1alpha1.BackupModeSnapshot,
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.backup.schedule")
+			expectInvalid(err, "spec.backup.timeZone")
+		})
+
+		It("rejects invalid update schedules", func() {
+			obj.Spec.Updates = &gamesv1alpha1.UpdatesSpec{Schedule: "@every 5 minutes"}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.updates.schedule")
+		})
+
+		It("rejects invalid schedule windows and time zones", func() {
+			obj.Spec.Schedule = &gamesv1alpha1.ScheduleSpec{
+				Windows: []gamesv1alpha1.ScheduleWindow{
+					{Start: "0 18 * * 1-5", Stop: "0 23 * * 1-5"},
+					{Start: "0 10 * * 6,0", Stop: "sunday night"},
+				},
+				TimeZone: ptr.To("Mars/Olympus_Mons"),
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.schedule.windows[1].stop")
+			expectInvalid(err, "spec.schedule.timeZone")
+		})
+
+		It("requires mods to refer to a file the way their source expects", func() {
+			obj.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Mods: []gamesv1alpha1.MinecraftMod{
+					{Source: gamesv1alpha1.MinecraftModSourceModrinth},
+					{Source: gamesv1alpha1.MinecraftModSourceCurseForge, Project: "jei"},
+					{Source: gamesv1alpha1.MinecraftModSourceURL, URL: "ftp://example.com/mod.jar", Project: "mod"},
+				},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.mods[0].project")
+			expectInvalid(err, "spec.gameConfigs.minecraft.mods[1].project")
+			expectInvalid(err, "spec.gameConfigs.minecraft.mods[2].url")
+			expectInvalid(err, "spec.gameConfigs.minecraft.mods[2].project")
+		})
+
+		It("rejects mods without persistent storage", func() {
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			obj.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Mods: []gamesv1alpha1.MinecraftMod{{Source: gamesv1alpha1.MinecraftModSourceModrinth, Project: "sodium"}},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.mods")
+		})
+
+		It("checks the loader and modpack of the Minecraft server", func() {
+			obj.Spec.Updates = &gamesv1alpha1.UpdatesSpec{Schedule: "@daily"}
+			obj.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Loader:  gamesv1alpha1.MinecraftLoaderForge,
+				Modpack: &gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceCurseForge, Project: "atm10"},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.modpack.project")
+			expectInvalid(err, "spec.updates")
+
+			obj.Spec.Updates = nil
+			obj.Spec.GameConfigs.Minecraft.Modpack = nil
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.version")
+
+			obj.Spec.GameConfigs.Minecraft.Version = "1.21.1"
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.loader")
+		})
+
+		It("rejects server.properties the operator sets itself or cannot write", func() {
+			obj.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Properties: &gamesv1alpha1.MinecraftProperties{
+					MOTD: ptr.To("first\nsecond"),
+					Extra: map[string]string{
+						"enable-command-block": "true",
+						"max-players":          "10",
+						"rcon.password":        "secret",
+						"Level Name":           "world",
+						"level-type":           "flat\nworld",
+					},
+				},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties.motd")
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties.extra[max-players]")
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties.extra[rcon.password]")
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties.extra[Level Name]")
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties.extra[level-type]")
+			Expect(err.Error()).NotTo(ContainSubstring("enable-command-block"))
+		})
+
+		It("rejects properties and players without persistent storage", func() {
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			obj.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Properties: &gamesv1alpha1.MinecraftProperties{Difficulty: "hard"},
+				Whitelist:  []string{"Steve"},
+				Ops:        []string{"Steve"},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.gameConfigs.minecraft.properties")
+			expectInvalid(err, "spec.gameConfigs.minecraft.whitelist")
+			expectInvalid(err, "spec.gameConfigs.minecraft.ops")
+		})
+
+		It("checks LinuxGSM settings and config files", func() {
+			obj.Spec.LinuxGSM = &gamesv1alpha1.LinuxGSMSpec{Config: map[string]string{
+				"maxplayers":      "10",
+				"server name":     "Kube",
+				"startparameters": "-batchmode",
+			}}
+			obj.Spec.ConfigFiles = []gamesv1alpha1.ConfigFile{
+				{Path: "serverfiles/server.cfg"},
+				{
+					Path:            "serverfiles/../../etc/passwd",
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "passwd"},
+					SecretKeyRef:    &corev1.SecretKeySelector{Key: "passwd"},
+				},
+				{Path: "serverfiles/cfg/", SecretKeyRef: &corev1.SecretKeySelector{Key: "cfg"}},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.linuxgsm.config[server name]")
+			Expect(err.Error()).NotTo(ContainSubstring("config[maxplayers]"))
+			expectInvalid(err, "spec.configFiles[0]")
+			expectInvalid(err, "spec.configFiles[1]")
+			expectInvalid(err, "spec.configFiles[1].path")
+			expectInvalid(err, "spec.configFiles[2].path")
+
+			obj.Spec.ConfigFiles = obj.Spec.ConfigFiles[2:]
+			obj.Spec.ConfigFiles[0].Path = "serverfiles/cfg/server.cfg"
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.configFiles")
+			Expect(err.Error()).NotTo(ContainSubstring("spec.configFiles[0]"))
+		})
+
+		It("requires the RCON port of games without known RCON settings", func() {
+			obj.Spec.GameName = "vh"
+			obj.Spec.Service = nil
+			obj.Spec.RCON = &gamesv1alpha1.RCONSpec{
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rcon"},
+					Key:                  "password",
+				},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.rcon.port")
+
+			obj.Spec.RCON.Port = 2459
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("requires exactly one RCON password", func() {
+			obj.Spec.RCON = &gamesv1alpha1.RCONSpec{}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.rcon.passwordSecretRef")
+
+			obj.Spec.Secrets = &gamesv1alpha1.SecretsSpec{RCONPassword: &gamesv1alpha1.SecretSource{Generate: true}}
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.RCON.PasswordSecretRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "rcon"},
+				Key:                  "password",
+			}
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.secrets.rconPassword")
+
+			obj.Spec.RCON = nil
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.secrets.rconPassword")
+		})
+
+		It("requires a single source for each password", func() {
+			obj.Spec.Secrets = &gamesv1alpha1.SecretsSpec{
+				ServerPassword: &gamesv1alpha1.SecretSource{},
+				AdminPassword: &gamesv1alpha1.SecretSource{
+					SecretKeyRef: &corev1.SecretKeySelector{Key: "password"},
+					Generate:     true,
+				},
+				GSLT: &corev1.SecretKeySelector{Key: "token"},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.secrets.serverPassword")
+			expectInvalid(err, "spec.secrets.adminPassword.generate")
+			expectInvalid(err, "spec.secrets.gslt.name")
+
+			obj.Spec.Secrets.ServerPassword.Generate = true
+			obj.Spec.Secrets.AdminPassword.Generate = false
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.secrets.adminPassword.secretKeyRef.name")
+			Expect(err.Error()).NotTo(ContainSubstring("spec.secrets.serverPassword"))
+		})
+
+		It("rejects shutdown warnings outside of the allowed range", func() {
+			obj.Spec.Shutdown = &gamesv1alpha1.ShutdownSpec{Warnings: []metav1.Duration{
+				{Duration: 5 * time.Minute},
+				{Duration: 2 * time.Hour},
+			}}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.shutdown.warnings[1]")
+		})
+
+		It("requires the port of games probed on an unknown port", func() {
+			obj.Spec.GameName = "jk2"
+			obj.Spec.Service = nil
+			obj.Spec.Probes = &gamesv1alpha1.ProbesSpec{
+				Type:           gamesv1alpha1.ProbeTypeA2S,
+				StartupTimeout: &metav1.Duration{Duration: 30 * time.Second},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.probes.port")
+			expectInvalid(err, "spec.probes.startupTimeout")
+
+			obj.Spec.Probes = &gamesv1alpha1.ProbesSpec{Type: gamesv1alpha1.ProbeTypeA2S, Port: 28070}
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects idle timeouts for games whose players cannot be counted", func() {
+			obj.Spec.GameName = "terraria"
+			obj.Spec.Idle = &gamesv1alpha1.IdleSpec{
+				Timeout:       &metav1.Duration{Duration: 30 * time.Second},
+				CheckInterval: &metav1.Duration{Duration: time.Minute},
+			}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.idle: Forbidden")
+			expectInvalid(err, "spec.idle.timeout")
+			expectInvalid(err, "spec.idle.checkInterval")
+
+			obj.Spec.GameName = "mc"
+			obj.Spec.Idle = &gamesv1alpha1.IdleSpec{}
+			_, err = validator.ValidateCreate(context.Background(), obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("requires a Service to wake game servers up through", func() {
+			obj.Spec.Service = nil
+			obj.Spec.Idle = &gamesv1alpha1.IdleSpec{WakeOnConnect: true}
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.idle.wakeOnConnect")
+		})
+
+		It("rejects named target ports that do not refer to a container port", func() {
+			obj.Spec.Service.Ports[1].TargetPort = intstr.FromString("rcon")
+			_, err := validator.ValidateCreate(context.Background(), obj)
+			expectInvalid(err, "spec.service.ports[1].targetPort")
+		})
+	})
+
+	Context("When updating a GameServer", func() {
+		It("admits growing the volume", func() {
+			obj.Spec.Storage.Size = "30Gi"
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects shrinking the volume", func() {
+			obj.Spec.Storage.Size = "10Gi"
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.storage.size")
+		})
+
+		It("compares against the default size when the size was not set", func() {
+			oldObj.Spec.Storage.Size = ""
+			obj.Spec.Storage.Size = "5Gi"
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.storage.size")
+		})
+
+		It("rejects toggling persistent storage", func() {
+			obj.Spec.Storage.Enabled = ptr.To(false)
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.storage.enabled")
+		})
+
+		It("rejects changing the storage class", func() {
+			obj.Spec.Storage.StorageClassName = ptr.To("fast")
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.storage.storageClassName")
+		})
+
+		It("rejects changing the snapshot the volume is created from", func() {
+			obj.Spec.Storage.FromSnapshot = "example-20260101040000"
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.storage.fromSnapshot")
+		})
+
+		It("admits metadata changes of a GameServer whose spec is no longer valid", func() {
+			oldObj.Spec.GameName = "unknown"
+			obj = oldObj.DeepCopy()
+			obj.Finalizers = []string{"gameserver.games.idebeijer.github.io/finalizer"}
+			obj.Annotations = map[string]string{gamesv1alpha1.AnnotationWakeRequestedAt: time.Now().Format(time.RFC3339)}
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("admits any update of a GameServer that is being deleted", func() {
+			obj.Spec.GameName = "unknown"
+			obj.Spec.Storage.Size = "10Gi"
+			obj.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("still validates the new spec when it changes", func() {
+			obj.Spec.GameName = "unknown"
+			_, err := validator.ValidateUpdate(context.Background(), oldObj, obj)
+			expectInvalid(err, "spec.gameName")
+		})
+	})
+})
@@ -0,0 +1,115 @@
+package linuxgsm
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Port is a port a game server listens on by default.
+type Port struct {
+	Name     string
+	Port     int32
+	Protocol corev1.Protocol
+}
+
+// Defaults are the recommended settings to run a game server with.
+type Defaults struct {
+	// Ports are the ports the game server listens on with its default LinuxGSM configuration.
+	Ports []Port
+	// CPU and Memory are the recommended resource requests for the game server container.
+	CPU    resource.Quantity
+	Memory resource.Quantity
+	// StorageSize is the recommended size of the data volume, including the game files installed by LinuxGSM.
+	StorageSize string
+}
+
+// catalog holds the defaults of commonly used games, keyed by LinuxGSM shortname.
+// Games that are not listed here are still supported, but have to be configured explicitly.
+var catalog = map[string]Defaults{
+	"ark": defaults("2", "8Gi", "40Gi",
+		Port{Name: "game", Port: 7777, Protocol: corev1.ProtocolUDP},
+		Port{Name: "raw", Port: 7778, Protocol: corev1.ProtocolUDP},
+		Port{Name: "query", Port: 27015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27020, Protocol: corev1.ProtocolTCP},
+	),
+	"cs2": defaults("2", "4Gi", "60Gi",
+		Port{Name: "game", Port: 27015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27015, Protocol: corev1.ProtocolTCP},
+	),
+	"csgo": defaults("2", "2Gi", "40Gi",
+		Port{Name: "game", Port: 27015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27015, Protocol: corev1.ProtocolTCP},
+	),
+	"fctr": defaults("1", "2Gi", "5Gi",
+		Port{Name: "game", Port: 34197, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27015, Protocol: corev1.ProtocolTCP},
+	),
+	"gmod": defaults("2", "2Gi", "20Gi",
+		Port{Name: "game", Port: 27015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27015, Protocol: corev1.ProtocolTCP},
+	),
+	"mc": defaults("1", "2Gi", "10Gi",
+		Port{Name: "game", Port: 25565, Protocol: corev1.ProtocolTCP},
+	),
+	"mcb": defaults("1", "1Gi", "5Gi",
+		Port{Name: "game", Port: 19132, Protocol: corev1.ProtocolUDP},
+	),
+	"pw": defaults("4", "16Gi", "20Gi",
+		Port{Name: "game", Port: 8211, Protocol: corev1.ProtocolUDP},
+		Port{Name: "query", Port: 27015, Protocol: corev1.ProtocolUDP},
+	),
+	"pz": defaults("2", "4Gi", "10Gi",
+		Port{Name: "game", Port: 16261, Protocol: corev1.ProtocolUDP},
+		Port{Name: "direct", Port: 16262, Protocol: corev1.ProtocolUDP},
+	),
+	"rust": defaults("2", "8Gi", "20Gi",
+		Port{Name: "game", Port: 28015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 28016, Protocol: corev1.ProtocolTCP},
+	),
+	"sdtd": defaults("2", "8Gi", "20Gi",
+		Port{Name: "game", Port: 26900, Protocol: corev1.ProtocolTCP},
+		Port{Name: "game-udp", Port: 26900, Protocol: corev1.ProtocolUDP},
+		Port{Name: "net-1", Port: 26901, Protocol: corev1.ProtocolUDP},
+		Port{Name: "net-2", Port: 26902, Protocol: corev1.ProtocolUDP},
+	),
+	"sf": defaults("4", "12Gi", "20Gi",
+		Port{Name: "game", Port: 7777, Protocol: corev1.ProtocolUDP},
+		Port{Name: "api", Port: 7777, Protocol: corev1.ProtocolTCP},
+	),
+	"terraria": defaults("1", "1Gi", "5Gi",
+		Port{Name: "game", Port: 7777, Protocol: corev1.ProtocolTCP},
+	),
+	"tf2": defaults("2", "2Gi", "20Gi",
+		Port{Name: "game", Port: 27015, Protocol: corev1.ProtocolUDP},
+		Port{Name: "rcon", Port: 27015, Protocol: corev1.ProtocolTCP},
+	),
+	"ts3": defaults("500m", "512Mi", "5Gi",
+		Port{Name: "voice", Port: 9987, Protocol: corev1.ProtocolUDP},
+		Port{Name: "query", Port: 10011, Protocol: corev1.ProtocolTCP},
+		Port{Name: "files", Port: 30033, Protocol: corev1.ProtocolTCP},
+	),
+	"vh": defaults("2", "4Gi", "10Gi",
+		Port{Name: "game", Port: 2456, Protocol: corev1.ProtocolUDP},
+		Port{Name: "query", Port: 2457, Protocol: corev1.ProtocolUDP},
+		Port{Name: "game-2", Port: 2458, Protocol: corev1.ProtocolUDP},
+	),
+	"vints": defaults("2", "4Gi", "10Gi",
+		Port{Name: "game", Port: 42420, Protocol: corev1.ProtocolTCP},
+		Port{Name: "game-udp", Port: 42420, Protocol: corev1.ProtocolUDP},
+	),
+}
+
+// LookupDefaults returns the recommended settings for the game with the given LinuxGSM shortname.
+func LookupDefaults(shortName string) (Defaults, bool) {
+	d, ok := catalog[shortName]
+	return d, ok
+}
+
+func defaults(cpu, memory, storageSize string, ports ...Port) Defaults {
+	return Defaults{
+		Ports:       ports,
+		CPU:         resource.MustParse(cpu),
+		Memory:      resource.MustParse(memory),
+		StorageSize: storageSize,
+	}
+}
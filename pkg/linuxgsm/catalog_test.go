@@ -0,0 +1,99 @@
+package linuxgsm
+
+import (
+	"fmt"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+)
+
+var _ = Describe("Game catalog", func() {
+	It("returns the defaults of known games", func() {
+		defaults, ok := LookupDefaults("rust")
+		Expect(ok).To(BeTrue())
+		Expect(defaults.Ports).To(ContainElement(Port{Name: "rcon", Port: 28016, Protocol: "TCP"}))
+
+		_, ok = LookupDefaults("unknown")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("only contains valid defaults for games supported by LinuxGSM", func() {
+		storageSize := regexp.MustCompile(`^\d+Gi$`)
+
+		for shortName, defaults := range catalog {
+			_, ok := LookupGame(shortName)
+			Expect(ok).To(BeTrue(), "%s is not in the LinuxGSM server list", shortName)
+			Expect(defaults.StorageSize).To(MatchRegexp(storageSize.String()), shortName)
+			Expect(defaults.Ports).NotTo(BeEmpty(), shortName)
+
+			names := map[string]bool{}
+			ports := map[string]bool{}
+			for _, port := range defaults.Ports {
+				Expect(names).NotTo(HaveKey(port.Name), "%s: duplicate port name %s", shortName, port.Name)
+				names[port.Name] = true
+
+				key := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+				Expect(ports).NotTo(HaveKey(key), "%s: duplicate port %s", shortName, key)
+				ports[key] = true
+			}
+		}
+	})
+
+	It("only describes remote consoles of games supported by LinuxGSM", func() {
+		for shortName, r := range rconCatalog {
+			_, ok := LookupGame(shortName)
+			Expect(ok).To(BeTrue(), "%s is not in the LinuxGSM server list", shortName)
+			Expect(r.Port).To(BeNumerically(">", 0), shortName)
+			Expect(r.PasswordSetting).NotTo(BeEmpty(), shortName)
+		}
+
+		_, ok := LookupRCON("vh")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("only describes secrets of games supported by LinuxGSM", func() {
+		for shortName, s := range secretsCatalog {
+			_, ok := LookupGame(shortName)
+			Expect(ok).To(BeTrue(), "%s is not in the LinuxGSM server list", shortName)
+			for _, setting := range []SecretSetting{s.ServerPassword, s.AdminPassword, s.GSLT} {
+				if setting.Name != "" {
+					Expect(setting.ConfigFormat).NotTo(BeEmpty(), shortName)
+				}
+			}
+		}
+
+		_, ok := LookupSecrets("mc")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("only describes consoles of games supported by LinuxGSM", func() {
+		for shortName, c := range consoleCatalog {
+			_, ok := LookupGame(shortName)
+			Expect(ok).To(BeTrue(), "%s is not in the LinuxGSM server list", shortName)
+			Expect(c.AnnounceCommand).To(ContainSubstring("%s"), shortName)
+		}
+
+		for shortName := range rconCatalog {
+			Expect(consoleCatalog).To(HaveKey(shortName), "%s has a remote console without console commands", shortName)
+		}
+	})
+
+	It("only queries games on a port of their defaults", func() {
+		transports := map[query.Protocol]corev1.Protocol{
+			query.ProtocolA2S:       corev1.ProtocolUDP,
+			query.ProtocolMinecraft: corev1.ProtocolTCP,
+		}
+		for shortName, q := range queryCatalog {
+			defaults, ok := LookupDefaults(shortName)
+			Expect(ok).To(BeTrue(), "%s has no defaults", shortName)
+			Expect(defaults.Ports).To(ContainElement(And(
+				HaveField("Name", q.PortName),
+				HaveField("Protocol", transports[q.Protocol]),
+			)), shortName)
+		}
+	})
+})
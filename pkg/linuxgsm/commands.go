@@ -0,0 +1,100 @@
+package linuxgsm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scriptDir is the directory of the LinuxGSM script in the gameservermanagers/gameserver images.
+const scriptDir = "/app"
+
+// LinuxGSM commands run by the operator.
+const (
+	CommandAutoInstall = "auto-install"
+	CommandUpdate      = "update"
+	CommandCheckUpdate = "check-update"
+	CommandDetails     = "details"
+	CommandSend        = "send"
+	CommandMonitor     = "monitor"
+)
+
+var (
+	ansiEscape  = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	localBuild  = regexp.MustCompile(`(?m)Local build:\s*(\S+)`)
+	remoteBuild = regexp.MustCompile(`(?m)Remote build:\s*(\S+)`)
+	players     = regexp.MustCompile(`(?m)^\s*Players:\s*(\d+)`)
+)
+
+// ScriptPath returns the path of the LinuxGSM script of the game in its container, e.g. '/app/mcserver'.
+func (g Game) ScriptPath() string {
+	return scriptDir + "/" + g.ServerName
+}
+
+// Command returns the command line running a LinuxGSM command with its arguments for the game.
+func (g Game) Command(command string, args ...string) []string {
+	return append([]string{g.ScriptPath(), command}, args...)
+}
+
+// UpdateCheck is the outcome of the update or check-update command.
+type UpdateCheck struct {
+	// LocalBuild is the build installed before the command ran, if reported.
+	LocalBuild string
+	// RemoteBuild is the latest available build, if reported.
+	RemoteBuild string
+	// UpdateAvailable reports whether the remote build differs from the local one.
+	UpdateAvailable bool
+}
+
+// ParseUpdateOutput parses the output of the update or check-update command. LinuxGSM prints the local
+// and remote build for most games, others only report whether an update is available.
+func ParseUpdateOutput(output string) UpdateCheck {
+	output = CleanOutput(output)
+
+	check := UpdateCheck{
+		LocalBuild:  firstSubmatch(localBuild, output),
+		RemoteBuild: firstSubmatch(remoteBuild, output),
+	}
+	if check.LocalBuild != "" && check.RemoteBuild != "" {
+		check.UpdateAvailable = check.LocalBuild != check.RemoteBuild
+	} else {
+		check.UpdateAvailable = strings.Contains(output, "Update available") &&
+			!strings.Contains(output, "No update available")
+	}
+	return check
+}
+
+// ParsePlayers parses the number of connected players from the output of the details command.
+// LinuxGSM only reports it when it can query the game server, so it is not known for every game.
+func ParsePlayers(output string) (int, bool) {
+	match := firstSubmatch(players, CleanOutput(output))
+	if match == "" {
+		return 0, false
+	}
+	count, err := strconv.Atoi(match)
+	if err != nil {
+		return 0, false
+	}
+	return count, true
+}
+
+// CleanOutput removes the colors and progress lines LinuxGSM writes for interactive terminals.
+func CleanOutput(output string) string {
+	output = ansiEscape.ReplaceAllString(output, "")
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		// Progress is written over the same line, only its final state is kept.
+		if idx := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstSubmatch(re *regexp.Regexp, s string) string {
+	if match := re.FindStringSubmatch(s); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package linuxgsm
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LinuxGSM commands", func() {
+	It("runs the script of the game", func() {
+		game, _ := LookupGame("mc")
+		Expect(game.Command(CommandUpdate)).To(Equal([]string{"/app/mcserver", "update"}))
+		Expect(game.Command("send", "say hello")).To(Equal([]string{"/app/mcserver", "send", "say hello"}))
+	})
+
+	It("parses the builds reported by an update check", func() {
+		output := "[ .... ] Checking for update: SteamCMD\r[  OK  ] Checking for update: SteamCMD\n" +
+			"Update available\n" +
+			"* Local build: \x1b[31m1234\x1b[0m\n" +
+			"* Remote build: \x1b[32m1240\x1b[0m\n"
+
+		Expect(ParseUpdateOutput(output)).To(Equal(UpdateCheck{
+			LocalBuild:      "1234",
+			RemoteBuild:     "1240",
+			UpdateAvailable: true,
+		}))
+		Expect(ParseUpdateOutput("* Local build: 1240\n* Remote build: 1240\nNo update available\n").
+			UpdateAvailable).To(BeFalse())
+	})
+
+	It("falls back to the update message when no builds are reported", func() {
+		Expect(ParseUpdateOutput("Update available\n").UpdateAvailable).To(BeTrue())
+		Expect(ParseUpdateOutput("No update available\n").UpdateAvailable).To(BeFalse())
+	})
+
+	It("parses the connected players from the details", func() {
+		count, ok := ParsePlayers("Server name:    example\n\x1b[94mPlayers:\t\x1b[0m3 / 20\nMaximum players: 20\n")
+		Expect(ok).To(BeTrue())
+		Expect(count).To(Equal(3))
+
+		_, ok = ParsePlayers("Maximum players: 20\n")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keeps only the final state of progress lines", func() {
+		Expect(CleanOutput("[ .... ] Starting\r[  OK  ] Starting\r\nDone\n")).To(Equal("[  OK  ] Starting\nDone\n"))
+	})
+})
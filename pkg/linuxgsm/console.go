@@ -0,0 +1,32 @@
+package linuxgsm
+
+// Console holds the console commands the operator sends to a game, over RCON or through the LinuxGSM console.
+type Console struct {
+	// SaveCommand saves the world, or is empty when the game has no such command.
+	SaveCommand string
+	// AnnounceCommand is the format of the command sending a message to all players.
+	AnnounceCommand string
+}
+
+// consoleCatalog holds the console commands of commonly used games, keyed by LinuxGSM shortname.
+// Source engine games save on their own.
+var consoleCatalog = map[string]Console{
+	"ark":  {SaveCommand: "saveworld", AnnounceCommand: "broadcast %s"},
+	"cs2":  {AnnounceCommand: "say %s"},
+	"csgo": {AnnounceCommand: "say %s"},
+	// Factorio sends text that is not a command to the chat.
+	"fctr":     {SaveCommand: "/server-save", AnnounceCommand: "%s"},
+	"gmod":     {AnnounceCommand: "say %s"},
+	"mc":       {SaveCommand: "save-all flush", AnnounceCommand: "say %s"},
+	"pz":       {SaveCommand: "save", AnnounceCommand: `servermsg "%s"`},
+	"rust":     {SaveCommand: "server.save", AnnounceCommand: "say %s"},
+	"sdtd":     {SaveCommand: "saveworld", AnnounceCommand: `say "%s"`},
+	"terraria": {SaveCommand: "save", AnnounceCommand: "say %s"},
+	"tf2":      {AnnounceCommand: "say %s"},
+}
+
+// LookupConsole returns the console commands of the game with the given LinuxGSM shortname.
+func LookupConsole(shortName string) (Console, bool) {
+	c, ok := consoleCatalog[shortName]
+	return c, ok
+}
@@ -0,0 +1,28 @@
+package linuxgsm
+
+import "github.com/idebeijer/gameserver-operator/pkg/query"
+
+// Query describes how a game answers server queries.
+type Query struct {
+	// Protocol is the query protocol the game speaks.
+	Protocol query.Protocol
+	// PortName is the name of the port in the game defaults the queries are answered on.
+	PortName string
+}
+
+// queryCatalog holds the query protocols of the games the operator knows how to query, keyed by LinuxGSM shortname.
+var queryCatalog = map[string]Query{
+	"ark":  {Protocol: query.ProtocolA2S, PortName: "query"},
+	"cs2":  {Protocol: query.ProtocolA2S, PortName: "game"},
+	"csgo": {Protocol: query.ProtocolA2S, PortName: "game"},
+	"gmod": {Protocol: query.ProtocolA2S, PortName: "game"},
+	"mc":   {Protocol: query.ProtocolMinecraft, PortName: "game"},
+	"tf2":  {Protocol: query.ProtocolA2S, PortName: "game"},
+	"vh":   {Protocol: query.ProtocolA2S, PortName: "query"},
+}
+
+// LookupQuery returns how the game with the given LinuxGSM shortname answers server queries.
+func LookupQuery(shortName string) (Query, bool) {
+	q, ok := queryCatalog[shortName]
+	return q, ok
+}
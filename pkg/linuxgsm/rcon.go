@@ -0,0 +1,98 @@
+package linuxgsm
+
+import "github.com/idebeijer/gameserver-operator/pkg/rcon"
+
+// ConfigFormat is the syntax of a config file.
+type ConfigFormat string
+
+const (
+	// ConfigFormatLinuxGSM is a LinuxGSM config, which is sourced by bash: name="value".
+	ConfigFormatLinuxGSM ConfigFormat = "linuxgsm"
+	// ConfigFormatProperties is a Java properties file: name=value.
+	ConfigFormatProperties ConfigFormat = "properties"
+	// ConfigFormatSource is a Source engine config: name "value".
+	ConfigFormatSource ConfigFormat = "source"
+)
+
+// Setting is a setting in a config file.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// RCON describes the remote console of a game and the settings enabling it.
+type RCON struct {
+	// Protocol is the RCON protocol the game speaks.
+	Protocol rcon.Protocol
+	// Port is the port the remote console listens on by default.
+	Port int32
+
+	// ConfigFile is the path of the config file holding the RCON settings, relative to the directory the game
+	// is installed in. When empty, the settings are LinuxGSM settings in the config of the LinuxGSM instance.
+	ConfigFile string
+	// ConfigFormat is the syntax of the config file.
+	ConfigFormat ConfigFormat
+	// PortSetting and PasswordSetting are the names of the port and password settings. Games without a
+	// port setting listen on their game port.
+	PortSetting     string
+	PasswordSetting string
+	// Settings are the other settings enabling the remote console.
+	Settings []Setting
+}
+
+// rconCatalog holds the remote consoles of the games the operator knows how to enable RCON for,
+// keyed by LinuxGSM shortname.
+var rconCatalog = map[string]RCON{
+	"ark": {
+		Protocol:        rcon.ProtocolSource,
+		Port:            27020,
+		ConfigFormat:    ConfigFormatLinuxGSM,
+		PortSetting:     "rconport",
+		PasswordSetting: "adminpassword",
+	},
+	"cs2":  sourceEngineRCON("game/csgo/cfg/cs2server.cfg"),
+	"csgo": sourceEngineRCON("csgo/cfg/csgoserver.cfg"),
+	"fctr": {
+		Protocol:        rcon.ProtocolSource,
+		Port:            27015,
+		ConfigFormat:    ConfigFormatLinuxGSM,
+		PortSetting:     "rconport",
+		PasswordSetting: "rconpassword",
+	},
+	"gmod": sourceEngineRCON("garrysmod/cfg/gmodserver.cfg"),
+	"mc": {
+		Protocol:        rcon.ProtocolSource,
+		Port:            25575,
+		ConfigFile:      "server.properties",
+		ConfigFormat:    ConfigFormatProperties,
+		PortSetting:     "rcon.port",
+		PasswordSetting: "rcon.password",
+		Settings:        []Setting{{Name: "enable-rcon", Value: "true"}},
+	},
+	"rust": {
+		Protocol:        rcon.ProtocolWebRCON,
+		Port:            28016,
+		ConfigFormat:    ConfigFormatLinuxGSM,
+		PortSetting:     "rconport",
+		PasswordSetting: "rconpassword",
+		Settings:        []Setting{{Name: "rconweb", Value: "1"}},
+	},
+	"tf2": sourceEngineRCON("tf/cfg/tf2server.cfg"),
+}
+
+// LookupRCON returns the remote console of the game with the given LinuxGSM shortname.
+func LookupRCON(shortName string) (RCON, bool) {
+	r, ok := rconCatalog[shortName]
+	return r, ok
+}
+
+// sourceEngineRCON returns the remote console of a Source engine game, which listens on the game port.
+func sourceEngineRCON(configFile string) RCON {
+	return RCON{
+		Protocol:        rcon.ProtocolSource,
+		Port:            27015,
+		ConfigFile:      configFile,
+		ConfigFormat:    ConfigFormatSource,
+		PasswordSetting: "rcon_password",
+	}
+}
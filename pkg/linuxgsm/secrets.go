@@ -0,0 +1,62 @@
+package linuxgsm
+
+// SecretSetting is the setting of a game holding a password or token.
+type SecretSetting struct {
+	// ConfigFile is the path of the config file holding the setting, relative to the directory the game is
+	// installed in. When empty, the setting is a LinuxGSM setting in the config of the LinuxGSM instance.
+	ConfigFile string
+	// ConfigFormat is the syntax of the config file.
+	ConfigFormat ConfigFormat
+	// Name is the name of the setting.
+	Name string
+}
+
+// Secrets describes the settings of a game holding its passwords and tokens. Settings without a name are not
+// supported by the game. The RCON password is described by the RCON of the game.
+type Secrets struct {
+	ServerPassword SecretSetting
+	AdminPassword  SecretSetting
+	GSLT           SecretSetting
+}
+
+// secretsCatalog holds the passwords and tokens of the games the operator knows how to configure,
+// keyed by LinuxGSM shortname.
+var secretsCatalog = map[string]Secrets{
+	// ARK uses its admin password for RCON as well.
+	"ark": {
+		ServerPassword: linuxGSMSetting("serverpassword"),
+		AdminPassword:  linuxGSMSetting("adminpassword"),
+	},
+	"cs2":  sourceEngineSecrets("game/csgo/cfg/cs2server.cfg"),
+	"csgo": sourceEngineSecrets("csgo/cfg/csgoserver.cfg"),
+	"gmod": sourceEngineSecrets("garrysmod/cfg/gmodserver.cfg"),
+	"ins":  sourceEngineSecrets("insurgency/cfg/insserver.cfg"),
+	"l4d2": sourceEngineSecrets("left4dead2/cfg/l4d2server.cfg"),
+	"pz": {
+		AdminPassword: linuxGSMSetting("adminpassword"),
+	},
+	"tf2": sourceEngineSecrets("tf/cfg/tf2server.cfg"),
+	"vh": {
+		ServerPassword: linuxGSMSetting("serverpassword"),
+	},
+}
+
+// LookupSecrets returns the passwords and tokens of the game with the given LinuxGSM shortname.
+func LookupSecrets(shortName string) (Secrets, bool) {
+	s, ok := secretsCatalog[shortName]
+	return s, ok
+}
+
+// linuxGSMSetting returns a setting in the config of the LinuxGSM instance.
+func linuxGSMSetting(name string) SecretSetting {
+	return SecretSetting{ConfigFormat: ConfigFormatLinuxGSM, Name: name}
+}
+
+// sourceEngineSecrets returns the passwords and tokens of a Source engine game, which keeps its server password in
+// its server config and passes the login token of LinuxGSM on its command line.
+func sourceEngineSecrets(configFile string) Secrets {
+	return Secrets{
+		ServerPassword: SecretSetting{ConfigFile: configFile, ConfigFormat: ConfigFormatSource, Name: "sv_password"},
+		GSLT:           linuxGSMSetting("gslt"),
+	}
+}
@@ -0,0 +1,69 @@
+// Package linuxgsm contains data about the game servers supported by LinuxGSM.
+package linuxgsm
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// serverListCSV is a copy of LinuxGSM's lgsm/data/serverlist.csv, trimmed to the name columns.
+// Refresh it with 'make update-serverlist'.
+//
+//go:embed serverlist.csv
+var serverListCSV string
+
+// Game is a game server supported by LinuxGSM.
+type Game struct {
+	// ShortName is the LinuxGSM shortname, e.g. 'mc'. It is also the tag of the LinuxGSM container image.
+	ShortName string
+	// ServerName is the name of the LinuxGSM script, e.g. 'mcserver'.
+	ServerName string
+	// Name is the human readable name of the game, e.g. 'Minecraft'.
+	Name string
+}
+
+var games = mustParseServerList(serverListCSV)
+
+// LookupGame returns the game with the given LinuxGSM shortname.
+func LookupGame(shortName string) (Game, bool) {
+	game, ok := games[shortName]
+	return game, ok
+}
+
+func mustParseServerList(data string) map[string]Game {
+	parsed, err := parseServerList(data)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded LinuxGSM server list: %v", err))
+	}
+	return parsed
+}
+
+func parseServerList(data string) (map[string]Game, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make(map[string]Game, len(records))
+	for i, record := range records {
+		if i == 0 {
+			// header
+			continue
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 fields, got %d", i+1, len(record))
+		}
+		parsed[record[0]] = Game{
+			ShortName:  record[0],
+			ServerName: record[1],
+			Name:       record[2],
+		}
+	}
+
+	return parsed, nil
+}
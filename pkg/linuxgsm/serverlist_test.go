@@ -0,0 +1,27 @@
+package linuxgsm
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LinuxGSM server list", func() {
+	It("looks up games by their shortname", func() {
+		game, ok := LookupGame("mc")
+		Expect(ok).To(BeTrue())
+		Expect(game).To(Equal(Game{ShortName: "mc", ServerName: "mcserver", Name: "Minecraft"}))
+
+		_, ok = LookupGame("minecraft")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("skips the header of the server list", func() {
+		_, ok := LookupGame("shortname")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects lines with missing fields", func() {
+		_, err := parseServerList("shortname,gameservername,gamename\nmc,mcserver\n")
+		Expect(err).To(MatchError(ContainSubstring("line 2")))
+	})
+})
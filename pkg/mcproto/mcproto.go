@@ -0,0 +1,103 @@
+// Package mcproto implements the framing of the Minecraft Java Edition protocol, see
+// https://minecraft.wiki/w/Java_Edition_protocol. Only uncompressed packets are supported, as compression is
+// enabled only after logging in.
+package mcproto
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Packet ids in the handshaking, status and login states.
+const (
+	HandshakePacket       int32 = 0x00
+	StatusPacket          int32 = 0x00
+	PingPacket            int32 = 0x01
+	LoginStartPacket      int32 = 0x00
+	LoginDisconnectPacket int32 = 0x00
+)
+
+// StatusState is the next state requested in the handshake of the Server List Ping.
+const StatusState int32 = 1
+
+// maxVarIntSize is the number of bytes of the longest VarInt.
+const maxVarIntSize = 5
+
+// Packet returns an uncompressed packet: its length, id and data.
+func Packet(id int32, data []byte) []byte {
+	body := append(AppendVarInt(nil, id), data...)
+	return append(AppendVarInt(nil, int32(len(body))), body...)
+}
+
+// ReadPacket reads an uncompressed packet of at most maxSize bytes with the given id and returns a reader of
+// its data.
+func ReadPacket(r *bufio.Reader, id int32, maxSize int32) (*bytes.Reader, error) {
+	size, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 || size > maxSize {
+		return nil, fmt.Errorf("mcproto: invalid packet size %d", size)
+	}
+	packet := make([]byte, size)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+
+	pr := bytes.NewReader(packet)
+	if got, err := ReadVarInt(pr); err != nil {
+		return nil, err
+	} else if got != id {
+		return nil, fmt.Errorf("mcproto: unexpected packet %#x, expected %#x", got, id)
+	}
+	return pr, nil
+}
+
+// AppendString appends s prefixed with its length.
+func AppendString(b []byte, s string) []byte {
+	return append(AppendVarInt(b, int32(len(s))), s...)
+}
+
+// ReadString reads a string prefixed with its length.
+func ReadString(r *bytes.Reader) (string, error) {
+	length, err := ReadVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || int(length) > r.Len() {
+		return "", errors.New("mcproto: string is too long")
+	}
+	b := make([]byte, length)
+	_, err = io.ReadFull(r, b)
+	return string(b), err
+}
+
+// AppendVarInt appends v as a VarInt: seven bits per byte, least significant first, with the most
+// significant bit set on every byte but the last. Negative values take five bytes.
+func AppendVarInt(b []byte, v int32) []byte {
+	u := uint32(v)
+	for u >= 0x80 {
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(b, byte(u))
+}
+
+// ReadVarInt reads a VarInt.
+func ReadVarInt(r io.ByteReader) (int32, error) {
+	var v uint32
+	for i := range maxVarIntSize {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(v), nil
+		}
+	}
+	return 0, errors.New("mcproto: VarInt is too long")
+}
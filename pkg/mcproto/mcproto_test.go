@@ -0,0 +1,39 @@
+package mcproto
+
+import (
+	"bufio"
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Minecraft protocol", func() {
+	It("encodes VarInts", func() {
+		for _, v := range []int32{0, 1, 127, 128, 25565, 2147483647, -1} {
+			decoded, err := ReadVarInt(bytes.NewReader(AppendVarInt(nil, v)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(v))
+		}
+		Expect(AppendVarInt(nil, -1)).To(Equal([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}))
+		Expect(AppendVarInt(nil, 25565)).To(HaveLen(3))
+
+		_, err := ReadVarInt(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}))
+		Expect(err).To(MatchError(ContainSubstring("VarInt is too long")))
+	})
+
+	It("reads the packets it writes", func() {
+		r := bufio.NewReader(bytes.NewReader(Packet(PingPacket, AppendString(nil, "hello"))))
+		data, err := ReadPacket(r, PingPacket, 64)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ReadString(data)).To(Equal("hello"))
+	})
+
+	It("rejects unexpected and oversized packets", func() {
+		packet := Packet(StatusPacket, AppendString(nil, "hello"))
+		_, err := ReadPacket(bufio.NewReader(bytes.NewReader(packet)), PingPacket, 64)
+		Expect(err).To(MatchError(ContainSubstring("unexpected packet 0x0, expected 0x1")))
+		_, err = ReadPacket(bufio.NewReader(bytes.NewReader(packet)), StatusPacket, 4)
+		Expect(err).To(MatchError(ContainSubstring("invalid packet size 7")))
+	})
+})
@@ -0,0 +1,93 @@
+package minecraft
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+// DefaultMojangAPIURL is the base URL of the Mojang API, which looks up player profiles.
+const DefaultMojangAPIURL = "https://api.mojang.com"
+
+var (
+	// playerNamePattern matches the names of Minecraft accounts.
+	playerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+	// uuidPattern matches UUIDs without dashes, as returned by the Mojang API.
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// Player is a Minecraft player resolved from their name.
+type Player struct {
+	// Name is the name of the player, as spelled by their account in online mode.
+	Name string
+	// UUID is the UUID of the player, formatted with dashes.
+	UUID string
+}
+
+// PlayerResolver resolves player names to their Mojang accounts.
+type PlayerResolver struct {
+	// MojangAPIURL is the base URL of the Mojang API.
+	MojangAPIURL string
+	// Client is the HTTP client used for the API.
+	Client *http.Client
+}
+
+// NewPlayerResolver returns a PlayerResolver for the public Mojang API.
+func NewPlayerResolver() *PlayerResolver {
+	return &PlayerResolver{
+		MojangAPIURL: DefaultMojangAPIURL,
+		Client:       &http.Client{Timeout: webapi.DefaultTimeout},
+	}
+}
+
+// ResolvePlayer returns the player of the Mojang account named name.
+func (r *PlayerResolver) ResolvePlayer(ctx context.Context, name string) (Player, error) {
+	if !playerNamePattern.MatchString(name) {
+		return Player{}, fmt.Errorf("invalid player name %q", name)
+	}
+	var profile struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	err := webapi.GetJSON(ctx, r.Client, r.MojangAPIURL+"/users/profiles/minecraft/"+url.PathEscape(name), nil, &profile)
+	if errors.Is(err, webapi.ErrNotFound) {
+		return Player{}, fmt.Errorf("there is no Minecraft account named %s", name)
+	}
+	if err != nil {
+		return Player{}, fmt.Errorf("looking up the Minecraft account of %s: %w", name, err)
+	}
+	uuid, err := formatUUID(profile.ID)
+	if err != nil {
+		return Player{}, err
+	}
+	if profile.Name == "" {
+		profile.Name = name
+	}
+	return Player{Name: profile.Name, UUID: uuid}, nil
+}
+
+// OfflinePlayer returns the player named name on servers in offline mode, which derive the UUID from the name.
+func OfflinePlayer(name string) Player {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	// The UUID is a name-based UUID of version 3.
+	sum[6] = sum[6]&0x0f | 0x30
+	sum[8] = sum[8]&0x3f | 0x80
+	uuid, _ := formatUUID(fmt.Sprintf("%x", sum))
+	return Player{Name: name, UUID: uuid}
+}
+
+// formatUUID formats a UUID of 32 hexadecimal digits with dashes, as in whitelist.json and ops.json.
+func formatUUID(id string) (string, error) {
+	if !uuidPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid UUID %q", id)
+	}
+	id = strings.ToLower(id)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32]), nil
+}
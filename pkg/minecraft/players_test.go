@@ -0,0 +1,56 @@
+package minecraft
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlayerResolver", func() {
+	var (
+		ctx      context.Context
+		resolver *PlayerResolver
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/profiles/minecraft/{name}", func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("name") != "notch" {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"id":   "069A79F444E94726A5BEFCA90E38AAF5",
+				"name": "Notch",
+			})
+		})
+		server := httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+		resolver = &PlayerResolver{MojangAPIURL: server.URL, Client: server.Client()}
+	})
+
+	It("resolves names to the UUID and name of their Mojang account", func() {
+		player, err := resolver.ResolvePlayer(ctx, "notch")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(player).To(Equal(Player{Name: "Notch", UUID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}))
+	})
+
+	It("fails for names without an account", func() {
+		_, err := resolver.ResolvePlayer(ctx, "nobody")
+		Expect(err).To(MatchError(ContainSubstring("no Minecraft account named nobody")))
+	})
+
+	It("rejects invalid names without a request", func() {
+		_, err := resolver.ResolvePlayer(ctx, "../x")
+		Expect(err).To(MatchError(ContainSubstring("invalid player name")))
+	})
+
+	It("derives the UUIDs of offline players from their name", func() {
+		Expect(OfflinePlayer("Notch")).To(Equal(Player{Name: "Notch", UUID: "b50ad385-829d-3141-a216-7e7d7539ba7f"}))
+	})
+})
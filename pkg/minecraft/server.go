@@ -0,0 +1,413 @@
+// Package minecraft resolves Minecraft servers with their loader and modpack to the files to install, and player
+// names to their accounts.
+package minecraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+const (
+	// DefaultMojangURL is the base URL of the Minecraft version manifest of Mojang.
+	DefaultMojangURL = "https://piston-meta.mojang.com"
+	// DefaultPaperURL is the base URL of the PaperMC downloads API.
+	DefaultPaperURL = "https://fill.papermc.io"
+	// DefaultFabricURL is the base URL of the Fabric meta API.
+	DefaultFabricURL = "https://meta.fabricmc.net"
+	// DefaultForgeURL is the base URL of the Maven repository of Minecraft Forge.
+	DefaultForgeURL = "https://maven.minecraftforge.net"
+	// DefaultNeoForgeURL is the base URL of the Maven repository of NeoForge.
+	DefaultNeoForgeURL = "https://maven.neoforged.net"
+)
+
+// ServerResolver resolves Minecraft servers to the files to install.
+type ServerResolver struct {
+	// MojangURL is the base URL of the Minecraft version manifest.
+	MojangURL string
+	// PaperURL is the base URL of the PaperMC downloads API.
+	PaperURL string
+	// FabricURL is the base URL of the Fabric meta API.
+	FabricURL string
+	// ForgeURL is the base URL of the Maven repository of Minecraft Forge.
+	ForgeURL string
+	// NeoForgeURL is the base URL of the Maven repository of NeoForge.
+	NeoForgeURL string
+	// Mods resolves the modpacks of servers. Servers with a modpack are not resolved without one.
+	Mods *mods.Resolver
+	// Client is the HTTP client used for the APIs.
+	Client *http.Client
+}
+
+// NewServerResolver returns a ServerResolver for the public APIs, resolving modpacks with modResolver.
+func NewServerResolver(modResolver *mods.Resolver) *ServerResolver {
+	return &ServerResolver{
+		MojangURL:   DefaultMojangURL,
+		PaperURL:    DefaultPaperURL,
+		FabricURL:   DefaultFabricURL,
+		ForgeURL:    DefaultForgeURL,
+		NeoForgeURL: DefaultNeoForgeURL,
+		Mods:        modResolver,
+		Client:      &http.Client{Timeout: webapi.DefaultTimeout},
+	}
+}
+
+// ServerRequest is a Minecraft server to resolve. Empty fields are taken from the modpack, or default to the
+// latest version.
+type ServerRequest struct {
+	GameVersion   string
+	Loader        mods.Loader
+	LoaderVersion string
+	Modpack       *mods.Mod
+}
+
+// Server is the Minecraft server a ServerRequest resolved to.
+type Server struct {
+	GameVersion   string
+	Loader        mods.Loader
+	LoaderVersion string
+	// File is the server jar, or the installer of the Forge and NeoForge servers.
+	File mods.File
+	// Modpack is the file of the modpack, if any.
+	Modpack *mods.Modpack
+}
+
+// ResolveServer resolves the modpack of the request first, whose Minecraft version and loader are used unless the
+// request specifies them, and then the server of the loader.
+func (r *ServerResolver) ResolveServer(ctx context.Context, req ServerRequest) (Server, error) {
+	server := Server{GameVersion: req.GameVersion, Loader: req.Loader, LoaderVersion: req.LoaderVersion}
+	if req.Modpack != nil {
+		if r.Mods == nil {
+			return Server{}, errors.New("modpacks are not resolved")
+		}
+		target := mods.Target{GameVersion: req.GameVersion, Loader: req.Loader}
+		modpack, pack, err := r.Mods.ResolveModpack(ctx, *req.Modpack, target)
+		if err != nil {
+			return Server{}, err
+		}
+		server.Modpack = &modpack
+		if server.GameVersion == "" {
+			server.GameVersion = pack.GameVersion
+		}
+		if server.Loader == "" {
+			server.Loader = pack.Loader
+		}
+		// The loader version of the modpack only applies to the server the modpack is made for.
+		if server.LoaderVersion == "" && server.Loader == pack.Loader && server.GameVersion == pack.GameVersion {
+			server.LoaderVersion = pack.LoaderVersion
+		}
+	}
+	if server.Loader == "" {
+		server.Loader = mods.LoaderVanilla
+	}
+
+	var err error
+	switch server.Loader {
+	case mods.LoaderVanilla:
+		server.LoaderVersion = ""
+		err = r.resolveVanilla(ctx, &server)
+	case mods.LoaderPaper:
+		err = r.resolvePaper(ctx, &server)
+	case mods.LoaderFabric:
+		err = r.resolveFabric(ctx, &server)
+	case mods.LoaderForge:
+		err = r.resolveForge(ctx, &server)
+	case mods.LoaderNeoForge:
+		err = r.resolveNeoForge(ctx, &server)
+	default:
+		err = fmt.Errorf("unknown loader %q", server.Loader)
+	}
+	if err != nil {
+		return Server{}, err
+	}
+	return server, nil
+}
+
+// resolveVanilla resolves the server jar of the Minecraft version in the version manifest of Mojang, defaulting to
+// the latest release.
+func (r *ServerResolver) resolveVanilla(ctx context.Context, server *Server) error {
+	var manifest struct {
+		Latest struct {
+			Release string `json:"release"`
+		} `json:"latest"`
+		Versions []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"versions"`
+	}
+	if err := r.getJSON(ctx, r.MojangURL+"/mc/game/version_manifest_v2.json", nil, &manifest); err != nil {
+		return fmt.Errorf("failed to get the Minecraft versions: %w", err)
+	}
+	if server.GameVersion == "" {
+		server.GameVersion = manifest.Latest.Release
+	}
+
+	for _, version := range manifest.Versions {
+		if version.ID != server.GameVersion {
+			continue
+		}
+		var details struct {
+			Downloads struct {
+				Server *struct {
+					URL  string `json:"url"`
+					SHA1 string `json:"sha1"`
+				} `json:"server"`
+			} `json:"downloads"`
+		}
+		if err := r.getJSON(ctx, version.URL, nil, &details); err != nil {
+			return fmt.Errorf("failed to get Minecraft %s: %w", version.ID, err)
+		}
+		if details.Downloads.Server == nil {
+			return fmt.Errorf("there is no server for Minecraft %s", version.ID)
+		}
+		server.File = mods.File{
+			Version: version.ID,
+			Name:    "minecraft_server." + version.ID + ".jar",
+			URL:     details.Downloads.Server.URL,
+			SHA1:    strings.ToLower(details.Downloads.Server.SHA1),
+		}
+		return nil
+	}
+	return fmt.Errorf("version %s of Minecraft not found", server.GameVersion)
+}
+
+// resolvePaper resolves the Paper build, defaulting to the latest stable build of the latest version.
+func (r *ServerResolver) resolvePaper(ctx context.Context, server *Server) error {
+	projectURL := r.PaperURL + "/v3/projects/paper"
+	if server.GameVersion == "" {
+		// Versions are listed newest first.
+		var versions struct {
+			Versions []struct {
+				Version struct {
+					ID string `json:"id"`
+				} `json:"version"`
+			} `json:"versions"`
+		}
+		if err := r.getJSON(ctx, projectURL+"/versions", nil, &versions); err != nil {
+			return fmt.Errorf("failed to list the Paper versions: %w", err)
+		}
+		if len(versions.Versions) == 0 {
+			return errors.New("there are no Paper versions")
+		}
+		server.GameVersion = versions.Versions[0].Version.ID
+	}
+
+	// Builds are listed newest first.
+	var builds []struct {
+		ID        int    `json:"id"`
+		Channel   string `json:"channel"`
+		Downloads map[string]struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"downloads"`
+	}
+	err := r.getJSON(ctx, fmt.Sprintf("%s/versions/%s/builds", projectURL, url.PathEscape(server.GameVersion)),
+		nil, &builds)
+	if errors.Is(err, webapi.ErrNotFound) {
+		return fmt.Errorf("there are no Paper builds for Minecraft %s", server.GameVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the Paper builds for Minecraft %s: %w", server.GameVersion, err)
+	}
+
+	index := -1
+	for i, build := range builds {
+		if server.LoaderVersion != "" {
+			if strconv.Itoa(build.ID) == server.LoaderVersion {
+				index = i
+				break
+			}
+			continue
+		}
+		if index == -1 || (build.Channel == "STABLE" && builds[index].Channel != "STABLE") {
+			index = i
+		}
+	}
+	if index == -1 && server.LoaderVersion != "" {
+		return fmt.Errorf("build %s of Paper for Minecraft %s not found", server.LoaderVersion, server.GameVersion)
+	}
+	if index == -1 {
+		return fmt.Errorf("there are no Paper builds for Minecraft %s", server.GameVersion)
+	}
+	build := builds[index]
+	download, ok := build.Downloads["server:default"]
+	if !ok {
+		return fmt.Errorf("build %d of Paper for Minecraft %s has no server download", build.ID, server.GameVersion)
+	}
+	name, err := mods.FileName(download.Name)
+	if err != nil {
+		return err
+	}
+	server.LoaderVersion = strconv.Itoa(build.ID)
+	server.File = mods.File{Version: server.LoaderVersion, Name: name, URL: download.URL}
+	return nil
+}
+
+// fabricVersion is a version listed by the Fabric meta API, newest first.
+type fabricVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// latestStable returns the first stable version, or the first version when none is stable.
+func latestStable(versions []fabricVersion) string {
+	for _, version := range versions {
+		if version.Stable {
+			return version.Version
+		}
+	}
+	if len(versions) > 0 {
+		return versions[0].Version
+	}
+	return ""
+}
+
+// resolveFabric resolves the Fabric server launcher, defaulting to the latest stable Minecraft and loader versions.
+func (r *ServerResolver) resolveFabric(ctx context.Context, server *Server) error {
+	if server.GameVersion == "" {
+		var versions []fabricVersion
+		if err := r.getJSON(ctx, r.FabricURL+"/v2/versions/game", nil, &versions); err != nil {
+			return fmt.Errorf("failed to list the Minecraft versions of Fabric: %w", err)
+		}
+		server.GameVersion = latestStable(versions)
+	}
+
+	if server.LoaderVersion == "" {
+		var loaders []struct {
+			Loader fabricVersion `json:"loader"`
+		}
+		err := r.getJSON(ctx, fmt.Sprintf("%s/v2/versions/loader/%s", r.FabricURL, url.PathEscape(server.GameVersion)),
+			nil, &loaders)
+		if err != nil {
+			return fmt.Errorf("failed to list the Fabric loaders for Minecraft %s: %w", server.GameVersion, err)
+		}
+		versions := make([]fabricVersion, 0, len(loaders))
+		for _, loader := range loaders {
+			versions = append(versions, loader.Loader)
+		}
+		server.LoaderVersion = latestStable(versions)
+		if server.LoaderVersion == "" {
+			return fmt.Errorf("there is no Fabric loader for Minecraft %s", server.GameVersion)
+		}
+	}
+
+	var installers []fabricVersion
+	if err := r.getJSON(ctx, r.FabricURL+"/v2/versions/installer", nil, &installers); err != nil {
+		return fmt.Errorf("failed to list the Fabric installers: %w", err)
+	}
+	installer := latestStable(installers)
+	if installer == "" {
+		return errors.New("there are no Fabric installers")
+	}
+
+	name, err := mods.FileName(fmt.Sprintf("fabric-server-mc.%s-loader.%s-launcher.%s.jar",
+		server.GameVersion, server.LoaderVersion, installer))
+	if err != nil {
+		return err
+	}
+	server.File = mods.File{
+		Version: server.LoaderVersion,
+		Name:    name,
+		URL: fmt.Sprintf("%s/v2/versions/loader/%s/%s/%s/server/jar", r.FabricURL,
+			url.PathEscape(server.GameVersion), url.PathEscape(server.LoaderVersion), url.PathEscape(installer)),
+	}
+	return nil
+}
+
+// resolveForge resolves the Forge installer, defaulting to the recommended or else the latest Forge version of the
+// Minecraft version.
+func (r *ServerResolver) resolveForge(ctx context.Context, server *Server) error {
+	if server.GameVersion == "" {
+		return errors.New("the Minecraft version is required for Forge")
+	}
+	if server.LoaderVersion == "" {
+		var promotions struct {
+			Promos map[string]string `json:"promos"`
+		}
+		if err := r.getJSON(ctx, r.ForgeURL+"/net/minecraftforge/forge/promotions_slim.json", nil, &promotions); err != nil {
+			return fmt.Errorf("failed to get the Forge versions: %w", err)
+		}
+		server.LoaderVersion = promotions.Promos[server.GameVersion+"-recommended"]
+		if server.LoaderVersion == "" {
+			server.LoaderVersion = promotions.Promos[server.GameVersion+"-latest"]
+		}
+		if server.LoaderVersion == "" {
+			return fmt.Errorf("there is no Forge version for Minecraft %s", server.GameVersion)
+		}
+	}
+
+	version := server.GameVersion + "-" + server.LoaderVersion
+	name, err := mods.FileName("forge-" + version + "-installer.jar")
+	if err != nil {
+		return err
+	}
+	server.File = mods.File{
+		Version: server.LoaderVersion,
+		Name:    name,
+		URL:     fmt.Sprintf("%s/net/minecraftforge/forge/%s/%s", r.ForgeURL, url.PathEscape(version), name),
+	}
+	return nil
+}
+
+// resolveNeoForge resolves the NeoForge installer, defaulting to the latest stable NeoForge version of the Minecraft
+// version. NeoForge versions start with the minor and patch version of Minecraft, 21.1 for Minecraft 1.21.1.
+func (r *ServerResolver) resolveNeoForge(ctx context.Context, server *Server) error {
+	if server.GameVersion == "" {
+		return errors.New("the Minecraft version is required for NeoForge")
+	}
+	if server.LoaderVersion == "" {
+		parts := strings.Split(server.GameVersion, ".")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] != "1" {
+			return fmt.Errorf("there is no NeoForge version for Minecraft %s", server.GameVersion)
+		}
+		if len(parts) == 2 {
+			parts = append(parts, "0")
+		}
+		prefix := parts[1] + "." + parts[2] + "."
+
+		// Versions are listed oldest first.
+		var versions struct {
+			Versions []string `json:"versions"`
+		}
+		err := r.getJSON(ctx, r.NeoForgeURL+"/api/maven/versions/releases/net/neoforged/neoforge", nil, &versions)
+		if err != nil {
+			return fmt.Errorf("failed to list the NeoForge versions: %w", err)
+		}
+		for _, version := range versions.Versions {
+			if !strings.HasPrefix(version, prefix) {
+				continue
+			}
+			if server.LoaderVersion == "" || !strings.Contains(version, "-") ||
+				strings.Contains(server.LoaderVersion, "-") {
+				server.LoaderVersion = version
+			}
+		}
+		if server.LoaderVersion == "" {
+			return fmt.Errorf("there is no NeoForge version for Minecraft %s", server.GameVersion)
+		}
+	}
+
+	name, err := mods.FileName("neoforge-" + server.LoaderVersion + "-installer.jar")
+	if err != nil {
+		return err
+	}
+	server.File = mods.File{
+		Version: server.LoaderVersion,
+		Name:    name,
+		URL: fmt.Sprintf("%s/releases/net/neoforged/neoforge/%s/%s", r.NeoForgeURL,
+			url.PathEscape(server.LoaderVersion), name),
+	}
+	return nil
+}
+
+// getJSON decodes the JSON response to a GET request of the API at rawURL into v.
+func (r *ServerResolver) getJSON(ctx context.Context, rawURL string, header http.Header, v any) error {
+	return webapi.GetJSON(ctx, r.Client, rawURL, header, v)
+}
@@ -0,0 +1,304 @@
+package minecraft
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mods"
+)
+
+const (
+	vanillaSHA1 = "fedcba9876543210fedcba9876543210fedcba98"
+	packSHA1    = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+// mrpack returns a .mrpack file with the given dependencies in its index.
+func mrpack(dependencies map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	index, err := archive.Create("modrinth.index.json")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(json.NewEncoder(index).Encode(map[string]any{
+		"formatVersion": 1,
+		"game":          "minecraft",
+		"dependencies":  dependencies,
+	})).To(Succeed())
+	Expect(archive.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+// serveServerAPIs answers like the APIs of Mojang, Paper, Fabric, Forge and NeoForge for Minecraft 1.21.1 and 1.21.4,
+// the Modrinth API for the fabulously-optimized modpack and the CurseForge API for modpack 715572.
+func serveServerAPIs() *httptest.Server {
+	pack := mrpack(map[string]string{"minecraft": "1.21.1", "fabric-loader": "0.16.5"})
+	packSum := sha1.Sum(pack)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	writeJSON := func(w http.ResponseWriter, v any) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	mux.HandleFunc("GET /mc/game/version_manifest_v2.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"latest": map[string]string{"release": "1.21.4"},
+			"versions": []map[string]string{
+				{"id": "1.21.4", "url": server.URL + "/v1/packages/1.21.4/server.json"},
+				{"id": "1.21.1", "url": server.URL + "/v1/packages/1.21.1/server.json"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /v1/packages/{version}/server.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"downloads": map[string]any{"server": map[string]string{
+			"url":  "https://piston-data.mojang.com/" + r.PathValue("version") + "/server.jar",
+			"sha1": vanillaSHA1,
+		}}})
+	})
+
+	mux.HandleFunc("GET /v3/projects/paper/versions", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"versions": []map[string]any{
+			{"version": map[string]string{"id": "1.21.4"}},
+			{"version": map[string]string{"id": "1.21.1"}},
+		}})
+	})
+	mux.HandleFunc("GET /v3/projects/paper/versions/1.21.4/builds", func(w http.ResponseWriter, _ *http.Request) {
+		build := func(id int, channel string) map[string]any {
+			return map[string]any{"id": id, "channel": channel, "downloads": map[string]any{
+				"server:default": map[string]string{
+					"name": fmt.Sprintf("paper-1.21.4-%d.jar", id),
+					"url":  "https://fill-data.papermc.io/paper.jar",
+				},
+			}}
+		}
+		writeJSON(w, []map[string]any{build(232, "BETA"), build(231, "STABLE"), build(230, "STABLE")})
+	})
+
+	mux.HandleFunc("GET /v2/versions/game", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{{"version": "25w02a", "stable": false}, {"version": "1.21.4", "stable": true}})
+	})
+	mux.HandleFunc("GET /v2/versions/loader/{game}", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"loader": map[string]any{"version": "0.16.10", "stable": true}},
+			{"loader": map[string]any{"version": "0.16.9", "stable": true}},
+		})
+	})
+	mux.HandleFunc("GET /v2/versions/installer", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{{"version": "1.0.2", "stable": false}, {"version": "1.0.1", "stable": true}})
+	})
+
+	mux.HandleFunc("GET /net/minecraftforge/forge/promotions_slim.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"promos": map[string]string{
+			"1.21.1-latest":      "52.0.40",
+			"1.21.1-recommended": "52.0.16",
+			"1.21.4-latest":      "54.0.17",
+		}})
+	})
+
+	mux.HandleFunc("GET /api/maven/versions/releases/net/neoforged/neoforge",
+		func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, map[string]any{"versions": []string{
+				"21.1.1", "21.1.77", "21.1.78-beta", "21.4.1-beta", "21.4.2-beta",
+			}})
+		})
+
+	mux.HandleFunc("GET /v2/project/fabulously-optimized/version", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, []map[string]any{{
+			"version_number": "6.4.0",
+			"files": []map[string]any{{
+				"url":      server.URL + "/fo.mrpack",
+				"filename": "Fabulously Optimized 6.4.0.mrpack",
+				"primary":  true,
+				"hashes":   map[string]string{"sha1": hex.EncodeToString(packSum[:])},
+			}},
+		}})
+	})
+	mux.HandleFunc("GET /fo.mrpack", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(pack)
+	})
+
+	mux.HandleFunc("GET /v1/mods/715572/files", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"data": []map[string]any{{
+			"id":               5000000,
+			"fileName":         "All the Mods 10-2.0.zip",
+			"downloadUrl":      "https://edge.forgecdn.net/atm10.zip",
+			"gameVersions":     []string{"NeoForge", "1.21.1"},
+			"serverPackFileId": 5000001,
+		}}})
+	})
+	mux.HandleFunc("GET /v1/mods/715572/files/5000001", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"data": map[string]any{
+			"id":          5000001,
+			"fileName":    "ServerFiles-2.0.zip",
+			"downloadUrl": "https://edge.forgecdn.net/atm10-server.zip",
+			"hashes":      []map[string]any{{"value": packSHA1, "algo": 1}},
+		}})
+	})
+	return server
+}
+
+var _ = Describe("ServerResolver", func() {
+	ctx := context.Background()
+
+	var (
+		server   *httptest.Server
+		resolver *ServerResolver
+	)
+
+	BeforeEach(func() {
+		server = serveServerAPIs()
+		resolver = &ServerResolver{
+			MojangURL:   server.URL,
+			PaperURL:    server.URL,
+			FabricURL:   server.URL,
+			ForgeURL:    server.URL,
+			NeoForgeURL: server.URL,
+			Mods: &mods.Resolver{
+				ModrinthURL:      server.URL,
+				CurseForgeURL:    server.URL,
+				CurseForgeAPIKey: "key",
+				Client:           server.Client(),
+			},
+			Client: server.Client(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("resolves the latest vanilla release", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved).To(Equal(Server{
+			GameVersion: "1.21.4",
+			Loader:      mods.LoaderVanilla,
+			File: mods.File{
+				Version: "1.21.4",
+				Name:    "minecraft_server.1.21.4.jar",
+				URL:     "https://piston-data.mojang.com/1.21.4/server.jar",
+				SHA1:    vanillaSHA1,
+			},
+		}))
+
+		_, err = resolver.ResolveServer(ctx, ServerRequest{GameVersion: "1.2.5"})
+		Expect(err).To(MatchError("version 1.2.5 of Minecraft not found"))
+	})
+
+	It("resolves the latest stable Paper build", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderPaper})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.GameVersion).To(Equal("1.21.4"))
+		Expect(resolved.LoaderVersion).To(Equal("231"))
+		Expect(resolved.File.URL).To(Equal("https://fill-data.papermc.io/paper.jar"))
+
+		resolved, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderPaper, LoaderVersion: "232"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("232"))
+
+		_, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderPaper, GameVersion: "1.21.1"})
+		Expect(err).To(MatchError("there are no Paper builds for Minecraft 1.21.1"))
+	})
+
+	It("resolves the Fabric server launcher", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderFabric})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.GameVersion).To(Equal("1.21.4"))
+		Expect(resolved.LoaderVersion).To(Equal("0.16.10"))
+		Expect(resolved.File).To(Equal(mods.File{
+			Version: "0.16.10",
+			Name:    "fabric-server-mc.1.21.4-loader.0.16.10-launcher.1.0.1.jar",
+			URL:     server.URL + "/v2/versions/loader/1.21.4/0.16.10/1.0.1/server/jar",
+		}))
+	})
+
+	It("resolves the recommended Forge installer", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderForge, GameVersion: "1.21.1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("52.0.16"))
+		Expect(resolved.File.URL).To(Equal(
+			server.URL + "/net/minecraftforge/forge/1.21.1-52.0.16/forge-1.21.1-52.0.16-installer.jar"))
+
+		resolved, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderForge, GameVersion: "1.21.4"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("54.0.17"))
+
+		_, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderForge})
+		Expect(err).To(MatchError("the Minecraft version is required for Forge"))
+	})
+
+	It("resolves the latest stable NeoForge installer", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderNeoForge, GameVersion: "1.21.1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("21.1.77"))
+		Expect(resolved.File.URL).To(Equal(
+			server.URL + "/releases/net/neoforged/neoforge/21.1.77/neoforge-21.1.77-installer.jar"))
+
+		resolved, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderNeoForge, GameVersion: "1.21.4"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("21.4.2-beta"))
+
+		_, err = resolver.ResolveServer(ctx, ServerRequest{Loader: mods.LoaderNeoForge, GameVersion: "1.20.1"})
+		Expect(err).To(MatchError("there is no NeoForge version for Minecraft 1.20.1"))
+	})
+
+	It("takes the server of Modrinth modpacks from their index", func() {
+		modpack := &mods.Mod{Source: mods.SourceModrinth, Project: "fabulously-optimized"}
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{Modpack: modpack})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.GameVersion).To(Equal("1.21.1"))
+		Expect(resolved.Loader).To(Equal(mods.LoaderFabric))
+		Expect(resolved.LoaderVersion).To(Equal("0.16.5"))
+		Expect(resolved.Modpack).NotTo(BeNil())
+		Expect(resolved.Modpack.Format).To(Equal(mods.ModpackFormatMrpack))
+		Expect(resolved.Modpack.File.Name).To(Equal("Fabulously-Optimized-6.4.0.mrpack"))
+
+		By("preferring the loader version of the spec")
+		resolved, err = resolver.ResolveServer(ctx, ServerRequest{Modpack: modpack, LoaderVersion: "0.16.9"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.LoaderVersion).To(Equal("0.16.9"))
+
+		By("rejecting modpacks that do not match their hash")
+		modpack.SHA1 = packSHA1
+		_, err = resolver.ResolveServer(ctx, ServerRequest{Modpack: modpack})
+		Expect(err).To(MatchError(ContainSubstring("the SHA-1 hash of Fabulously-Optimized-6.4.0.mrpack is")))
+	})
+
+	It("installs the server pack of CurseForge modpacks", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{
+			Modpack: &mods.Mod{Source: mods.SourceCurseForge, Project: "715572"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.GameVersion).To(Equal("1.21.1"))
+		Expect(resolved.Loader).To(Equal(mods.LoaderNeoForge))
+		Expect(resolved.LoaderVersion).To(Equal("21.1.77"))
+		Expect(resolved.Modpack).To(Equal(&mods.Modpack{
+			File: mods.File{
+				Version: "5000000",
+				Name:    "ServerFiles-2.0.zip",
+				URL:     "https://edge.forgecdn.net/atm10-server.zip",
+				SHA1:    packSHA1,
+			},
+			Format: mods.ModpackFormatServerPack,
+		}))
+	})
+
+	It("installs URLs that are not .mrpack files as server packs", func() {
+		resolved, err := resolver.ResolveServer(ctx, ServerRequest{
+			GameVersion: "1.21.1",
+			Modpack:     &mods.Mod{Source: mods.SourceURL, URL: "https://example.com/pack.zip"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolved.Loader).To(Equal(mods.LoaderVanilla))
+		Expect(resolved.Modpack.Format).To(Equal(mods.ModpackFormatServerPack))
+	})
+})
@@ -0,0 +1,130 @@
+package mods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+// curseForgeHashSHA1 is the algorithm of SHA-1 file hashes in the CurseForge API.
+const curseForgeHashSHA1 = 1
+
+// curseForgeModLoaders are the mod loader types of the CurseForge API.
+var curseForgeModLoaders = map[Loader]int{
+	LoaderForge:    1,
+	LoaderFabric:   4,
+	LoaderNeoForge: 6,
+}
+
+// curseForgeFile is a file of a CurseForge mod, see https://docs.curseforge.com/rest-api/.
+type curseForgeFile struct {
+	ID          int    `json:"id"`
+	FileName    string `json:"fileName"`
+	DownloadURL string `json:"downloadUrl"`
+	Hashes      []struct {
+		Value string `json:"value"`
+		Algo  int    `json:"algo"`
+	} `json:"hashes"`
+	// GameVersions lists the Minecraft versions and loaders the file is for, e.g. '1.20.1' and 'Forge'.
+	GameVersions []string `json:"gameVersions"`
+	// ServerPackFileID is the file of the server pack of a modpack file.
+	ServerPackFileID int `json:"serverPackFileId"`
+}
+
+// resolveCurseForge resolves the CurseForge file of mod.
+func (r *Resolver) resolveCurseForge(ctx context.Context, mod Mod, target Target) (File, error) {
+	modID, file, err := r.getCurseForgeFile(ctx, mod, target)
+	if err != nil {
+		return File{}, err
+	}
+	return curseForgeDownload(modID, file)
+}
+
+// getCurseForgeFile returns the ID of the mod and the given file of it, or the most recent file for the target,
+// which CurseForge lists first.
+func (r *Resolver) getCurseForgeFile(ctx context.Context, mod Mod, target Target) (int, curseForgeFile, error) {
+	var file curseForgeFile
+	if r.CurseForgeAPIKey == "" {
+		return 0, file, errors.New("mods on CurseForge cannot be resolved, the operator has no CurseForge API key")
+	}
+	modID, err := strconv.Atoi(mod.Project)
+	if err != nil {
+		return 0, file, fmt.Errorf("mods on CurseForge are referred to by their numeric ID, not %q", mod.Project)
+	}
+
+	if mod.Version != "" {
+		fileID, err := strconv.Atoi(mod.Version)
+		if err != nil {
+			return 0, file, fmt.Errorf("files on CurseForge are referred to by their numeric ID, not %q", mod.Version)
+		}
+		file, err = r.getCurseForgeFileByID(ctx, modID, fileID)
+		return modID, file, err
+	}
+
+	query := url.Values{"pageSize": {"1"}}
+	if target.GameVersion != "" {
+		query.Set("gameVersion", target.GameVersion)
+	}
+	if loader, ok := curseForgeModLoaders[target.Loader]; ok {
+		query.Set("modLoaderType", strconv.Itoa(loader))
+	}
+	var resp struct {
+		Data []curseForgeFile `json:"data"`
+	}
+	err = r.getJSON(ctx, fmt.Sprintf("%s/v1/mods/%d/files?%s", r.CurseForgeURL, modID, query.Encode()),
+		r.curseForgeHeader(), &resp)
+	if errors.Is(err, webapi.ErrNotFound) {
+		return 0, file, fmt.Errorf("mod %d not found on CurseForge", modID)
+	}
+	if err != nil {
+		return 0, file, fmt.Errorf("failed to list the files of CurseForge mod %d: %w", modID, err)
+	}
+	if len(resp.Data) == 0 {
+		return 0, file, fmt.Errorf("mod %d on CurseForge has no file for %s", modID, target)
+	}
+	return modID, resp.Data[0], nil
+}
+
+func (r *Resolver) getCurseForgeFileByID(ctx context.Context, modID, fileID int) (curseForgeFile, error) {
+	var resp struct {
+		Data curseForgeFile `json:"data"`
+	}
+	err := r.getJSON(ctx, fmt.Sprintf("%s/v1/mods/%d/files/%d", r.CurseForgeURL, modID, fileID),
+		r.curseForgeHeader(), &resp)
+	if errors.Is(err, webapi.ErrNotFound) {
+		return resp.Data, fmt.Errorf("file %d of CurseForge mod %d not found", fileID, modID)
+	}
+	if err != nil {
+		return resp.Data, fmt.Errorf("failed to get file %d of CurseForge mod %d: %w", fileID, modID, err)
+	}
+	return resp.Data, nil
+}
+
+func (r *Resolver) curseForgeHeader() http.Header {
+	return http.Header{"X-Api-Key": {r.CurseForgeAPIKey}}
+}
+
+// curseForgeDownload returns the download of a CurseForge file.
+func curseForgeDownload(modID int, file curseForgeFile) (File, error) {
+	// Authors can opt out of downloads outside of the CurseForge app, leaving the download URL empty.
+	if file.DownloadURL == "" {
+		return File{}, fmt.Errorf("the author of CurseForge mod %d does not allow downloading it outside of CurseForge",
+			modID)
+	}
+	name, err := FileName(file.FileName)
+	if err != nil {
+		return File{}, err
+	}
+	resolved := File{Version: strconv.Itoa(file.ID), Name: name, URL: file.DownloadURL}
+	for _, hash := range file.Hashes {
+		if hash.Algo == curseForgeHashSHA1 {
+			resolved.SHA1 = hash.Value
+		}
+	}
+	return resolved, nil
+}
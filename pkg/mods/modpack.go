@@ -0,0 +1,154 @@
+package mods
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+// ModpackFormat is how a modpack file is installed.
+type ModpackFormat string
+
+const (
+	// ModpackFormatMrpack is a Modrinth .mrpack file, whose files are downloaded as listed in its index.
+	ModpackFormatMrpack ModpackFormat = "Mrpack"
+	// ModpackFormatServerPack is a zip of the server files.
+	ModpackFormatServerPack ModpackFormat = "ServerPack"
+)
+
+// mrpackIndex is the name of the index of .mrpack files.
+const mrpackIndex = "modrinth.index.json"
+
+// Modpack is the file a modpack resolved to.
+type Modpack struct {
+	File   File
+	Format ModpackFormat
+}
+
+// ModpackServer is the Minecraft server a modpack is made for, as far as it is known.
+type ModpackServer struct {
+	GameVersion   string
+	Loader        Loader
+	LoaderVersion string
+}
+
+// ResolveModpack resolves the file of a modpack and the server it is made for. Modrinth modpacks and URLs of .mrpack
+// files are read for the dependencies in their index, CurseForge modpacks resolve to their server pack.
+func (r *Resolver) ResolveModpack(ctx context.Context, mod Mod, target Target) (Modpack, ModpackServer, error) {
+	if mod.Source == SourceCurseForge {
+		return r.resolveCurseForgeModpack(ctx, mod, target)
+	}
+
+	file, err := r.Resolve(ctx, mod, target)
+	if err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+	if mod.Source == SourceURL && !strings.HasSuffix(strings.ToLower(file.Name), ".mrpack") {
+		return Modpack{File: file, Format: ModpackFormatServerPack}, ModpackServer{}, nil
+	}
+	server, err := r.readMrpack(ctx, file)
+	if err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+	return Modpack{File: file, Format: ModpackFormatMrpack}, server, nil
+}
+
+// readMrpack downloads a .mrpack file and returns the server of the dependencies in its index.
+func (r *Resolver) readMrpack(ctx context.Context, file File) (ModpackServer, error) {
+	body, err := webapi.Get(ctx, r.Client, file.URL, nil, maxModpackSize)
+	if err != nil {
+		return ModpackServer{}, fmt.Errorf("failed to download modpack %s: %w", file.Name, err)
+	}
+	if file.SHA1 != "" {
+		sum := sha1.Sum(body)
+		if hash := hex.EncodeToString(sum[:]); hash != file.SHA1 {
+			return ModpackServer{}, fmt.Errorf("the SHA-1 hash of %s is %s, not %s", file.Name, hash, file.SHA1)
+		}
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return ModpackServer{}, fmt.Errorf("modpack %s is not a zip file: %w", file.Name, err)
+	}
+	indexFile, err := archive.Open(mrpackIndex)
+	if err != nil {
+		return ModpackServer{}, fmt.Errorf("modpack %s has no %s", file.Name, mrpackIndex)
+	}
+	defer func() { _ = indexFile.Close() }()
+	var index struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.NewDecoder(io.LimitReader(indexFile, webapi.MaxJSONSize)).Decode(&index); err != nil {
+		return ModpackServer{}, fmt.Errorf("failed to read the %s of modpack %s: %w", mrpackIndex, file.Name, err)
+	}
+
+	server := ModpackServer{GameVersion: index.Dependencies["minecraft"], Loader: LoaderVanilla}
+	for dependency, version := range index.Dependencies {
+		switch dependency {
+		case "minecraft":
+			continue
+		case "fabric-loader":
+			server.Loader = LoaderFabric
+		case "forge":
+			server.Loader = LoaderForge
+		case "neoforge":
+			server.Loader = LoaderNeoForge
+		default:
+			return ModpackServer{}, fmt.Errorf("modpack %s depends on %s, which is not supported", file.Name, dependency)
+		}
+		server.LoaderVersion = version
+	}
+	return server, nil
+}
+
+// resolveCurseForgeModpack resolves the server pack of a CurseForge modpack file. The server is taken from the game
+// versions of the modpack file, which do not include the loader version.
+func (r *Resolver) resolveCurseForgeModpack(
+	ctx context.Context,
+	mod Mod,
+	target Target,
+) (Modpack, ModpackServer, error) {
+	modID, file, err := r.getCurseForgeFile(ctx, mod, target)
+	if err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+	if file.ServerPackFileID == 0 {
+		return Modpack{}, ModpackServer{}, fmt.Errorf("file %d of CurseForge modpack %d has no server pack", file.ID, modID)
+	}
+	serverPack, err := r.getCurseForgeFileByID(ctx, modID, file.ServerPackFileID)
+	if err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+	download, err := curseForgeDownload(modID, serverPack)
+	if err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+	// The version of the modpack is the file the server pack belongs to, which is what the spec pins.
+	download.Version = strconv.Itoa(file.ID)
+	if download, err = checkSHA1(download, mod.SHA1); err != nil {
+		return Modpack{}, ModpackServer{}, err
+	}
+
+	server := ModpackServer{Loader: LoaderVanilla}
+	for _, gameVersion := range file.GameVersions {
+		switch loader := Loader(gameVersion); loader {
+		case LoaderFabric, LoaderForge, LoaderNeoForge:
+			server.Loader = loader
+		default:
+			if server.GameVersion == "" && gameVersion != "" && unicode.IsDigit(rune(gameVersion[0])) {
+				server.GameVersion = gameVersion
+			}
+		}
+	}
+	return Modpack{File: download, Format: ModpackFormatServerPack}, server, nil
+}
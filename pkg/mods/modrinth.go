@@ -0,0 +1,107 @@
+package mods
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+// modrinthVersion is a version of a Modrinth project, see https://docs.modrinth.com/api/.
+type modrinthVersion struct {
+	ID            string `json:"id"`
+	VersionNumber string `json:"version_number"`
+	Files         []struct {
+		URL      string `json:"url"`
+		Filename string `json:"filename"`
+		Primary  bool   `json:"primary"`
+		Hashes   struct {
+			SHA1 string `json:"sha1"`
+		} `json:"hashes"`
+	} `json:"files"`
+}
+
+// resolveModrinth resolves the primary file of the Modrinth version of mod.
+func (r *Resolver) resolveModrinth(ctx context.Context, mod Mod, target Target) (File, error) {
+	version, err := r.getModrinthVersion(ctx, mod, target)
+	if err != nil {
+		return File{}, err
+	}
+
+	if len(version.Files) == 0 {
+		return File{}, fmt.Errorf("version %s of Modrinth project %s has no files", version.VersionNumber, mod.Project)
+	}
+	file := version.Files[0]
+	for _, f := range version.Files {
+		if f.Primary {
+			file = f
+			break
+		}
+	}
+	name, err := FileName(file.Filename)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Version: version.VersionNumber, Name: name, URL: file.URL, SHA1: file.Hashes.SHA1}, nil
+}
+
+// getModrinthVersion returns the given version of the project, which Modrinth looks up by ID and version number,
+// or the most recent version for the target, which Modrinth lists first.
+func (r *Resolver) getModrinthVersion(ctx context.Context, mod Mod, target Target) (modrinthVersion, error) {
+	if mod.Project == "" {
+		return modrinthVersion{}, errors.New("the Modrinth project is not set")
+	}
+	projectURL := fmt.Sprintf("%s/v2/project/%s/version", r.ModrinthURL, url.PathEscape(mod.Project))
+
+	var version modrinthVersion
+	if mod.Version != "" {
+		err := r.getJSON(ctx, projectURL+"/"+url.PathEscape(mod.Version), nil, &version)
+		if errors.Is(err, webapi.ErrNotFound) {
+			return version, fmt.Errorf("version %s of Modrinth project %s not found", mod.Version, mod.Project)
+		}
+		if err != nil {
+			return version, fmt.Errorf("failed to get version %s of Modrinth project %s: %w", mod.Version, mod.Project, err)
+		}
+		return version, nil
+	}
+
+	query := url.Values{}
+	if target.GameVersion != "" {
+		gameVersions, _ := json.Marshal([]string{target.GameVersion})
+		query.Set("game_versions", string(gameVersions))
+	}
+	if loader := target.Loader.modrinthLoader(); loader != "" {
+		loaders, _ := json.Marshal([]string{loader})
+		query.Set("loaders", string(loaders))
+	}
+	listURL := projectURL
+	if len(query) > 0 {
+		listURL += "?" + query.Encode()
+	}
+
+	var versions []modrinthVersion
+	err := r.getJSON(ctx, listURL, nil, &versions)
+	if errors.Is(err, webapi.ErrNotFound) {
+		return version, fmt.Errorf("project %s not found on Modrinth", mod.Project)
+	}
+	if err != nil {
+		return version, fmt.Errorf("failed to list the versions of Modrinth project %s: %w", mod.Project, err)
+	}
+	if len(versions) == 0 {
+		return version, fmt.Errorf("project %s on Modrinth has no version for %s", mod.Project, target)
+	}
+	return versions[0], nil
+}
+
+// modrinthLoader returns the name of the loader on Modrinth, or an empty string for vanilla servers, whose mods
+// are not filtered by loader.
+func (l Loader) modrinthLoader() string {
+	if l == LoaderVanilla {
+		return ""
+	}
+	return strings.ToLower(string(l))
+}
@@ -0,0 +1,177 @@
+// Package mods resolves Minecraft mods and modpacks on Modrinth, CurseForge and plain URLs to the files to download.
+package mods
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/idebeijer/gameserver-operator/pkg/webapi"
+)
+
+// Source is where a mod is downloaded from.
+type Source string
+
+const (
+	// SourceModrinth resolves mods with the Modrinth API.
+	SourceModrinth Source = "Modrinth"
+	// SourceCurseForge resolves mods with the CurseForge API, which requires an API key.
+	SourceCurseForge Source = "CurseForge"
+	// SourceURL downloads the mod file from a URL.
+	SourceURL Source = "URL"
+)
+
+// Loader is the server software mods are resolved for.
+type Loader string
+
+// The loaders, named as in the GameServer API.
+const (
+	LoaderVanilla  Loader = "Vanilla"
+	LoaderPaper    Loader = "Paper"
+	LoaderFabric   Loader = "Fabric"
+	LoaderForge    Loader = "Forge"
+	LoaderNeoForge Loader = "NeoForge"
+)
+
+// Target is what a mod is resolved for. Empty fields match any Minecraft version or loader.
+type Target struct {
+	GameVersion string
+	Loader      Loader
+}
+
+// String describes the target in errors, e.g. "Minecraft 1.21.1 with Fabric".
+func (t Target) String() string {
+	s := "Minecraft"
+	if t.GameVersion != "" {
+		s += " " + t.GameVersion
+	}
+	if t.Loader != "" && t.Loader != LoaderVanilla {
+		s += " with " + string(t.Loader)
+	}
+	return s
+}
+
+const (
+	// DefaultModrinthURL is the base URL of the Modrinth API.
+	DefaultModrinthURL = "https://api.modrinth.com"
+	// DefaultCurseForgeURL is the base URL of the CurseForge API.
+	DefaultCurseForgeURL = "https://api.curseforge.com"
+
+	// maxModpackSize bounds the .mrpack files read for their index, which hold the index and configs.
+	maxModpackSize = 64 << 20
+)
+
+// Mod is a mod to resolve.
+type Mod struct {
+	Source Source
+	// Project is the ID or slug of the Modrinth project, or the numeric ID of the CurseForge mod.
+	Project string
+	// Version is the ID or version number of the Modrinth version, or the ID of the CurseForge file.
+	// The most recent version for the game version is resolved when it is empty.
+	Version string
+	// URL is the download URL of the mod file for SourceURL.
+	URL string
+	// SHA1 is the expected SHA-1 hash of the mod file, if known.
+	SHA1 string
+}
+
+// File is the file a mod resolved to.
+type File struct {
+	// Version is the version of the mod, the version number on Modrinth and the file ID on CurseForge.
+	Version string
+	// Name is the name of the file, safe to use in a path.
+	Name string
+	// URL is where the file is downloaded from.
+	URL string
+	// SHA1 is the SHA-1 hash of the file, if known.
+	SHA1 string
+}
+
+// Resolver resolves mods and modpacks to their files.
+type Resolver struct {
+	// ModrinthURL is the base URL of the Modrinth API.
+	ModrinthURL string
+	// CurseForgeURL is the base URL of the CurseForge API.
+	CurseForgeURL string
+	// CurseForgeAPIKey is the key for the CurseForge API. CurseForge mods are not resolved without one.
+	CurseForgeAPIKey string
+	// Client is the HTTP client used for the APIs.
+	Client *http.Client
+}
+
+// NewResolver returns a Resolver for the public Modrinth and CurseForge APIs.
+func NewResolver(curseForgeAPIKey string) *Resolver {
+	return &Resolver{
+		ModrinthURL:      DefaultModrinthURL,
+		CurseForgeURL:    DefaultCurseForgeURL,
+		CurseForgeAPIKey: curseForgeAPIKey,
+		Client:           &http.Client{Timeout: webapi.DefaultTimeout},
+	}
+}
+
+// Resolve returns the file of mod for the target. A hash given with the mod must match the hash reported by the API.
+func (r *Resolver) Resolve(ctx context.Context, mod Mod, target Target) (File, error) {
+	var (
+		file File
+		err  error
+	)
+	switch mod.Source {
+	case SourceModrinth:
+		file, err = r.resolveModrinth(ctx, mod, target)
+	case SourceCurseForge:
+		file, err = r.resolveCurseForge(ctx, mod, target)
+	case SourceURL:
+		file, err = resolveURL(mod)
+	default:
+		err = fmt.Errorf("unknown mod source %q", mod.Source)
+	}
+	if err != nil {
+		return File{}, err
+	}
+	return checkSHA1(file, mod.SHA1)
+}
+
+// checkSHA1 returns file with the expected hash, which must match the hash reported by the API, if any.
+func checkSHA1(file File, sha1 string) (File, error) {
+	if sha1 != "" {
+		if file.SHA1 != "" && !strings.EqualFold(file.SHA1, sha1) {
+			return File{}, fmt.Errorf("the SHA-1 hash of %s is %s, not %s", file.Name, file.SHA1, sha1)
+		}
+		file.SHA1 = sha1
+	}
+	file.SHA1 = strings.ToLower(file.SHA1)
+	return file, nil
+}
+
+func resolveURL(mod Mod) (File, error) {
+	u, err := url.Parse(mod.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return File{}, fmt.Errorf("invalid mod URL %q", mod.URL)
+	}
+	name, err := FileName(path.Base(u.Path))
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: name, URL: mod.URL}, nil
+}
+
+// unsafeFileNameChars are replaced in file names, which are written into the mods directory by a shell script.
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._+-]`)
+
+// FileName returns name made safe to use as a file name in the mods directory.
+func FileName(name string) (string, error) {
+	safe := strings.TrimLeft(unsafeFileNameChars.ReplaceAllString(name, "-"), ".-")
+	if safe == "" {
+		return "", fmt.Errorf("invalid mod file name %q", name)
+	}
+	return safe, nil
+}
+
+// getJSON decodes the JSON response to a GET request of the API at rawURL into v.
+func (r *Resolver) getJSON(ctx context.Context, rawURL string, header http.Header, v any) error {
+	return webapi.GetJSON(ctx, r.Client, rawURL, header, v)
+}
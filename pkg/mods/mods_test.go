@@ -0,0 +1,184 @@
+package mods
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	sodiumSHA1 = "0123456789abcdef0123456789abcdef01234567"
+	jeiSHA1    = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+// serveModAPIs answers like the Modrinth and CurseForge APIs for the sodium project on Modrinth and mod 238222
+// on CurseForge, whose file 5101366 may not be downloaded outside of CurseForge.
+func serveModAPIs() *httptest.Server {
+	sodium := func(number, gameVersion string) map[string]any {
+		return map[string]any{
+			"id":             "id-" + number,
+			"version_number": number,
+			"game_versions":  []string{gameVersion},
+			"files": []map[string]any{
+				{"url": "https://cdn.modrinth.com/sources.jar", "filename": "sodium-sources.jar", "primary": false},
+				{
+					"url":      "https://cdn.modrinth.com/sodium-" + number + ".jar",
+					"filename": "sodium fabric " + number + ".jar",
+					"primary":  true,
+					"hashes":   map[string]string{"sha1": sodiumSHA1},
+				},
+			},
+		}
+	}
+	jei := func(id int, downloadURL string) map[string]any {
+		return map[string]any{
+			"id":          id,
+			"fileName":    "jei-1.21.1.jar",
+			"downloadUrl": downloadURL,
+			"hashes": []map[string]any{
+				{"value": "d41d8cd98f00b204e9800998ecf8427e", "algo": 2},
+				{"value": jeiSHA1, "algo": 1},
+			},
+		}
+	}
+
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /v2/project/sodium/version", func(w http.ResponseWriter, r *http.Request) {
+		versions := []map[string]any{sodium("0.6.0", "1.21.1"), sodium("0.5.11", "1.21.1")}
+		if gameVersions := r.URL.Query().Get("game_versions"); gameVersions != "" && gameVersions != `["1.21.1"]` {
+			versions = nil
+		}
+		if loaders := r.URL.Query().Get("loaders"); loaders != "" && loaders != `["fabric"]` {
+			versions = nil
+		}
+		writeJSON(w, versions)
+	})
+	mux.HandleFunc("GET /v2/project/sodium/version/0.5.11", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, sodium("0.5.11", "1.21.1"))
+	})
+	curseForge := func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Api-Key") != "key" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			handler(w, r)
+		}
+	}
+	mux.HandleFunc("GET /v1/mods/238222/files", curseForge(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.URL.Query().Get("gameVersion")).To(Equal("1.21.1"))
+		Expect(r.URL.Query().Get("modLoaderType")).To(Equal("6"))
+		writeJSON(w, map[string]any{"data": []map[string]any{jei(5101365, "https://edge.forgecdn.net/jei.jar")}})
+	}))
+	mux.HandleFunc("GET /v1/mods/238222/files/5101366", curseForge(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"data": jei(5101366, "")})
+	}))
+	return httptest.NewServer(mux)
+}
+
+var _ = Describe("Resolver", func() {
+	ctx := context.Background()
+
+	var (
+		server   *httptest.Server
+		resolver *Resolver
+	)
+
+	BeforeEach(func() {
+		server = serveModAPIs()
+		resolver = &Resolver{
+			ModrinthURL:      server.URL,
+			CurseForgeURL:    server.URL,
+			CurseForgeAPIKey: "key",
+			Client:           server.Client(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("resolves the most recent Modrinth version for the Minecraft version", func() {
+		file, err := resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium"}, Target{GameVersion: "1.21.1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(Equal(File{
+			Version: "0.6.0",
+			Name:    "sodium-fabric-0.6.0.jar",
+			URL:     "https://cdn.modrinth.com/sodium-0.6.0.jar",
+			SHA1:    sodiumSHA1,
+		}))
+
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium"}, Target{GameVersion: "1.7.10"})
+		Expect(err).To(MatchError(ContainSubstring("has no version for Minecraft 1.7.10")))
+
+		By("filtering by loader")
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium"},
+			Target{GameVersion: "1.21.1", Loader: LoaderFabric})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium"},
+			Target{GameVersion: "1.21.1", Loader: LoaderForge})
+		Expect(err).To(MatchError(ContainSubstring("has no version for Minecraft 1.21.1 with Forge")))
+	})
+
+	It("resolves pinned Modrinth versions", func() {
+		file, err := resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium", Version: "0.5.11"}, Target{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Version).To(Equal("0.5.11"))
+
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium", Version: "0.1.0"}, Target{})
+		Expect(err).To(MatchError("version 0.1.0 of Modrinth project sodium not found"))
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "lithium"}, Target{})
+		Expect(err).To(MatchError("project lithium not found on Modrinth"))
+	})
+
+	It("resolves CurseForge files with the API key", func() {
+		neoForge := Target{GameVersion: "1.21.1", Loader: LoaderNeoForge}
+		file, err := resolver.Resolve(ctx, Mod{Source: SourceCurseForge, Project: "238222"}, neoForge)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(Equal(File{
+			Version: "5101365",
+			Name:    "jei-1.21.1.jar",
+			URL:     "https://edge.forgecdn.net/jei.jar",
+			SHA1:    jeiSHA1,
+		}))
+
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceCurseForge, Project: "238222", Version: "5101366"}, Target{})
+		Expect(err).To(MatchError(ContainSubstring("does not allow downloading it outside of CurseForge")))
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceCurseForge, Project: "jei"}, Target{})
+		Expect(err).To(MatchError(ContainSubstring("numeric ID")))
+
+		resolver.CurseForgeAPIKey = ""
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceCurseForge, Project: "238222"}, neoForge)
+		Expect(err).To(MatchError(ContainSubstring("no CurseForge API key")))
+	})
+
+	It("takes the file name of URLs from their path", func() {
+		file, err := resolver.Resolve(ctx, Mod{
+			Source: SourceURL,
+			URL:    "https://example.com/mods/../My%20Mod.jar?download=1",
+			SHA1:   sodiumSHA1,
+		}, Target{GameVersion: "1.21.1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file).To(Equal(File{
+			Name: "My-Mod.jar",
+			URL:  "https://example.com/mods/../My%20Mod.jar?download=1",
+			SHA1: sodiumSHA1,
+		}))
+
+		_, err = resolver.Resolve(ctx, Mod{Source: SourceURL, URL: "file:///etc/passwd"}, Target{})
+		Expect(err).To(MatchError(ContainSubstring("invalid mod URL")))
+	})
+
+	It("rejects files whose hash does not match", func() {
+		_, err := resolver.Resolve(ctx, Mod{Source: SourceModrinth, Project: "sodium", SHA1: jeiSHA1},
+			Target{GameVersion: "1.21.1"})
+		Expect(err).To(MatchError(ContainSubstring("the SHA-1 hash of sodium-fabric-0.6.0.jar is")))
+	})
+})
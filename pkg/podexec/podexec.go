@@ -0,0 +1,90 @@
+// Package podexec runs commands in the containers of running pods, like 'kubectl exec'.
+package podexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// Result is the outcome of a command that ran to completion.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Executor runs commands in a container of a pod.
+type Executor interface {
+	// Exec runs the command and waits for it to finish. A non-zero exit code is reported in the result,
+	// an error means the command could not be run or was interrupted, e.g. because ctx expired.
+	Exec(ctx context.Context, namespace, pod, container string, command []string) (Result, error)
+}
+
+type executor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewExecutor returns an Executor using the pods/exec subresource of the API server.
+func NewExecutor(config *rest.Config) (Executor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	return &executor{config: config, clientset: clientset}, nil
+}
+
+func (e *executor) Exec(ctx context.Context, namespace, pod, container string, command []string) (Result, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	// Like kubectl, prefer WebSockets and fall back to SPDY for API servers that do not support them.
+	websocketExec, err := remotecommand.NewWebSocketExecutor(e.config, "GET", req.URL().String())
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create WebSocket executor: %w", err)
+	}
+	spdyExec, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create SPDY executor: %w", err)
+	}
+	exec, err := remotecommand.NewFallbackExecutor(websocketExec, spdyExec, func(err error) bool {
+		return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	result := Result{Stdout: stdout.String(), Stderr: stderr.String()}
+
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+		return result, nil
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		result.ExitCode = exitErr.ExitStatus()
+		return result, nil
+	default:
+		return result, fmt.Errorf("failed to exec in pod %s/%s: %w", namespace, pod, err)
+	}
+}
@@ -0,0 +1,270 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+)
+
+// A2S message headers, see https://developer.valvesoftware.com/wiki/Server_queries.
+const (
+	a2sInfoRequest       byte = 'T'
+	a2sInfoResponse      byte = 'I'
+	a2sPlayerRequest     byte = 'U'
+	a2sPlayerResponse    byte = 'D'
+	a2sChallengeResponse byte = 'A'
+)
+
+const (
+	// a2sSinglePacket and a2sSplitPacket are the headers of responses that fit in a single packet and
+	// responses that are split over multiple packets.
+	a2sSinglePacket int32 = -1
+	a2sSplitPacket  int32 = -2
+
+	// a2sSplitHeaderSize is the size of the header of a split packet: the header, the response id,
+	// the number of packets, the number of this packet and the maximum packet size.
+	a2sSplitHeaderSize = 12
+	// a2sCompressed is set in the response id of split responses that are bzip2 compressed.
+	a2sCompressed uint32 = 0x80000000
+
+	// a2sMaxChallenges bounds the number of challenges answered for a single request.
+	a2sMaxChallenges = 3
+
+	// theShipAppID is the app id of The Ship, whose info response has additional fields.
+	theShipAppID = 2400
+)
+
+var (
+	// a2sInfoPayload is the payload of the A2S_INFO request.
+	a2sInfoPayload = []byte("Source Engine Query\x00")
+	// a2sNoChallenge asks the server for a challenge.
+	a2sNoChallenge = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+
+	errShortResponse = errors.New("query: A2S response is too short")
+)
+
+// A2SInfoRequest returns an A2S_INFO request without a challenge. A server answering queries responds to it
+// with its info or with a challenge.
+func A2SInfoRequest() []byte {
+	return append([]byte{0xFF, 0xFF, 0xFF, 0xFF, a2sInfoRequest}, a2sInfoPayload...)
+}
+
+type a2sConn struct {
+	conn net.Conn
+	buf  []byte
+}
+
+func queryA2S(ctx context.Context, address string) (Info, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return Info{}, fmt.Errorf("query: failed to connect to %s: %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	c := &a2sConn{conn: conn, buf: make([]byte, 65535)}
+	info, err := c.info()
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Players > 0 {
+		// Some games do not answer A2S_PLAYER, the player names are left out for them.
+		if names, err := c.players(); err == nil {
+			info.PlayerNames = names
+		}
+	}
+	return info, nil
+}
+
+// info sends A2S_INFO and parses the Source engine response.
+func (c *a2sConn) info() (Info, error) {
+	r, err := c.request(a2sInfoRequest, a2sInfoPayload, nil, a2sInfoResponse)
+	if err != nil {
+		return Info{}, err
+	}
+
+	r.byte() // protocol
+	info := Info{Name: r.string(), Map: r.string()}
+	r.string() // folder
+	r.string() // game
+	appID := r.uint16()
+	players, maxPlayers, bots := int(r.byte()), int(r.byte()), int(r.byte())
+	r.skip(4) // server type, environment, visibility and VAC
+	if appID == theShipAppID {
+		r.skip(3) // mode, witnesses and duration
+	}
+	info.Version = r.string()
+	if r.err != nil {
+		return Info{}, r.err
+	}
+
+	info.Players = max(players-bots, 0)
+	info.MaxPlayers = maxPlayers
+	return info, nil
+}
+
+// players sends A2S_PLAYER and returns the names of the connected players.
+func (c *a2sConn) players() ([]string, error) {
+	r, err := c.request(a2sPlayerRequest, nil, a2sNoChallenge, a2sPlayerResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(r.byte())
+	names := make([]string, 0, count)
+	for range count {
+		r.byte() // index
+		name := r.string()
+		r.skip(8) // score and duration
+		if r.err != nil {
+			return nil, r.err
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, r.err
+}
+
+// request sends a request and returns a reader for the payload of the expected response. When the server
+// answers with a challenge, the request is sent again with the challenge.
+func (c *a2sConn) request(header byte, payload, challenge []byte, expected byte) (*a2sReader, error) {
+	for range a2sMaxChallenges {
+		req := []byte{0xFF, 0xFF, 0xFF, 0xFF, header}
+		req = append(req, payload...)
+		req = append(req, challenge...)
+		if _, err := c.conn.Write(req); err != nil {
+			return nil, fmt.Errorf("query: failed to send A2S request: %w", err)
+		}
+
+		resp, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		if len(resp) == 0 {
+			return nil, errShortResponse
+		}
+		switch resp[0] {
+		case expected:
+			return &a2sReader{buf: resp[1:]}, nil
+		case a2sChallengeResponse:
+			if len(resp) < 5 {
+				return nil, errShortResponse
+			}
+			challenge = resp[1:5]
+		default:
+			return nil, fmt.Errorf("query: unexpected A2S response type %q", resp[0])
+		}
+	}
+	return nil, errors.New("query: A2S server keeps answering with a challenge")
+}
+
+// read reads a response, reassembling responses that are split over multiple packets.
+func (c *a2sConn) read() ([]byte, error) {
+	var (
+		id       uint32
+		parts    [][]byte
+		received int
+	)
+	for {
+		n, err := c.conn.Read(c.buf)
+		if err != nil {
+			return nil, fmt.Errorf("query: failed to read A2S response: %w", err)
+		}
+		packet := c.buf[:n]
+		if len(packet) < 4 {
+			return nil, errShortResponse
+		}
+
+		switch int32(binary.LittleEndian.Uint32(packet)) {
+		case a2sSinglePacket:
+			return slices.Clone(packet[4:]), nil
+		case a2sSplitPacket:
+			if len(packet) < a2sSplitHeaderSize {
+				return nil, errShortResponse
+			}
+			packetID, total, number := binary.LittleEndian.Uint32(packet[4:]), int(packet[8]), int(packet[9])
+			if packetID&a2sCompressed != 0 {
+				return nil, errors.New("query: compressed A2S responses are not supported")
+			}
+			if parts == nil {
+				id, parts = packetID, make([][]byte, total)
+			}
+			// Packets of an earlier response that arrive late are ignored.
+			if packetID != id || total != len(parts) || number >= total || parts[number] != nil {
+				continue
+			}
+			parts[number] = slices.Clone(packet[a2sSplitHeaderSize:])
+			if received++; received < total {
+				continue
+			}
+
+			// The reassembled payload starts with the header of a single packet.
+			resp := bytes.Join(parts, nil)
+			if len(resp) < 4 || int32(binary.LittleEndian.Uint32(resp)) != a2sSinglePacket {
+				return nil, errors.New("query: invalid split A2S response")
+			}
+			return resp[4:], nil
+		default:
+			return nil, errors.New("query: invalid A2S response header")
+		}
+	}
+}
+
+// a2sReader reads the fields of an A2S response. Reading past the end of the response sets err.
+type a2sReader struct {
+	buf []byte
+	err error
+}
+
+func (r *a2sReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = errShortResponse
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *a2sReader) skip(n int) {
+	r.next(n)
+}
+
+func (r *a2sReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *a2sReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+// string reads a null-terminated string.
+func (r *a2sReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.buf, 0)
+	if end < 0 {
+		r.err = errShortResponse
+		return ""
+	}
+	s := string(r.buf[:end])
+	r.buf = r.buf[end+1:]
+	return s
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mcproto"
+)
+
+// Minecraft Server List Ping, see https://minecraft.wiki/w/Java_Edition_protocol/Server_List_Ping.
+const (
+	// minecraftAnyProtocolVersion is sent as the protocol version, servers answer the status of any version.
+	minecraftAnyProtocolVersion int32 = -1
+
+	// maxMinecraftPacketSize bounds the size of the status response, which may include a server icon.
+	maxMinecraftPacketSize = 1 << 20
+)
+
+// minecraftFormatting matches the formatting codes in Minecraft texts, e.g. '§a'.
+var minecraftFormatting = regexp.MustCompile(`§.`)
+
+// minecraftStatus is the status response of a Minecraft server.
+type minecraftStatus struct {
+	Version struct {
+		Name string `json:"name"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		Sample []struct {
+			Name string `json:"name"`
+		} `json:"sample"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+}
+
+// minecraftText is a text component, see https://minecraft.wiki/w/Text_component_format.
+type minecraftText struct {
+	Text  string            `json:"text"`
+	Extra []json.RawMessage `json:"extra"`
+}
+
+func queryMinecraft(ctx context.Context, address string) (Info, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return Info{}, fmt.Errorf("query: invalid address %s: %w", address, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return Info{}, fmt.Errorf("query: invalid port in address %s: %w", address, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Info{}, fmt.Errorf("query: failed to connect to %s: %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write(MinecraftStatusRequest(host, uint16(port))); err != nil {
+		return Info{}, fmt.Errorf("query: failed to send status request: %w", err)
+	}
+
+	body, err := readMinecraftStatus(bufio.NewReader(conn))
+	if err != nil {
+		return Info{}, err
+	}
+	var status minecraftStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return Info{}, fmt.Errorf("query: invalid status response: %w", err)
+	}
+
+	info := Info{
+		Name:       strings.TrimSpace(minecraftFormatting.ReplaceAllString(plainText(status.Description), "")),
+		Version:    status.Version.Name,
+		Players:    status.Players.Online,
+		MaxPlayers: status.Players.Max,
+	}
+	for _, player := range status.Players.Sample {
+		if player.Name != "" {
+			info.PlayerNames = append(info.PlayerNames, player.Name)
+		}
+	}
+	return info, nil
+}
+
+// MinecraftStatusRequest returns the handshake and status request of the Server List Ping of the server at
+// host and port. The server responds to it with its status.
+func MinecraftStatusRequest(host string, port uint16) []byte {
+	handshake := mcproto.AppendVarInt(nil, minecraftAnyProtocolVersion)
+	handshake = mcproto.AppendString(handshake, host)
+	handshake = binary.BigEndian.AppendUint16(handshake, port)
+	handshake = mcproto.AppendVarInt(handshake, mcproto.StatusState)
+	return append(mcproto.Packet(mcproto.HandshakePacket, handshake), mcproto.Packet(mcproto.StatusPacket, nil)...)
+}
+
+// readMinecraftStatus reads the status response packet and returns its JSON body.
+func readMinecraftStatus(r *bufio.Reader) ([]byte, error) {
+	packet, err := mcproto.ReadPacket(r, mcproto.StatusPacket, maxMinecraftPacketSize)
+	if err != nil {
+		return nil, fmt.Errorf("query: failed to read status response: %w", err)
+	}
+	body, err := mcproto.ReadString(packet)
+	if err != nil {
+		return nil, fmt.Errorf("query: invalid status response: %w", err)
+	}
+	return []byte(body), nil
+}
+
+// plainText returns the text of a text component, which is either a string, an object or a list of components.
+func plainText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		var b strings.Builder
+		for _, c := range list {
+			b.WriteString(plainText(c))
+		}
+		return b.String()
+	}
+
+	var text minecraftText
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(text.Text)
+	for _, c := range text.Extra {
+		b.WriteString(plainText(c))
+	}
+	return b.String()
+}
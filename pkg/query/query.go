@@ -0,0 +1,69 @@
+// Package query queries game servers for their name, map, players and version, speaking the Steam A2S
+// queries and the Minecraft Server List Ping.
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Protocol is a server query protocol.
+type Protocol string
+
+const (
+	// ProtocolA2S is the Steam server query protocol (A2S_INFO and A2S_PLAYER), spoken over UDP by
+	// Source engine games and most other games published on Steam.
+	ProtocolA2S Protocol = "A2S"
+	// ProtocolMinecraft is the Minecraft Server List Ping, spoken over TCP on the game port.
+	ProtocolMinecraft Protocol = "Minecraft"
+)
+
+// Network returns the network the protocol is spoken over, "udp" or "tcp".
+func (p Protocol) Network() string {
+	if p == ProtocolA2S {
+		return "udp"
+	}
+	return "tcp"
+}
+
+// defaultTimeout bounds a query when the context has no deadline, as lost UDP packets are never answered.
+const defaultTimeout = 5 * time.Second
+
+// Info is what a game server reports about itself.
+type Info struct {
+	// Name is the name of the server as shown in the server browser.
+	Name string
+	// Map is the map or world that is loaded, if the protocol reports it.
+	Map string
+	// Version is the version of the game.
+	Version string
+	// Players is the number of connected players, excluding bots.
+	Players int
+	// MaxPlayers is the number of player slots.
+	MaxPlayers int
+	// PlayerNames lists the names of connected players as far as the server reports them. Minecraft only
+	// reports a sample of the players, and players that are still connecting have no name yet.
+	PlayerNames []string
+}
+
+// Querier queries the game server at address, e.g. 'example.default.svc:27015'.
+type Querier func(ctx context.Context, protocol Protocol, address string) (Info, error)
+
+// Query queries the game server at address using protocol.
+func Query(ctx context.Context, protocol Protocol, address string) (Info, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+
+	switch protocol {
+	case ProtocolA2S:
+		return queryA2S(ctx, address)
+	case ProtocolMinecraft:
+		return queryMinecraft(ctx, address)
+	default:
+		return Info{}, fmt.Errorf("query: unsupported protocol %q", protocol)
+	}
+}
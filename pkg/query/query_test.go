@@ -0,0 +1,140 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mcproto"
+)
+
+// serveA2S answers A2S queries like a Source engine server: every request is challenged first,
+// and the player list is split over two packets that are sent in reverse order.
+func serveA2S(conn net.PacketConn) {
+	challenge := []byte{0x01, 0x02, 0x03, 0x04}
+	single := func(payload ...[]byte) []byte {
+		return append([]byte{0xFF, 0xFF, 0xFF, 0xFF}, bytes.Join(payload, nil)...)
+	}
+	split := func(number byte, payload []byte) []byte {
+		return append([]byte{0xFE, 0xFF, 0xFF, 0xFF, 0x2A, 0x00, 0x00, 0x00, 2, number, 0xE0, 0x04}, payload...)
+	}
+	player := func(name string) []byte {
+		return append(append([]byte{0}, name...), 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	}
+
+	buf := make([]byte, 1400)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		if !bytes.HasSuffix(req, challenge) {
+			_, _ = conn.WriteTo(single([]byte{'A'}, challenge), addr)
+			continue
+		}
+		switch req[4] {
+		case 'T':
+			_, _ = conn.WriteTo(single(
+				[]byte{'I', 17},
+				[]byte("Example server\x00de_dust2\x00csgo\x00Counter-Strike\x00"),
+				[]byte{0xDA, 0x02, 5, 20, 1, 'd', 'l', 0, 1},
+				[]byte("1.38.7.9\x00"),
+			), addr)
+		case 'U':
+			players := single([]byte{'D', 3}, player("alice"), player(""), player("bob"))
+			_, _ = conn.WriteTo(split(1, players[20:]), addr)
+			_, _ = conn.WriteTo(split(0, players[:20]), addr)
+		}
+	}
+}
+
+// serveMinecraft answers the Server List Ping like a Minecraft server.
+func serveMinecraft(listener net.Listener, status string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer GinkgoRecover()
+			defer func() { _ = conn.Close() }()
+
+			r := bufio.NewReader(conn)
+			for range 2 {
+				size, err := mcproto.ReadVarInt(r)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = r.Discard(int(size))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err := conn.Write(mcproto.Packet(mcproto.StatusPacket, mcproto.AppendString(nil, status)))
+			Expect(err).NotTo(HaveOccurred())
+		}()
+	}
+}
+
+var _ = Describe("Query", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	It("queries the info and players over A2S", func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		go serveA2S(conn)
+
+		Expect(Query(ctx, ProtocolA2S, conn.LocalAddr().String())).To(Equal(Info{
+			Name:        "Example server",
+			Map:         "de_dust2",
+			Version:     "1.38.7.9",
+			Players:     4,
+			MaxPlayers:  20,
+			PlayerNames: []string{"alice", "bob"},
+		}))
+	})
+
+	It("queries the status of a Minecraft server", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(listener.Close)
+		go serveMinecraft(listener, `{
+			"version": {"name": "1.21.4", "protocol": 769},
+			"players": {"max": 20, "online": 2, "sample": [{"name": "alice", "id": "4566e69f-c907-48ee-8d71-d7ba5aa00d20"}]},
+			"description": {"text": "§aExample ", "extra": [{"text": "server"}, "!"]}
+		}`)
+
+		Expect(Query(ctx, ProtocolMinecraft, listener.Addr().String())).To(Equal(Info{
+			Name:        "Example server!",
+			Version:     "1.21.4",
+			Players:     2,
+			MaxPlayers:  20,
+			PlayerNames: []string{"alice"},
+		}))
+	})
+
+	It("times out when an A2S server does not answer", func() {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = Query(ctx, ProtocolA2S, conn.LocalAddr().String())
+		Expect(err).To(MatchError(ContainSubstring("failed to read A2S response")))
+	})
+
+	It("rejects unknown protocols", func() {
+		_, err := Query(ctx, "GameSpy", "127.0.0.1:1")
+		Expect(err).To(MatchError(ContainSubstring("unsupported protocol")))
+	})
+})
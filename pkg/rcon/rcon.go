@@ -0,0 +1,44 @@
+// Package rcon is a client for the remote consoles of game servers, speaking Source RCON and Rust WebRCON.
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Protocol is an RCON protocol.
+type Protocol string
+
+const (
+	// ProtocolSource is the Source RCON protocol, also spoken by Minecraft, Factorio and ARK.
+	ProtocolSource Protocol = "Source"
+	// ProtocolWebRCON is the WebSocket based RCON protocol of Rust.
+	ProtocolWebRCON Protocol = "WebRCON"
+)
+
+// ErrAuthFailed is returned when the game server rejects the password.
+var ErrAuthFailed = errors.New("rcon: authentication failed")
+
+// Client runs commands on the remote console of a game server. A Client is not safe for concurrent use.
+type Client interface {
+	// Execute runs the command and returns its response.
+	Execute(ctx context.Context, command string) (string, error)
+	// Close closes the connection.
+	Close() error
+}
+
+// Dialer connects to the remote console at address, e.g. '10.0.0.1:25575', and authenticates with password.
+type Dialer func(ctx context.Context, protocol Protocol, address, password string) (Client, error)
+
+// Dial connects to the remote console at address using protocol and authenticates with password.
+func Dial(ctx context.Context, protocol Protocol, address, password string) (Client, error) {
+	switch protocol {
+	case ProtocolSource:
+		return dialSource(ctx, address, password)
+	case ProtocolWebRCON:
+		return dialWebRCON(ctx, address, password)
+	default:
+		return nil, fmt.Errorf("rcon: unsupported protocol %q", protocol)
+	}
+}
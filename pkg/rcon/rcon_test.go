@@ -0,0 +1,161 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// serveSource answers Source RCON packets like a Source engine server, splitting long responses
+// over multiple packets and mirroring the empty response value packet.
+func serveSource(listener net.Listener, password string, responses map[string]string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer GinkgoRecover()
+			defer func() { _ = conn.Close() }()
+
+			c := &sourceClient{conn: conn, reader: bufio.NewReader(conn)}
+			for {
+				p, err := c.read()
+				if err != nil {
+					return
+				}
+				switch p.typ {
+				case packetTypeAuth:
+					id := p.id
+					if p.body != password {
+						id = authFailedID
+					}
+					Expect(c.write(packet{id: p.id, typ: packetTypeResponseValue})).To(Succeed())
+					Expect(c.write(packet{id: id, typ: packetTypeAuthResponse})).To(Succeed())
+				case packetTypeExecCommand:
+					response := responses[p.body]
+					for len(response) > 10 {
+						Expect(c.write(packet{id: p.id, typ: packetTypeResponseValue, body: response[:10]})).To(Succeed())
+						response = response[10:]
+					}
+					Expect(c.write(packet{id: p.id, typ: packetTypeResponseValue, body: response})).To(Succeed())
+				case packetTypeResponseValue:
+					Expect(c.write(packet{id: p.id, typ: packetTypeResponseValue})).To(Succeed())
+					Expect(c.write(packet{id: p.id, typ: packetTypeResponseValue, body: "\x00\x01"})).To(Succeed())
+				}
+			}
+		}()
+	}
+}
+
+// serveWebRCON answers WebRCON commands like a Rust server, sending a chat message before every response.
+func serveWebRCON(password string, responses map[string]string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			var msg webRCONMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			_ = conn.WriteJSON(webRCONMessage{Identifier: -1, Message: "[CHAT] player: hi", Type: "Chat"})
+			_ = conn.WriteJSON(webRCONMessage{Identifier: msg.Identifier, Message: responses[msg.Message], Type: "Generic"})
+		}
+	}))
+}
+
+var _ = Describe("RCON", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		DeferCleanup(cancel)
+	})
+
+	Context("Source RCON", func() {
+		var address string
+
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(listener.Close)
+			address = listener.Addr().String()
+
+			go serveSource(listener, "secret", map[string]string{
+				"list":    "There are 0 of a max of 20 players online: ",
+				"save-on": "Automatic saving is now enabled",
+			})
+		})
+
+		It("runs commands and joins split responses", func() {
+			client, err := Dial(ctx, ProtocolSource, address, "secret")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(client.Close)
+
+			Expect(client.Execute(ctx, "list")).To(Equal("There are 0 of a max of 20 players online: "))
+			Expect(client.Execute(ctx, "save-on")).To(Equal("Automatic saving is now enabled"))
+			Expect(client.Execute(ctx, "unknown")).To(BeEmpty())
+		})
+
+		It("rejects a wrong password", func() {
+			_, err := Dial(ctx, ProtocolSource, address, "wrong")
+			Expect(err).To(MatchError(ErrAuthFailed))
+		})
+
+		It("rejects commands that are too long", func() {
+			client, err := Dial(ctx, ProtocolSource, address, "secret")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(client.Close)
+
+			_, err = client.Execute(ctx, strings.Repeat("a", maxCommandSize+1))
+			Expect(err).To(MatchError(ContainSubstring("longer than")))
+		})
+	})
+
+	Context("WebRCON", func() {
+		var address string
+
+		BeforeEach(func() {
+			server := serveWebRCON("se cret", map[string]string{"server.save": "Saved 1234 ents"})
+			DeferCleanup(server.Close)
+			address = strings.TrimPrefix(server.URL, "http://")
+		})
+
+		It("runs commands and skips unrelated messages", func() {
+			client, err := Dial(ctx, ProtocolWebRCON, address, "se cret")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(client.Close)
+
+			Expect(client.Execute(ctx, "server.save")).To(Equal("Saved 1234 ents"))
+			Expect(client.Execute(ctx, "status")).To(BeEmpty())
+		})
+
+		It("rejects a wrong password", func() {
+			_, err := Dial(ctx, ProtocolWebRCON, address, "wrong")
+			Expect(err).To(MatchError(ErrAuthFailed))
+		})
+	})
+
+	It("rejects unknown protocols", func() {
+		_, err := Dial(ctx, "Telnet", "127.0.0.1:1", "secret")
+		Expect(err).To(MatchError(ContainSubstring("unsupported protocol")))
+	})
+})
@@ -0,0 +1,172 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Source RCON packet types, see https://developer.valvesoftware.com/wiki/Source_RCON_Protocol.
+// The auth response and exec command types share the same value.
+const (
+	packetTypeResponseValue int32 = 0
+	packetTypeExecCommand   int32 = 2
+	packetTypeAuthResponse  int32 = 2
+	packetTypeAuth          int32 = 3
+)
+
+const (
+	// packetHeaderSize is the size of the id and type fields, packetPaddingSize the two null bytes ending a packet.
+	packetHeaderSize  = 8
+	packetPaddingSize = 2
+
+	// maxPacketSize bounds the size of received packets. Servers split longer responses over multiple packets.
+	maxPacketSize = 4096 + packetHeaderSize + packetPaddingSize
+
+	// maxCommandSize is the longest command Minecraft accepts, the strictest of the supported games.
+	maxCommandSize = 1446
+
+	// authFailedID is the id of the auth response when the password was wrong.
+	authFailedID int32 = -1
+)
+
+type packet struct {
+	id   int32
+	typ  int32
+	body string
+}
+
+type sourceClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int32
+}
+
+func dialSource(ctx context.Context, address, password string) (Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("rcon: failed to connect to %s: %w", address, err)
+	}
+
+	c := &sourceClient{conn: conn, reader: bufio.NewReader(conn), nextID: 1}
+	if err := c.authenticate(ctx, password); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *sourceClient) authenticate(ctx context.Context, password string) error {
+	defer c.setDeadline(ctx)()
+
+	id := c.newID()
+	if err := c.write(packet{id: id, typ: packetTypeAuth, body: password}); err != nil {
+		return err
+	}
+	// Source servers send an empty response value before the auth response, Minecraft does not.
+	for {
+		p, err := c.read()
+		if err != nil {
+			return err
+		}
+		if p.typ != packetTypeAuthResponse {
+			continue
+		}
+		if p.id == authFailedID {
+			return ErrAuthFailed
+		}
+		if p.id == id {
+			return nil
+		}
+	}
+}
+
+// Execute sends the command followed by an empty response value packet. Servers answer packets in order,
+// so the response to the empty packet marks the end of a response that was split over multiple packets.
+func (c *sourceClient) Execute(ctx context.Context, command string) (string, error) {
+	if len(command) > maxCommandSize {
+		return "", fmt.Errorf("rcon: command is longer than %d bytes", maxCommandSize)
+	}
+	defer c.setDeadline(ctx)()
+
+	id, endID := c.newID(), c.newID()
+	if err := c.write(packet{id: id, typ: packetTypeExecCommand, body: command}); err != nil {
+		return "", err
+	}
+	if err := c.write(packet{id: endID, typ: packetTypeResponseValue}); err != nil {
+		return "", err
+	}
+
+	var response strings.Builder
+	for {
+		p, err := c.read()
+		if err != nil {
+			return "", err
+		}
+		switch p.id {
+		case id:
+			response.WriteString(p.body)
+		case endID:
+			return response.String(), nil
+		}
+	}
+}
+
+func (c *sourceClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sourceClient) newID() int32 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+// setDeadline applies the deadline of ctx to the connection and returns a function clearing it again.
+func (c *sourceClient) setDeadline(ctx context.Context) func() {
+	deadline, _ := ctx.Deadline()
+	_ = c.conn.SetDeadline(deadline)
+	return func() { _ = c.conn.SetDeadline(time.Time{}) }
+}
+
+func (c *sourceClient) write(p packet) error {
+	buf := make([]byte, 4+packetHeaderSize+len(p.body)+packetPaddingSize)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(packetHeaderSize+len(p.body)+packetPaddingSize))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(p.id))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(p.typ))
+	copy(buf[12:], p.body)
+	if _, err := c.conn.Write(buf); err != nil {
+		return fmt.Errorf("rcon: failed to send packet: %w", err)
+	}
+	return nil
+}
+
+func (c *sourceClient) read() (packet, error) {
+	var size int32
+	if err := binary.Read(c.reader, binary.LittleEndian, &size); err != nil {
+		return packet{}, fmt.Errorf("rcon: failed to read packet: %w", err)
+	}
+	if size < packetHeaderSize+packetPaddingSize || size > maxPacketSize {
+		return packet{}, fmt.Errorf("rcon: invalid packet size %d", size)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(c.reader, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return packet{}, fmt.Errorf("rcon: failed to read packet: %w", err)
+	}
+	return packet{
+		id:   int32(binary.LittleEndian.Uint32(buf[0:])),
+		typ:  int32(binary.LittleEndian.Uint32(buf[4:])),
+		body: strings.TrimRight(string(buf[packetHeaderSize:]), "\x00"),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// webRCONName identifies the operator in the console of the game server.
+const webRCONName = "gameserver-operator"
+
+// webRCONMessage is the JSON message exchanged with a WebRCON server. Responses carry the identifier of
+// the command they answer, messages the server sends on its own, such as chat and log lines, do not.
+type webRCONMessage struct {
+	Identifier int    `json:"Identifier"`
+	Message    string `json:"Message"`
+	Name       string `json:"Name,omitempty"`
+	Type       string `json:"Type,omitempty"`
+}
+
+type webRCONClient struct {
+	conn   *websocket.Conn
+	nextID int
+}
+
+func dialWebRCON(ctx context.Context, address, password string) (Client, error) {
+	// The password is the path of the URL, the server refuses the upgrade when it is wrong.
+	u := url.URL{Scheme: "ws", Host: address, Path: "/" + password}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return nil, ErrAuthFailed
+		}
+		return nil, fmt.Errorf("rcon: failed to connect to %s: %w", address, err)
+	}
+	return &webRCONClient{conn: conn, nextID: 1}, nil
+}
+
+func (c *webRCONClient) Execute(ctx context.Context, command string) (string, error) {
+	deadline, _ := ctx.Deadline()
+	_ = c.conn.SetWriteDeadline(deadline)
+	_ = c.conn.SetReadDeadline(deadline)
+	defer func() {
+		_ = c.conn.SetWriteDeadline(time.Time{})
+		_ = c.conn.SetReadDeadline(time.Time{})
+	}()
+
+	id := c.nextID
+	c.nextID++
+	if err := c.conn.WriteJSON(webRCONMessage{Identifier: id, Message: command, Name: webRCONName}); err != nil {
+		return "", fmt.Errorf("rcon: failed to send command: %w", err)
+	}
+
+	for {
+		var msg webRCONMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return "", fmt.Errorf("rcon: failed to read response: %w", err)
+		}
+		if msg.Identifier == id {
+			return msg.Message, nil
+		}
+	}
+}
+
+func (c *webRCONClient) Close() error {
+	return c.conn.Close()
+}
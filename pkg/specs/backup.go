@@ -0,0 +1,253 @@
+package specs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	batchv1ac "k8s.io/client-go/applyconfigurations/batch/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/utils"
+)
+
+const (
+	// backupImage ships the archives. rclone supports both local paths and S3-compatible object stores,
+	// and its Alpine based image provides the shell, tar and sha256sum used by the backup script.
+	backupImage = "rclone/rclone:1.71.1"
+
+	backupContainerName     = "backup"
+	backupTargetVolumeName  = "backup-target"
+	backupTmpVolumeName     = "tmp"
+	backupTargetMountPath   = "/backups"
+	defaultBackupRetention  = 7
+	backupJobBackoffLimit   = 1
+	backupJobsHistoryLimit  = 3
+	backupS3Remote          = "s3"
+	backupS3AccessKeyIDKey  = "AWS_ACCESS_KEY_ID"
+	backupS3SecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+)
+
+// BackupJobDeadline bounds how long backup and restore Jobs may run, including the time their pod waits to be
+// scheduled and started, so a pod that never starts does not keep the game server stopped.
+const BackupJobDeadline = 4 * time.Hour
+
+// backupScript archives the data volume, uploads the archive and its checksum to the target and prunes
+// old backups. The archive is described in the termination message, which the controller records in
+// the GameServer status. Archive names sort chronologically, which the pruning relies on.
+const backupScript = `set -eu
+name="${GAMESERVER_NAME}-$(date -u +%Y%m%d%H%M%S).tar.gz"
+mkdir -p /tmp/backup
+tar -czf "/tmp/backup/${name}" -C /data .
+sha256="$(sha256sum "/tmp/backup/${name}" | cut -d ' ' -f 1)"
+size="$(stat -c %s "/tmp/backup/${name}")"
+echo "${sha256}  ${name}" > "/tmp/backup/${name}.sha256"
+rclone copy /tmp/backup "${BACKUP_TARGET}"
+rclone lsf --files-only --include "${GAMESERVER_NAME}-*.tar.gz" "${BACKUP_TARGET}" | sort -r |
+  tail -n +$((BACKUP_RETENTION + 1)) | while read -r old; do
+    rclone deletefile "${BACKUP_TARGET}/${old}"
+    rclone deletefile "${BACKUP_TARGET}/${old}.sha256" || true
+  done
+printf '{"name":"%s","sizeBytes":%s,"sha256":"%s"}' "${name}" "${size}" "${sha256}" > /dev/termination-log
+`
+
+// GameServerBackupCronJobName returns the name of the CronJob taking scheduled backups of the game server.
+func GameServerBackupCronJobName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-backup", gs.Name)
+}
+
+// GameServerFinalBackupJobName returns the name of the Job taking a backup before the GameServer is deleted.
+func GameServerFinalBackupJobName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-final-backup", gs.Name)
+}
+
+// GameServerBackupLabels returns the labels of backup Jobs and their pods.
+// They deliberately differ from the game server labels, so the StatefulSet and Service never select backup pods.
+func GameServerBackupLabels(gs *gamesv1alpha1.GameServer) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":   gs.Name,
+		"app.kubernetes.io/managed-by": utils.GameServerControllerName,
+		"app.kubernetes.io/component":  "backup",
+	}
+}
+
+// BuildGameServerBackupCronJob builds the CronJob taking scheduled backups, or returns nil when
+// Archive backups are not configured.
+func BuildGameServerBackupCronJob(gs *gamesv1alpha1.GameServer) *batchv1ac.CronJobApplyConfiguration {
+	backup := gs.Spec.Backup
+	if backup == nil || GameServerBackupMode(gs) != gamesv1alpha1.BackupModeArchive {
+		return nil
+	}
+
+	cronJobSpec := batchv1ac.CronJobSpec().
+		WithSchedule(backup.Schedule).
+		WithSuspend(backup.Suspend).
+		WithConcurrencyPolicy(batchv1.ForbidConcurrent).
+		WithSuccessfulJobsHistoryLimit(backupJobsHistoryLimit).
+		WithFailedJobsHistoryLimit(backupJobsHistoryLimit).
+		WithJobTemplate(batchv1ac.JobTemplateSpec().
+			WithLabels(GameServerBackupLabels(gs)).
+			WithSpec(buildBackupJobSpec(gs)),
+		)
+
+	if backup.TimeZone != nil {
+		cronJobSpec.WithTimeZone(*backup.TimeZone)
+	}
+
+	return batchv1ac.CronJob(GameServerBackupCronJobName(gs), gs.Namespace).
+		WithLabels(GameServerBackupLabels(gs)).
+		WithSpec(cronJobSpec)
+}
+
+// BuildGameServerFinalBackupJob builds the Job taking a backup before the GameServer is deleted,
+// or returns nil when Archive backups are not configured.
+func BuildGameServerFinalBackupJob(gs *gamesv1alpha1.GameServer) *batchv1ac.JobApplyConfiguration {
+	if gs.Spec.Backup == nil || GameServerBackupMode(gs) != gamesv1alpha1.BackupModeArchive {
+		return nil
+	}
+
+	return batchv1ac.Job(GameServerFinalBackupJobName(gs), gs.Namespace).
+		WithLabels(GameServerBackupLabels(gs)).
+		WithSpec(buildBackupJobSpec(gs))
+}
+
+func buildBackupJobSpec(gs *gamesv1alpha1.GameServer) *batchv1ac.JobSpecApplyConfiguration {
+	return batchv1ac.JobSpec().
+		WithBackoffLimit(backupJobBackoffLimit).
+		WithActiveDeadlineSeconds(int64(BackupJobDeadline.Seconds())).
+		WithTemplate(corev1ac.PodTemplateSpec().
+			WithLabels(GameServerBackupLabels(gs)).
+			WithSpec(buildBackupPodSpec(gs)),
+		)
+}
+
+func buildBackupPodSpec(gs *gamesv1alpha1.GameServer) *corev1ac.PodSpecApplyConfiguration {
+	backup := gs.Spec.Backup
+
+	retention := int32(defaultBackupRetention)
+	if backup.Retention > 0 {
+		retention = backup.Retention
+	}
+
+	container := corev1ac.Container().
+		WithName(backupContainerName).
+		WithImage(backupImage).
+		WithImagePullPolicy(v1.PullIfNotPresent).
+		WithCommand("/bin/sh", "-c", backupScript).
+		WithSecurityContext(restrictedContainerSecurityContext().
+			WithReadOnlyRootFilesystem(true),
+		).
+		WithEnv(
+			corev1ac.EnvVar().WithName("GAMESERVER_NAME").WithValue(gs.Name),
+			corev1ac.EnvVar().WithName("BACKUP_RETENTION").WithValue(strconv.Itoa(int(retention))),
+			corev1ac.EnvVar().WithName("HOME").WithValue("/tmp"),
+		).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().WithName(dataVolumeName).WithMountPath("/data").WithReadOnly(true),
+			corev1ac.VolumeMount().WithName(backupTmpVolumeName).WithMountPath("/tmp"),
+		)
+
+	podSpec := corev1ac.PodSpec().
+		WithRestartPolicy(v1.RestartPolicyNever).
+		WithAutomountServiceAccountToken(false).
+		WithSecurityContext(linuxGSMPodSecurityContext()).
+		// The data volume may only be read once the game server stopped and saved the world.
+		WithAffinity(gameServerAntiAffinity(gs)).
+		WithVolumes(
+			corev1ac.Volume().
+				WithName(dataVolumeName).
+				WithPersistentVolumeClaim(corev1ac.PersistentVolumeClaimVolumeSource().
+					WithClaimName(GameServerDataVolumeClaimName(gs)).
+					WithReadOnly(true),
+				),
+			corev1ac.Volume().
+				WithName(backupTmpVolumeName).
+				WithEmptyDir(corev1ac.EmptyDirVolumeSource()),
+		)
+
+	withBackupTarget(gs, backup.Target, container, podSpec)
+
+	return podSpec.WithContainers(container)
+}
+
+// gameServerAntiAffinity keeps backup and restore pods away from the game server pod. They cannot be
+// scheduled next to it, and ReadWriteOnce volumes cannot be attached to another node while it runs,
+// so they only start once the game server pod is gone.
+func gameServerAntiAffinity(gs *gamesv1alpha1.GameServer) *corev1ac.AffinityApplyConfiguration {
+	return corev1ac.Affinity().
+		WithPodAntiAffinity(corev1ac.PodAntiAffinity().
+			WithRequiredDuringSchedulingIgnoredDuringExecution(corev1ac.PodAffinityTerm().
+				WithLabelSelector(metav1ac.LabelSelector().WithMatchLabels(gameServerLabels(gs))).
+				WithTopologyKey(v1.LabelHostname),
+			),
+		)
+}
+
+// withBackupTarget makes the backup target available to the container as BACKUP_TARGET, an rclone path
+// to the directory holding the archives of the game server.
+func withBackupTarget(
+	gs *gamesv1alpha1.GameServer,
+	target gamesv1alpha1.BackupTarget,
+	container *corev1ac.ContainerApplyConfiguration,
+	podSpec *corev1ac.PodSpecApplyConfiguration,
+) {
+	switch {
+	case target.PersistentVolumeClaim != nil:
+		pvcTarget := target.PersistentVolumeClaim
+		path := pvcTarget.Path
+		if path == "" {
+			path = gs.Name
+		}
+
+		container.
+			WithEnv(corev1ac.EnvVar().WithName("BACKUP_TARGET").WithValue(fmt.Sprintf("%s/%s", backupTargetMountPath, path))).
+			WithVolumeMounts(corev1ac.VolumeMount().WithName(backupTargetVolumeName).WithMountPath(backupTargetMountPath))
+		podSpec.WithVolumes(corev1ac.Volume().
+			WithName(backupTargetVolumeName).
+			WithPersistentVolumeClaim(corev1ac.PersistentVolumeClaimVolumeSource().
+				WithClaimName(pvcTarget.ClaimName),
+			),
+		)
+	case target.S3 != nil:
+		container.WithEnv(buildBackupS3Env(gs, target.S3)...)
+	}
+}
+
+// buildBackupS3Env configures an rclone remote for the S3 target through environment variables,
+// so no rclone config file has to be written.
+func buildBackupS3Env(
+	gs *gamesv1alpha1.GameServer,
+	target *gamesv1alpha1.S3BackupTarget,
+) []*corev1ac.EnvVarApplyConfiguration {
+	prefix := target.Prefix
+	if prefix == "" {
+		prefix = fmt.Sprintf("%s/%s", gs.Namespace, gs.Name)
+	}
+
+	secretEnv := func(key string) *corev1ac.EnvVarApplyConfiguration {
+		return corev1ac.EnvVar().
+			WithName(key).
+			WithValueFrom(corev1ac.EnvVarSource().
+				WithSecretKeyRef(corev1ac.SecretKeySelector().
+					WithName(target.CredentialsSecretRef.Name).
+					WithKey(key),
+				),
+			)
+	}
+
+	return []*corev1ac.EnvVarApplyConfiguration{
+		corev1ac.EnvVar().WithName("BACKUP_TARGET").WithValue(fmt.Sprintf("%s:%s/%s", backupS3Remote, target.Bucket, prefix)),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_TYPE").WithValue("s3"),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_PROVIDER").WithValue("Other"),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_ENV_AUTH").WithValue("true"),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_ENDPOINT").WithValue(target.Endpoint),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_REGION").WithValue(target.Region),
+		corev1ac.EnvVar().WithName("RCLONE_CONFIG_S3_FORCE_PATH_STYLE").WithValue("true"),
+		secretEnv(backupS3AccessKeyIDKey),
+		secretEnv(backupS3SecretAccessKey),
+	}
+}
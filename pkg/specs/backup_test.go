@@ -0,0 +1,108 @@
+package specs_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Backup spec builders", func() {
+	withBackup := func(target gamesv1alpha1.BackupTarget) func(*gamesv1alpha1.GameServer) {
+		return func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.Backup = &gamesv1alpha1.BackupSpec{
+				Schedule:  "0 4 * * *",
+				TimeZone:  new("Europe/Amsterdam"),
+				Retention: 3,
+				Target:    target,
+			}
+		}
+	}
+
+	envValue := func(container corev1ac.ContainerApplyConfiguration, name string) string {
+		GinkgoHelper()
+		for _, env := range container.Env {
+			if *env.Name == name {
+				Expect(env.Value).NotTo(BeNil(), "env %s has no value", name)
+				return *env.Value
+			}
+		}
+		Fail("env " + name + " not found")
+		return ""
+	}
+
+	It("returns nil when backups are not configured", func() {
+		Expect(specs.BuildGameServerBackupCronJob(newGameServer())).To(BeNil())
+		Expect(specs.BuildGameServerFinalBackupJob(newGameServer())).To(BeNil())
+	})
+
+	It("builds a CronJob that backs up the data volume to a persistent volume claim", func() {
+		gs := newGameServer(withBackup(gamesv1alpha1.BackupTarget{
+			PersistentVolumeClaim: &gamesv1alpha1.PersistentVolumeClaimBackupTarget{ClaimName: "backups"},
+		}))
+
+		cronJob := specs.BuildGameServerBackupCronJob(gs)
+		Expect(cronJob).NotTo(BeNil())
+		Expect(*cronJob.Name).To(Equal("example-backup"))
+		Expect(cronJob.Spec.Schedule).To(HaveValue(Equal("0 4 * * *")))
+		Expect(cronJob.Spec.TimeZone).To(HaveValue(Equal("Europe/Amsterdam")))
+		Expect(cronJob.Spec.ConcurrencyPolicy).To(HaveValue(Equal(batchv1.ForbidConcurrent)))
+		Expect(cronJob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds).To(HaveValue(Equal(int64(4 * 60 * 60))))
+
+		template := cronJob.Spec.JobTemplate.Spec.Template
+		Expect(template.Labels).To(Equal(specs.GameServerBackupLabels(gs)))
+		Expect(template.Labels).NotTo(Equal(expectedLabels(gs)), "backup pods must not be selected by the game server")
+
+		podSpec := template.Spec
+		Expect(podSpec.RestartPolicy).To(HaveValue(Equal(corev1.RestartPolicyNever)))
+		Expect(podSpec.SecurityContext.RunAsUser).To(HaveValue(Equal(int64(1000))))
+
+		By("waiting for the game server pod to be gone before reading the data volume")
+		antiAffinity := podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		Expect(antiAffinity).To(HaveLen(1))
+		Expect(antiAffinity[0].LabelSelector.MatchLabels).To(Equal(expectedLabels(gs)))
+
+		volumeClaims := map[string]string{}
+		for _, volume := range podSpec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				volumeClaims[*volume.Name] = *volume.PersistentVolumeClaim.ClaimName
+			}
+		}
+		Expect(volumeClaims).To(Equal(map[string]string{"data": "data-example-0", "backup-target": "backups"}))
+
+		Expect(podSpec.Containers).To(HaveLen(1))
+		container := podSpec.Containers[0]
+		Expect(container.SecurityContext.ReadOnlyRootFilesystem).To(HaveValue(BeTrue()))
+		Expect(envValue(container, "BACKUP_TARGET")).To(Equal("/backups/example"))
+		Expect(envValue(container, "BACKUP_RETENTION")).To(Equal("3"))
+	})
+
+	It("builds a CronJob that uploads backups to an S3 bucket", func() {
+		gs := newGameServer(withBackup(gamesv1alpha1.BackupTarget{
+			S3: &gamesv1alpha1.S3BackupTarget{
+				Endpoint:             "http://minio.minio:9000",
+				Bucket:               "worlds",
+				CredentialsSecretRef: corev1.LocalObjectReference{Name: "minio-credentials"},
+			},
+		}))
+
+		container := specs.BuildGameServerBackupCronJob(gs).Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+		Expect(envValue(container, "BACKUP_TARGET")).To(Equal("s3:worlds/default/example"))
+		Expect(envValue(container, "RCLONE_CONFIG_S3_ENDPOINT")).To(Equal("http://minio.minio:9000"))
+
+		secretKeys := map[string]string{}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				Expect(*env.ValueFrom.SecretKeyRef.Name).To(Equal("minio-credentials"))
+				secretKeys[*env.Name] = *env.ValueFrom.SecretKeyRef.Key
+			}
+		}
+		Expect(secretKeys).To(HaveKey("AWS_ACCESS_KEY_ID"))
+		Expect(secretKeys).To(HaveKey("AWS_SECRET_ACCESS_KEY"))
+	})
+})
@@ -0,0 +1,181 @@
+package specs
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+)
+
+const (
+	// ConfigHashAnnotation is the annotation of the pod template holding status.configHash, so the game server is
+	// restarted when the contents of its config files change.
+	ConfigHashAnnotation = "games.idebeijer.github.io/config-hash"
+
+	configFilesVolume    = "config-files"
+	configFilesMountPath = "/config/files"
+)
+
+// mergeConfigFunction defines the merge_config shell function, which merges the settings of a config file into
+// another: lines setting the same name, followed by "=", a space or a tab, are replaced and the others added.
+const mergeConfigFunction = `# merge_config SOURCE FILE
+merge_config() {
+  [ -s "$1" ] || return 0
+  mkdir -p "$(dirname "$2")"
+  touch "$2"
+  awk 'function name(line) { sub(/^[ \t]+/, "", line); sub(/[ \t=].*$/, "", line); return line }
+    NR == FNR {
+      s = $0; sub(/^[ \t]+/, "", s)
+      if (s == "" || s ~ /^(#|;|\/\/)/) next
+      names[name($0)] = 1; lines[++n] = $0; next
+    }
+    !(name($0) in names)
+    END { for (i = 1; i <= n; i++) print lines[i] }' "$1" "$2" > "$2.tmp"
+  mv "$2.tmp" "$2"
+}
+`
+
+// linuxGSMInstanceConfig returns the path of the config of the LinuxGSM instance of the game, which LinuxGSM never
+// overwrites.
+func linuxGSMInstanceConfig(game linuxgsm.Game) string {
+	return fmt.Sprintf(`${LGSM_CONFIG:-/data/config-lgsm}/%s/%s.cfg`, game.ServerName, game.ServerName)
+}
+
+// buildInstallScript returns the part of the config script installing the game into empty server files, or an empty
+// string when the config script writes no files into the server files.
+//
+// LinuxGSM creates the config files of the game when it installs it, and the entrypoint of the image only installs
+// the game into empty server files. Installing the game first lets the config script write them before the game
+// starts for the first time, after which the entrypoint starts the installed game.
+func buildInstallScript(gs *gamesv1alpha1.GameServer) string {
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok || !configuresServerFiles(gs) {
+		return ""
+	}
+	script := game.ScriptPath()
+	return fmt.Sprintf(`if [ -z "$(ls -A "${LGSM_SERVERFILES:-/data/serverfiles}" 2>/dev/null)" ]; then
+  [ -f %s ] || (cd %s && ./linuxgsm.sh %s)
+  %s %s
+fi
+`, script, path.Dir(script), game.ServerName, script, linuxgsm.CommandAutoInstall)
+}
+
+// configuresServerFiles reports whether the config script writes files into the server files.
+func configuresServerFiles(gs *gamesv1alpha1.GameServer) bool {
+	if secretsConfigureServerFiles(gs) || rconConfiguresServerFiles(gs) {
+		return true
+	}
+	if !LinuxGSMStorageEnabled(gs) {
+		return false
+	}
+	for _, file := range gs.Spec.ConfigFiles {
+		if inServerFiles(file.Path) {
+			return true
+		}
+	}
+	return buildMinecraftConfigScript(gs) != ""
+}
+
+// inServerFiles reports whether the path in the data volume is in the server files.
+func inServerFiles(path string) bool {
+	return path == "serverfiles" || strings.HasPrefix(path, "serverfiles/")
+}
+
+// buildLinuxGSMConfigScript returns the part of the config script writing spec.linuxgsm.config into the config of
+// the LinuxGSM instance, or an empty string when there is nothing to write.
+func buildLinuxGSMConfigScript(gs *gamesv1alpha1.GameServer) string {
+	if gs.Spec.LinuxGSM == nil || len(gs.Spec.LinuxGSM.Config) == 0 {
+		return ""
+	}
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return ""
+	}
+
+	var script strings.Builder
+	file := linuxGSMInstanceConfig(game)
+	for _, name := range slices.Sorted(maps.Keys(gs.Spec.LinuxGSM.Config)) {
+		fmt.Fprintf(&script, "set_config \"%s\" %s %s %s\n", file, linuxgsm.ConfigFormatLinuxGSM, name,
+			shellQuote(gs.Spec.LinuxGSM.Config[name]))
+	}
+	return script.String()
+}
+
+// buildConfigFilesScript returns the part of the config script writing spec.configFiles into the data volume, or an
+// empty string when there are none.
+//
+// Files in serverfiles are only written once the game is installed, as LinuxGSM only installs the game into empty
+// server files, which the config script does first when it is not. Files of optional keys that do not exist are
+// skipped.
+func buildConfigFilesScript(gs *gamesv1alpha1.GameServer) string {
+	if len(gs.Spec.ConfigFiles) == 0 {
+		return ""
+	}
+
+	var script strings.Builder
+	script.WriteString(mergeConfigFunction)
+	for i, file := range gs.Spec.ConfigFiles {
+		source := configFilesMountPath + "/" + strconv.Itoa(i)
+		target := shellQuote("/data/" + file.Path)
+		command := fmt.Sprintf(`mkdir -p "$(dirname %s)" && cp %s %s`, target, source, target)
+		if file.Mode == gamesv1alpha1.ConfigFileModeMerge {
+			command = fmt.Sprintf("merge_config %s %s", source, target)
+		}
+		condition := fmt.Sprintf("[ -f %s ]", source)
+		if inServerFiles(file.Path) {
+			condition += ` && [ -n "$(ls -A /data/serverfiles 2>/dev/null)" ]`
+		}
+		fmt.Fprintf(&script, "if %s; then %s; fi\n", condition, command)
+	}
+	return script.String()
+}
+
+// buildConfigFilesVolume returns the volume projecting the keys of spec.configFiles, named after their index, or nil
+// when there are none.
+func buildConfigFilesVolume(gs *gamesv1alpha1.GameServer) *corev1ac.VolumeApplyConfiguration {
+	if len(gs.Spec.ConfigFiles) == 0 {
+		return nil
+	}
+
+	projected := corev1ac.ProjectedVolumeSource()
+	for i, file := range gs.Spec.ConfigFiles {
+		item := corev1ac.KeyToPath().WithPath(strconv.Itoa(i))
+		switch {
+		case file.ConfigMapKeyRef != nil:
+			source := corev1ac.ConfigMapProjection().
+				WithName(file.ConfigMapKeyRef.Name).
+				WithItems(item.WithKey(file.ConfigMapKeyRef.Key))
+			if file.ConfigMapKeyRef.Optional != nil {
+				source.WithOptional(*file.ConfigMapKeyRef.Optional)
+			}
+			projected.WithSources(corev1ac.VolumeProjection().WithConfigMap(source))
+		case file.SecretKeyRef != nil:
+			source := corev1ac.SecretProjection().
+				WithName(file.SecretKeyRef.Name).
+				WithItems(item.WithKey(file.SecretKeyRef.Key))
+			if file.SecretKeyRef.Optional != nil {
+				source.WithOptional(*file.SecretKeyRef.Optional)
+			}
+			projected.WithSources(corev1ac.VolumeProjection().WithSecret(source))
+		}
+	}
+	return corev1ac.Volume().
+		WithName(configFilesVolume).
+		WithProjected(projected)
+}
+
+// buildPodTemplateAnnotations returns the annotations of the pod template, restarting the game server when they
+// change.
+func buildPodTemplateAnnotations(gs *gamesv1alpha1.GameServer) map[string]string {
+	if gs.Status.ConfigHash == "" {
+		return nil
+	}
+	return map[string]string{ConfigHashAnnotation: gs.Status.ConfigHash}
+}
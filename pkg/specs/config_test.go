@@ -0,0 +1,98 @@
+package specs_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Config spec builders", func() {
+	withConfig := func(gs *gamesv1alpha1.GameServer) {
+		gs.Spec.GameName = "rust"
+		gs.Spec.LinuxGSM = &gamesv1alpha1.LinuxGSMSpec{Config: map[string]string{
+			"servername": "Kube's server",
+			"maxplayers": "50",
+		}}
+		gs.Spec.ConfigFiles = []gamesv1alpha1.ConfigFile{
+			{
+				Path: "config-lgsm/rustserver/common.cfg",
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rust-config"},
+					Key:                  "common.cfg",
+				},
+				Mode: gamesv1alpha1.ConfigFileModeOverwrite,
+			},
+			{
+				Path: "serverfiles/server/rustserver/cfg/server.cfg",
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rust-secrets"},
+					Key:                  "server.cfg",
+					Optional:             new(true),
+				},
+				Mode: gamesv1alpha1.ConfigFileModeMerge,
+			},
+		}
+		gs.Status.ConfigHash = "0123abcd"
+	}
+
+	It("writes the LinuxGSM config and config files before starting the game", func() {
+		sts := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withConfig))
+		script := sts.Spec.Template.Spec.Containers[0].Command[2]
+
+		Expect(script).To(ContainSubstring(`set_config "${LGSM_CONFIG:-/data/config-lgsm}/rustserver/rustserver.cfg" ` +
+			`linuxgsm maxplayers '50'
+set_config "${LGSM_CONFIG:-/data/config-lgsm}/rustserver/rustserver.cfg" linuxgsm servername 'Kube'\''s server'
+`))
+		Expect(script).To(ContainSubstring(`if [ -f /config/files/0 ]; then ` +
+			`mkdir -p "$(dirname '/data/config-lgsm/rustserver/common.cfg')" && ` +
+			`cp /config/files/0 '/data/config-lgsm/rustserver/common.cfg'; fi`))
+		Expect(script).To(ContainSubstring(`if [ -f /config/files/1 ] && [ -n "$(ls -A /data/serverfiles 2>/dev/null)" ]; ` +
+			`then merge_config /config/files/1 '/data/serverfiles/server/rustserver/cfg/server.cfg'; fi`))
+		Expect(script).To(HaveSuffix("exec /app/entrypoint-user.sh\n"))
+
+		By("installing the game into a fresh data volume before writing files in serverfiles")
+		install := strings.Index(script, "  /app/rustserver auto-install\n")
+		Expect(install).To(BeNumerically(">", strings.Index(script, "linuxgsm servername")))
+		Expect(install).To(BeNumerically("<", strings.Index(script, "merge_config /config/files/1")))
+	})
+
+	It("projects the keys of the config files into the container", func() {
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withConfig)).Spec.Template.Spec
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath",
+			HaveValue(Equal("/config/files")))))
+		Expect(podSpec.Volumes).To(HaveLen(1))
+		sources := podSpec.Volumes[0].Projected.Sources
+		Expect(sources).To(HaveLen(2))
+		Expect(sources[0].ConfigMap.Name).To(HaveValue(Equal("rust-config")))
+		Expect(sources[0].ConfigMap.Items[0].Key).To(HaveValue(Equal("common.cfg")))
+		Expect(sources[0].ConfigMap.Items[0].Path).To(HaveValue(Equal("0")))
+		Expect(sources[1].Secret.Name).To(HaveValue(Equal("rust-secrets")))
+		Expect(sources[1].Secret.Items[0].Path).To(HaveValue(Equal("1")))
+		Expect(sources[1].Secret.Optional).To(HaveValue(BeTrue()))
+	})
+
+	It("restarts the game server when the config hash changes", func() {
+		template := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withConfig)).Spec.Template
+		Expect(template.Annotations).To(HaveKeyWithValue(specs.ConfigHashAnnotation, "0123abcd"))
+
+		template = specs.BuildLinuxGSMGameServerStatefulSet(newGameServer()).Spec.Template
+		Expect(template.Annotations).To(BeEmpty())
+	})
+
+	It("leaves config files alone without persistent storage", func() {
+		gs := newGameServer(withConfig, func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+		})
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(BeEmpty())
+		Expect(podSpec.Containers[0].Command[2]).NotTo(ContainSubstring("/config/files"))
+		Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("linuxgsm servername"))
+		Expect(podSpec.Containers[0].Command[2]).NotTo(ContainSubstring("auto-install"))
+	})
+})
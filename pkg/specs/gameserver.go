@@ -2,8 +2,11 @@ package specs
 
 import (
 	"fmt"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
@@ -14,10 +17,21 @@ import (
 	"github.com/idebeijer/gameserver-operator/pkg/utils"
 )
 
+const (
+	dataVolumeName     = "data"
+	defaultStorageSize = "10Gi"
+
+	defaultIdleTimeout       = 30 * time.Minute
+	defaultIdleCheckInterval = time.Minute
+
+	// GameServerContainerName is the name of the container running LinuxGSM and the game.
+	GameServerContainerName = "gameserver"
+)
+
 func BuildLinuxGSMGameServerStatefulSet(gs *gamesv1alpha1.GameServer) *appsv1ac.StatefulSetApplyConfiguration {
-	storageEnabled := linuxGSMStorageEnabled(gs)
+	storageEnabled := LinuxGSMStorageEnabled(gs)
 	container := buildLinuxGSMContainer(gs, storageEnabled)
-	podSpec := buildLinuxGSMPodSpec(container)
+	podSpec := buildLinuxGSMPodSpec(gs, container)
 	stsSpec := buildLinuxGSMStatefulSetSpec(gs, podSpec, storageEnabled)
 
 	sts := appsv1ac.StatefulSet(gs.Name, gs.Namespace).
@@ -28,30 +42,104 @@ func BuildLinuxGSMGameServerStatefulSet(gs *gamesv1alpha1.GameServer) *appsv1ac.
 	return sts
 }
 
-func linuxGSMStorageEnabled(gs *gamesv1alpha1.GameServer) bool {
+// LinuxGSMStorageEnabled reports whether the game server data is stored on a persistent volume.
+func LinuxGSMStorageEnabled(gs *gamesv1alpha1.GameServer) bool {
 	if gs.Spec.Storage != nil && gs.Spec.Storage.Enabled != nil {
 		return *gs.Spec.Storage.Enabled
 	}
 	return true
 }
 
+// GameServerDesiredState returns the run state requested by the GameServer spec.
+// The deprecated Replicas field is only considered when State is not set, where 0 means stopped.
+func GameServerDesiredState(gs *gamesv1alpha1.GameServer) gamesv1alpha1.GameServerState {
+	if gs.Spec.State != "" {
+		return gs.Spec.State
+	}
+	if gs.Spec.Replicas == 0 {
+		return gamesv1alpha1.GameServerStateStopped
+	}
+	return gamesv1alpha1.GameServerStateRunning
+}
+
+// GameServerScheduledState returns the run state requested by the GameServer spec, where spec.schedule stops the
+// game server outside of its windows as reported in status.schedule.
+func GameServerScheduledState(gs *gamesv1alpha1.GameServer) gamesv1alpha1.GameServerState {
+	if gs.Spec.Schedule != nil && gs.Status.Schedule != nil &&
+		gs.Status.Schedule.State == gamesv1alpha1.GameServerStateStopped {
+		return gamesv1alpha1.GameServerStateStopped
+	}
+	return GameServerDesiredState(gs)
+}
+
+// GameServerStopped reports whether the game server is scaled down to zero, either because the spec or its
+// schedule requests it or because it was stopped for being idle.
+func GameServerStopped(gs *gamesv1alpha1.GameServer) bool {
+	return GameServerScheduledState(gs) == gamesv1alpha1.GameServerStateStopped ||
+		meta.IsStatusConditionTrue(gs.Status.Conditions, gamesv1alpha1.GameServerConditionIdle)
+}
+
+// GameServerIdleTimeouts returns how long the game server may be empty before it is stopped and how often its
+// players are counted.
+func GameServerIdleTimeouts(gs *gamesv1alpha1.GameServer) (timeout, checkInterval time.Duration) {
+	timeout, checkInterval = defaultIdleTimeout, defaultIdleCheckInterval
+	if gs.Spec.Idle == nil {
+		return timeout, checkInterval
+	}
+	if gs.Spec.Idle.Timeout != nil {
+		timeout = gs.Spec.Idle.Timeout.Duration
+	}
+	if gs.Spec.Idle.CheckInterval != nil {
+		checkInterval = gs.Spec.Idle.CheckInterval.Duration
+	}
+	return timeout, checkInterval
+}
+
+// GameServerStorageDeletionPolicy returns what happens to the data volume when the GameServer is deleted.
+func GameServerStorageDeletionPolicy(gs *gamesv1alpha1.GameServer) gamesv1alpha1.StorageDeletionPolicy {
+	if gs.Spec.Storage != nil && gs.Spec.Storage.DeletionPolicy != "" {
+		return gs.Spec.Storage.DeletionPolicy
+	}
+	return gamesv1alpha1.StorageDeletionPolicyRetain
+}
+
+// GameServerPodName returns the name of the pod the StatefulSet creates for the game server.
+func GameServerPodName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-0", gs.Name)
+}
+
+// GameServerDataVolumeClaimName returns the name of the PVC the StatefulSet creates
+// from the data volume claim template.
+func GameServerDataVolumeClaimName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-%s", dataVolumeName, GameServerPodName(gs))
+}
+
 func buildLinuxGSMContainer(gs *gamesv1alpha1.GameServer, storageEnabled bool) *corev1ac.ContainerApplyConfiguration {
 	container := corev1ac.Container().
-		WithName("gameserver").
+		WithName(GameServerContainerName).
 		WithImage(fmt.Sprintf("gameservermanagers/gameserver:%s", gs.Spec.GameName)).
 		WithImagePullPolicy(v1.PullIfNotPresent).
-		WithSecurityContext(corev1ac.SecurityContext().
-			WithAllowPrivilegeEscalation(false).
-			WithCapabilities(corev1ac.Capabilities().
-				WithDrop("ALL"),
-			),
-		).
-		WithCommand("/app/entrypoint-user.sh").
+		WithSecurityContext(restrictedContainerSecurityContext()).
+		// The cron based update check of the image does not work under the security profile,
+		// updates are run by the operator on spec.updates.schedule instead.
 		WithEnv(
 			corev1ac.EnvVar().
 				WithName("UPDATE_CHECK").
 				WithValue("0"),
-		)
+		).
+		WithEnv(buildRCONEnv(gs)...).
+		WithEnv(buildSecretsEnv(gs)...)
+
+	if script := buildConfigScript(gs); script != "" {
+		container.WithCommand("/bin/bash", "-c", script)
+	} else {
+		container.WithCommand(linuxGSMEntrypoint)
+	}
+
+	if preStop := buildPreStopHook(gs); preStop != nil {
+		container.WithLifecycle(corev1ac.Lifecycle().WithPreStop(preStop))
+	}
+	buildProbes(gs, container)
 
 	if gs.Spec.Resources != nil {
 		resources := corev1ac.ResourceRequirements()
@@ -85,28 +173,86 @@ func buildLinuxGSMContainer(gs *gamesv1alpha1.GameServer, storageEnabled bool) *
 	if storageEnabled {
 		container.WithVolumeMounts(
 			corev1ac.VolumeMount().
-				WithName("data").
+				WithName(dataVolumeName).
 				WithMountPath("/data"),
 		)
+		if buildConfigFilesVolume(gs) != nil {
+			container.WithVolumeMounts(corev1ac.VolumeMount().
+				WithName(configFilesVolume).
+				WithMountPath(configFilesMountPath).
+				WithReadOnly(true),
+			)
+		}
+		if buildMinecraftPlayersVolume(gs) != nil {
+			container.WithVolumeMounts(corev1ac.VolumeMount().
+				WithName(minecraftPlayersVolume).
+				WithMountPath(minecraftPlayersMountPath).
+				WithReadOnly(true),
+			)
+		}
 	}
 
 	return container
 }
 
-func buildLinuxGSMPodSpec(container *corev1ac.ContainerApplyConfiguration) *corev1ac.PodSpecApplyConfiguration {
-	return corev1ac.PodSpec().
+func buildLinuxGSMPodSpec(
+	gs *gamesv1alpha1.GameServer,
+	container *corev1ac.ContainerApplyConfiguration,
+) *corev1ac.PodSpecApplyConfiguration {
+	podSpec := corev1ac.PodSpec().
 		WithAutomountServiceAccountToken(false).
-		WithSecurityContext(corev1ac.PodSecurityContext().
-			WithRunAsNonRoot(true).
-			WithRunAsUser(1000).
-			WithRunAsGroup(1000).
-			WithFSGroup(1000).
-			WithFSGroupChangePolicy(v1.FSGroupChangeOnRootMismatch).
-			WithSeccompProfile(corev1ac.SeccompProfile().
-				WithType(v1.SeccompProfileTypeRuntimeDefault),
-			),
-		).
+		WithSecurityContext(linuxGSMPodSecurityContext()).
 		WithContainers(container)
+
+	if gracePeriod := gameServerTerminationGracePeriodSeconds(gs); gracePeriod != nil {
+		podSpec.WithTerminationGracePeriodSeconds(*gracePeriod)
+	}
+
+	// The Minecraft server and mods are installed into the data volume, which the webhook requires for them.
+	if LinuxGSMStorageEnabled(gs) {
+		if minecraft := buildMinecraftInitContainer(gs); minecraft != nil {
+			podSpec.WithInitContainers(minecraft)
+		}
+		if mods := buildModsInitContainer(gs); mods != nil {
+			podSpec.WithInitContainers(mods)
+		}
+		if len(podSpec.InitContainers) > 0 {
+			podSpec.WithVolumes(corev1ac.Volume().
+				WithName(modsTmpVolumeName).
+				WithEmptyDir(corev1ac.EmptyDirVolumeSource()),
+			)
+		}
+		if configFiles := buildConfigFilesVolume(gs); configFiles != nil {
+			podSpec.WithVolumes(configFiles)
+		}
+		if players := buildMinecraftPlayersVolume(gs); players != nil {
+			podSpec.WithVolumes(players)
+		}
+	}
+
+	return podSpec
+}
+
+// linuxGSMPodSecurityContext runs pods as the linuxgsm user of the LinuxGSM images,
+// so every pod accessing the data volume agrees on its ownership.
+func linuxGSMPodSecurityContext() *corev1ac.PodSecurityContextApplyConfiguration {
+	return corev1ac.PodSecurityContext().
+		WithRunAsNonRoot(true).
+		WithRunAsUser(1000).
+		WithRunAsGroup(1000).
+		WithFSGroup(1000).
+		WithFSGroupChangePolicy(v1.FSGroupChangeOnRootMismatch).
+		WithSeccompProfile(corev1ac.SeccompProfile().
+			WithType(v1.SeccompProfileTypeRuntimeDefault),
+		)
+}
+
+func restrictedContainerSecurityContext() *corev1ac.SecurityContextApplyConfiguration {
+	return corev1ac.SecurityContext().
+		WithAllowPrivilegeEscalation(false).
+		WithCapabilities(corev1ac.Capabilities().
+			WithDrop("ALL"),
+		)
 }
 
 func buildLinuxGSMStatefulSetSpec(gs *gamesv1alpha1.GameServer,
@@ -114,7 +260,7 @@ func buildLinuxGSMStatefulSetSpec(gs *gamesv1alpha1.GameServer,
 	storageEnabled bool,
 ) *appsv1ac.StatefulSetSpecApplyConfiguration {
 	replicaCount := int32(1)
-	if gs.Spec.Replicas == 0 {
+	if GameServerStopped(gs) {
 		replicaCount = 0
 	}
 
@@ -126,27 +272,42 @@ func buildLinuxGSMStatefulSetSpec(gs *gamesv1alpha1.GameServer,
 		WithTemplate(
 			corev1ac.PodTemplateSpec().
 				WithLabels(gameServerLabels(gs)).
+				WithAnnotations(buildPodTemplateAnnotations(gs)).
 				WithSpec(podSpec),
 		)
 
 	if storageEnabled {
-		stsSpec.WithVolumeClaimTemplates(buildLinuxGSMVolumeClaimTemplate(gs))
+		whenDeleted := appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+		if GameServerStorageDeletionPolicy(gs) == gamesv1alpha1.StorageDeletionPolicyDelete {
+			whenDeleted = appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+		}
+
+		stsSpec.
+			WithVolumeClaimTemplates(buildLinuxGSMVolumeClaimTemplate(gs)).
+			WithPersistentVolumeClaimRetentionPolicy(appsv1ac.StatefulSetPersistentVolumeClaimRetentionPolicy().
+				WithWhenDeleted(whenDeleted).
+				// Stopping a game server scales it to zero, which must never remove its world.
+				WithWhenScaled(appsv1.RetainPersistentVolumeClaimRetentionPolicyType),
+			)
 	}
 
 	return stsSpec
 }
 
-func buildLinuxGSMVolumeClaimTemplate(gs *gamesv1alpha1.GameServer) *corev1ac.PersistentVolumeClaimApplyConfiguration {
-	storageSize := "10Gi"
+// GameServerStorageSize returns the requested size of the data volume.
+func GameServerStorageSize(gs *gamesv1alpha1.GameServer) resource.Quantity {
 	if gs.Spec.Storage != nil && gs.Spec.Storage.Size != "" {
-		storageSize = gs.Spec.Storage.Size
+		return resource.MustParse(gs.Spec.Storage.Size)
 	}
+	return resource.MustParse(defaultStorageSize)
+}
 
+func buildLinuxGSMVolumeClaimTemplate(gs *gamesv1alpha1.GameServer) *corev1ac.PersistentVolumeClaimApplyConfiguration {
 	pvcSpec := corev1ac.PersistentVolumeClaimSpec().
 		WithAccessModes(v1.ReadWriteOnce).
 		WithResources(corev1ac.VolumeResourceRequirements().
 			WithRequests(v1.ResourceList{
-				v1.ResourceStorage: resource.MustParse(storageSize),
+				v1.ResourceStorage: GameServerStorageSize(gs),
 			}),
 		)
 
@@ -154,7 +315,15 @@ func buildLinuxGSMVolumeClaimTemplate(gs *gamesv1alpha1.GameServer) *corev1ac.Pe
 		pvcSpec.WithStorageClassName(*gs.Spec.Storage.StorageClassName)
 	}
 
-	return corev1ac.PersistentVolumeClaim("data", gs.Namespace).
+	if gs.Spec.Storage != nil && gs.Spec.Storage.FromSnapshot != "" {
+		pvcSpec.WithDataSourceRef(corev1ac.TypedObjectReference().
+			WithAPIGroup(VolumeSnapshotGroupVersionKind.Group).
+			WithKind(VolumeSnapshotGroupVersionKind.Kind).
+			WithName(gs.Spec.Storage.FromSnapshot),
+		)
+	}
+
+	return corev1ac.PersistentVolumeClaim(dataVolumeName, gs.Namespace).
 		WithSpec(pvcSpec)
 }
 
@@ -4,6 +4,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -100,6 +101,28 @@ var _ = Describe("LinuxGSM spec builders", func() {
 			Expect(statefulSet.Spec.VolumeClaimTemplates).To(BeNil())
 		})
 
+		It("retains the data volume by default and when scaled down", func() {
+			statefulSet := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer())
+
+			policy := statefulSet.Spec.PersistentVolumeClaimRetentionPolicy
+			Expect(policy).NotTo(BeNil())
+			Expect(policy.WhenDeleted).To(HaveValue(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType)))
+			Expect(policy.WhenScaled).To(HaveValue(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType)))
+		})
+
+		It("deletes the data volume with the Delete deletion policy", func() {
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Spec.Storage = &gamesv1alpha1.StorageSpec{
+					DeletionPolicy: gamesv1alpha1.StorageDeletionPolicyDelete,
+				}
+			})
+
+			policy := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.PersistentVolumeClaimRetentionPolicy
+			Expect(policy).NotTo(BeNil())
+			Expect(policy.WhenDeleted).To(HaveValue(Equal(appsv1.DeletePersistentVolumeClaimRetentionPolicyType)))
+			Expect(policy.WhenScaled).To(HaveValue(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType)))
+		})
+
 		It("adds container ports for numeric service target ports", func() {
 			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
 				gs.Spec.Service = &gamesv1alpha1.ServiceSpec{
@@ -139,6 +162,44 @@ var _ = Describe("LinuxGSM spec builders", func() {
 		})
 	})
 
+	DescribeTable("GameServerDesiredState",
+		func(state gamesv1alpha1.GameServerState, replicas int32, expected gamesv1alpha1.GameServerState) {
+			gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+				gs.Spec.State = state
+				gs.Spec.Replicas = replicas
+			})
+
+			Expect(specs.GameServerDesiredState(gs)).To(Equal(expected))
+
+			expectedReplicas := int32(1)
+			if expected == gamesv1alpha1.GameServerStateStopped {
+				expectedReplicas = 0
+			}
+			Expect(specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Replicas).To(HaveValue(Equal(expectedReplicas)))
+		},
+		Entry("runs by default", gamesv1alpha1.GameServerState(""), int32(1), gamesv1alpha1.GameServerStateRunning),
+		Entry("stops with the deprecated zero replicas", gamesv1alpha1.GameServerState(""), int32(0),
+			gamesv1alpha1.GameServerStateStopped),
+		Entry("runs a single instance for more than one replica", gamesv1alpha1.GameServerState(""), int32(3),
+			gamesv1alpha1.GameServerStateRunning),
+		Entry("prefers the stopped state over replicas", gamesv1alpha1.GameServerStateStopped, int32(1),
+			gamesv1alpha1.GameServerStateStopped),
+		Entry("prefers the running state over replicas", gamesv1alpha1.GameServerStateRunning, int32(0),
+			gamesv1alpha1.GameServerStateRunning),
+	)
+
+	It("scales idle game servers down to zero", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.State = gamesv1alpha1.GameServerStateRunning
+			gs.Status.Conditions = []metav1.Condition{{
+				Type:   gamesv1alpha1.GameServerConditionIdle,
+				Status: metav1.ConditionTrue,
+			}}
+		})
+		Expect(specs.GameServerStopped(gs)).To(BeTrue())
+		Expect(specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Replicas).To(HaveValue(BeEquivalentTo(0)))
+	})
+
 	Describe("BuildGameServerService", func() {
 		It("returns nil when service spec is not provided", func() {
 			gs := newGameServer()
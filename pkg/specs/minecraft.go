@@ -0,0 +1,211 @@
+package specs
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+)
+
+const (
+	minecraftContainerName = "install-minecraft"
+	minecraftInstallMarker = ".gameserver-operator-install"
+	minecraftModpackIndex  = ".gameserver-operator-modpack"
+
+	// minecraftJavaOptions are the options of the java command LinuxGSM starts the server with, with the memory
+	// configured in LinuxGSM.
+	minecraftJavaOptions = "java -Xmx${javaram}M"
+)
+
+// minecraftScript installs the Minecraft server resolved in status.minecraft.server into the server files, unless
+// INSTALL_KEY shows it is installed already. The files of the previous modpack are removed first, then the files of
+// the modpack are installed, and then the server jar, or the server of the Forge and NeoForge installers. The
+// executable in the config of the LinuxGSM instance is set to start the installed server.
+//
+// LinuxGSM skips installing the vanilla server when the server files exist.
+const minecraftScript = setConfigFunction + `set -o pipefail
+serverfiles=/data/serverfiles
+marker="${serverfiles}/` + minecraftInstallMarker + `"
+index="${serverfiles}/` + minecraftModpackIndex + `"
+if [ -f "${marker}" ] && [ "$(cat "${marker}")" = "${INSTALL_KEY}" ]; then
+  echo "The Minecraft server is installed"
+  exit 0
+fi
+mkdir -p "${serverfiles}"
+work="$(mktemp -d)"
+touch "${work}/installed"
+
+# download URL SHA1 FILE downloads URL to FILE, checking its hash unless SHA1 is "-".
+download() {
+  echo "Downloading $1"
+  curl -fsSL --retry 3 -o "$3" "$1"
+  if [ "$2" != "-" ] && [ "$(sha1sum "$3" | cut -d ' ' -f 1)" != "$2" ]; then
+    echo "The SHA-1 hash of $1 is not $2" >&2
+    exit 1
+  fi
+}
+
+# install_file FILE PATH moves FILE to PATH in the server files, and records it as a file of the modpack.
+install_file() {
+  case "/$2/" in
+    */../*)
+      echo "Skipping $2, which is outside of the server files" >&2
+      return 0
+      ;;
+  esac
+  mkdir -p "$(dirname "${serverfiles}/$2")"
+  mv -f "$1" "${serverfiles}/$2"
+  printf '%s\n' "$2" >> "${work}/installed"
+}
+
+# install_tree DIR installs the files in DIR into the server files.
+install_tree() {
+  [ -d "$1" ] || return 0
+  (cd "$1" && find . -type f | sed 's|^\./||') | while IFS= read -r path; do
+    install_file "$1/${path}" "${path}"
+  done
+}
+
+if [ -f "${index}" ]; then
+  while IFS= read -r path; do
+    rm -f "${serverfiles}/${path}"
+  done < "${index}"
+  rm -f "${index}"
+fi
+
+if [ -n "${MODPACK_URL}" ]; then
+  download "${MODPACK_URL}" "${MODPACK_SHA1}" "${work}/modpack.zip"
+  mkdir "${work}/modpack"
+  unzip -q "${work}/modpack.zip" -d "${work}/modpack"
+  if [ "${MODPACK_FORMAT}" = "` + string(gamesv1alpha1.MinecraftModpackFormatMrpack) + `" ]; then
+    jq -r '.files[] | select(.env.server != "unsupported") | [.hashes.sha1, .downloads[0], .path] | @tsv' \
+      "${work}/modpack/modrinth.index.json" > "${work}/files"
+    while IFS=$'\t' read -r sha1 url path; do
+      download "${url}" "${sha1}" "${work}/file"
+      install_file "${work}/file" "${path}"
+    done < "${work}/files"
+    install_tree "${work}/modpack/overrides"
+    install_tree "${work}/modpack/server-overrides"
+  else
+    root="${work}/modpack"
+    # Server packs often hold the server files in a single directory.
+    if [ "$(ls -A "${root}" | wc -l)" -eq 1 ] && [ -d "${root}/$(ls -A "${root}")" ]; then
+      root="${root}/$(ls -A "${root}")"
+    fi
+    install_tree "${root}"
+  fi
+fi
+
+if [ "${SERVER_INSTALLER}" = "true" ]; then
+  download "${SERVER_URL}" "${SERVER_SHA1}" "${work}/installer.jar"
+  (cd "${serverfiles}" && java -jar "${work}/installer.jar" --installServer)
+else
+  download "${SERVER_URL}" "${SERVER_SHA1}" "${work}/server.jar"
+  mv -f "${work}/server.jar" "${serverfiles}/minecraft_server.jar"
+fi
+[ -z "${CONFIG_FILE}" ] || set_config "${CONFIG_FILE}" properties executable "\"${MINECRAFT_EXECUTABLE}\""
+
+mv "${work}/installed" "${index}"
+printf '%s' "${INSTALL_KEY}" > "${marker}"
+rm -rf "${work}"
+`
+
+// GameServerMinecraftServer returns the Minecraft server the operator installs as specified in
+// spec.gameConfigs.minecraft, or nil when LinuxGSM installs the vanilla server.
+func GameServerMinecraftServer(gs *gamesv1alpha1.GameServer) *gamesv1alpha1.MinecraftServerRequest {
+	if gs.Spec.GameConfigs == nil || gs.Spec.GameConfigs.Minecraft == nil {
+		return nil
+	}
+	minecraft := gs.Spec.GameConfigs.Minecraft
+	if minecraft.Version == "" && minecraft.Loader == "" && minecraft.Modpack == nil {
+		return nil
+	}
+	return &gamesv1alpha1.MinecraftServerRequest{
+		Version:       minecraft.Version,
+		Loader:        minecraft.Loader,
+		LoaderVersion: minecraft.LoaderVersion,
+		Modpack:       minecraft.Modpack.DeepCopy(),
+	}
+}
+
+// minecraftServerStatus returns the resolved Minecraft server the operator installs, or nil when there is none.
+func minecraftServerStatus(gs *gamesv1alpha1.GameServer) *gamesv1alpha1.MinecraftServerStatus {
+	if gs.Status.Minecraft == nil || gs.Status.Minecraft.Server == nil || gs.Status.Minecraft.Server.File == nil {
+		return nil
+	}
+	return gs.Status.Minecraft.Server
+}
+
+// minecraftExecutable returns the command LinuxGSM starts the server with. The Forge and NeoForge installers write
+// the arguments of the server into the libraries of the server files.
+func minecraftExecutable(server *gamesv1alpha1.MinecraftServerStatus) string {
+	switch server.Loader {
+	case gamesv1alpha1.MinecraftLoaderForge:
+		return fmt.Sprintf("%s @libraries/net/minecraftforge/forge/%s-%s/unix_args.txt",
+			minecraftJavaOptions, server.Version, server.LoaderVersion)
+	case gamesv1alpha1.MinecraftLoaderNeoForge:
+		return fmt.Sprintf("%s @libraries/net/neoforged/neoforge/%s/unix_args.txt",
+			minecraftJavaOptions, server.LoaderVersion)
+	default:
+		return minecraftJavaOptions + " -jar ${serverfiles}/minecraft_server.jar"
+	}
+}
+
+// buildMinecraftInitContainer returns the init container installing the Minecraft server resolved in
+// status.minecraft.server, or nil when LinuxGSM installs the server. It runs the image of the game, which has java
+// for the Forge and NeoForge installers and the tools LinuxGSM needs.
+func buildMinecraftInitContainer(gs *gamesv1alpha1.GameServer) *corev1ac.ContainerApplyConfiguration {
+	server := minecraftServerStatus(gs)
+	if server == nil || GameServerMinecraftServer(gs) == nil {
+		return nil
+	}
+
+	installer := server.Loader == gamesv1alpha1.MinecraftLoaderForge ||
+		server.Loader == gamesv1alpha1.MinecraftLoaderNeoForge
+	installKey := server.File.URL
+	modpackURL, modpackSHA1, modpackFormat := "", modsUnknownSHA1, ""
+	if server.Modpack != nil {
+		installKey += " " + server.Modpack.URL
+		modpackURL, modpackFormat = server.Modpack.URL, string(server.Modpack.Format)
+		if server.Modpack.SHA1 != "" {
+			modpackSHA1 = server.Modpack.SHA1
+		}
+	}
+	serverSHA1 := server.File.SHA1
+	if serverSHA1 == "" {
+		serverSHA1 = modsUnknownSHA1
+	}
+
+	// The path of the config is set in the script, as it depends on LGSM_CONFIG of the image.
+	script := minecraftScript
+	if game, ok := linuxgsm.LookupGame(gs.Spec.GameName); ok {
+		script = fmt.Sprintf("CONFIG_FILE=\"%s\"\n", linuxGSMInstanceConfig(game)) + script
+	}
+
+	return corev1ac.Container().
+		WithName(minecraftContainerName).
+		WithImage(fmt.Sprintf("gameservermanagers/gameserver:%s", gs.Spec.GameName)).
+		WithImagePullPolicy(v1.PullIfNotPresent).
+		WithCommand("/bin/bash", "-c", script).
+		WithSecurityContext(restrictedContainerSecurityContext().
+			WithReadOnlyRootFilesystem(true),
+		).
+		WithEnv(
+			corev1ac.EnvVar().WithName("INSTALL_KEY").WithValue(installKey),
+			corev1ac.EnvVar().WithName("SERVER_URL").WithValue(server.File.URL),
+			corev1ac.EnvVar().WithName("SERVER_SHA1").WithValue(serverSHA1),
+			corev1ac.EnvVar().WithName("SERVER_INSTALLER").WithValue(fmt.Sprint(installer)),
+			corev1ac.EnvVar().WithName("MODPACK_URL").WithValue(modpackURL),
+			corev1ac.EnvVar().WithName("MODPACK_SHA1").WithValue(modpackSHA1),
+			corev1ac.EnvVar().WithName("MODPACK_FORMAT").WithValue(modpackFormat),
+			corev1ac.EnvVar().WithName("MINECRAFT_EXECUTABLE").WithValue(minecraftExecutable(server)),
+			corev1ac.EnvVar().WithName("HOME").WithValue("/tmp"),
+		).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().WithName(dataVolumeName).WithMountPath("/data"),
+			corev1ac.VolumeMount().WithName(modsTmpVolumeName).WithMountPath("/tmp"),
+		)
+}
@@ -0,0 +1,279 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+)
+
+const (
+	minecraftPropertiesFile   = "server.properties"
+	minecraftPlayersVolume    = "minecraft-players"
+	minecraftPlayersMountPath = "/config/minecraft"
+
+	// MinecraftWhitelistKey and MinecraftOpsKey are the keys of the Minecraft players ConfigMap, named after the
+	// files of the server they replace.
+	MinecraftWhitelistKey = "whitelist.json"
+	MinecraftOpsKey       = "ops.json"
+
+	// minecraftOpLevel is the permission level of ops, which allows all commands.
+	minecraftOpLevel = 4
+)
+
+// minecraftWhitelistProperties enable the whitelist, and kick players that are not on it when it is reloaded.
+var minecraftWhitelistProperties = []string{"white-list", "enforce-whitelist"}
+
+// MinecraftPlayer is an entry of whitelist.json or ops.json.
+type MinecraftPlayer struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// Level and BypassesPlayerLimit are only set for ops.
+	Level               *int  `json:"level,omitempty"`
+	BypassesPlayerLimit *bool `json:"bypassesPlayerLimit,omitempty"`
+}
+
+// GameServerMinecraftPlayersConfigMapName returns the name of the ConfigMap holding whitelist.json and ops.json.
+func GameServerMinecraftPlayersConfigMapName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-minecraft-players", gs.Name)
+}
+
+// GameServerMinecraftOnlineMode returns whether the Minecraft server checks that players have a Mojang account,
+// which it does unless online mode is disabled in spec.gameConfigs.minecraft.properties.
+func GameServerMinecraftOnlineMode(gs *gamesv1alpha1.GameServer) bool {
+	minecraft := gameServerMinecraftConfig(gs)
+	if minecraft == nil || minecraft.Properties == nil || minecraft.Properties.OnlineMode == nil {
+		return true
+	}
+	return *minecraft.Properties.OnlineMode
+}
+
+// GameServerMinecraftPlayerNames returns the names of the whitelisted players and ops, without duplicates.
+func GameServerMinecraftPlayerNames(gs *gamesv1alpha1.GameServer) []string {
+	minecraft := gameServerMinecraftConfig(gs)
+	if minecraft == nil {
+		return nil
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range append(append([]string{}, minecraft.Whitelist...), minecraft.Ops...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MinecraftPropertyReserved returns whether the operator sets the server.properties property name itself, from the
+// typed properties, the whitelist or RCON, so it cannot be set as an extra property.
+func MinecraftPropertyReserved(name string) bool {
+	for _, property := range minecraftTypedProperties(&gamesv1alpha1.MinecraftProperties{}) {
+		if property.Name == name {
+			return true
+		}
+	}
+	for _, property := range minecraftWhitelistProperties {
+		if property == name {
+			return true
+		}
+	}
+	if r, ok := linuxgsm.LookupRCON("mc"); ok {
+		if name == r.PortSetting || name == r.PasswordSetting {
+			return true
+		}
+		for _, setting := range r.Settings {
+			if setting.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func gameServerMinecraftConfig(gs *gamesv1alpha1.GameServer) *gamesv1alpha1.MinecraftConfig {
+	if gs.Spec.GameConfigs == nil {
+		return nil
+	}
+	return gs.Spec.GameConfigs.Minecraft
+}
+
+// minecraftTypedProperties returns the typed properties with their names in server.properties. Properties that are
+// not set have an empty value, so the names of all of them are known.
+func minecraftTypedProperties(p *gamesv1alpha1.MinecraftProperties) []linuxgsm.Setting {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	integer := func(i *int32) string {
+		if i == nil {
+			return ""
+		}
+		return strconv.Itoa(int(*i))
+	}
+	boolean := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return strconv.FormatBool(*b)
+	}
+	return []linuxgsm.Setting{
+		{Name: "motd", Value: str(p.MOTD)},
+		{Name: "difficulty", Value: p.Difficulty},
+		{Name: "gamemode", Value: p.Gamemode},
+		{Name: "max-players", Value: integer(p.MaxPlayers)},
+		{Name: "view-distance", Value: integer(p.ViewDistance)},
+		{Name: "simulation-distance", Value: integer(p.SimulationDistance)},
+		{Name: "spawn-protection", Value: integer(p.SpawnProtection)},
+		{Name: "level-seed", Value: str(p.Seed)},
+		{Name: "online-mode", Value: boolean(p.OnlineMode)},
+		{Name: "pvp", Value: boolean(p.PVP)},
+		{Name: "hardcore", Value: boolean(p.Hardcore)},
+		{Name: "allow-flight", Value: boolean(p.AllowFlight)},
+	}
+}
+
+// gameServerMinecraftProperties returns the properties of server.properties set by spec.gameConfigs.minecraft, in
+// the order they are written: the whitelist, the extra properties sorted by name, then the typed properties.
+func gameServerMinecraftProperties(gs *gamesv1alpha1.GameServer) []linuxgsm.Setting {
+	minecraft := gameServerMinecraftConfig(gs)
+	if minecraft == nil {
+		return nil
+	}
+	var properties []linuxgsm.Setting
+	if minecraft.Whitelist != nil {
+		for _, name := range minecraftWhitelistProperties {
+			properties = append(properties, linuxgsm.Setting{Name: name, Value: "true"})
+		}
+	}
+	if minecraft.Properties == nil {
+		return properties
+	}
+	for _, name := range slices.Sorted(maps.Keys(minecraft.Properties.Extra)) {
+		properties = append(properties, linuxgsm.Setting{Name: name, Value: minecraft.Properties.Extra[name]})
+	}
+	for _, property := range minecraftTypedProperties(minecraft.Properties) {
+		if property.Value != "" {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// minecraftPropertyValue escapes a value for server.properties, which is read as a Java properties file.
+func minecraftPropertyValue(value string) string {
+	return strings.ReplaceAll(value, `\`, `\\`)
+}
+
+// buildMinecraftConfigScript returns the part of the config script writing spec.gameConfigs.minecraft into the
+// server files, or an empty string when there is nothing to write.
+//
+// The server files are only written once the server is installed, as LinuxGSM only installs the server into empty
+// server files. The config script installs the server first when it is not, so the properties and players apply when
+// the world is created. Properties written into a missing server.properties are kept when the server creates it.
+func buildMinecraftConfigScript(gs *gamesv1alpha1.GameServer) string {
+	properties := gameServerMinecraftProperties(gs)
+	players := GameServerMinecraftPlayerNames(gs) != nil
+	if len(properties) == 0 && !players {
+		return ""
+	}
+
+	var script strings.Builder
+	script.WriteString("serverfiles=\"${LGSM_SERVERFILES:-/data/serverfiles}\"\n")
+	script.WriteString("if [ -n \"$(ls -A \"${serverfiles}\" 2>/dev/null)\" ]; then\n")
+	for _, property := range properties {
+		fmt.Fprintf(&script, "  set_config \"${serverfiles}/%s\" %s %s %s\n", minecraftPropertiesFile,
+			linuxgsm.ConfigFormatProperties, property.Name, shellQuote(minecraftPropertyValue(property.Value)))
+	}
+	if players {
+		for _, key := range []string{MinecraftWhitelistKey, MinecraftOpsKey} {
+			source := minecraftPlayersMountPath + "/" + key
+			fmt.Fprintf(&script, "  if [ -f %s ]; then cp %s \"${serverfiles}/%s\"; fi\n", source, source, key)
+		}
+	}
+	script.WriteString("fi\n")
+	return script.String()
+}
+
+// buildMinecraftPlayersVolume returns the volume of the Minecraft players ConfigMap, or nil without whitelisted
+// players or ops. The ConfigMap is optional, as it is only created once the players are resolved.
+func buildMinecraftPlayersVolume(gs *gamesv1alpha1.GameServer) *corev1ac.VolumeApplyConfiguration {
+	if GameServerMinecraftPlayerNames(gs) == nil {
+		return nil
+	}
+	return corev1ac.Volume().
+		WithName(minecraftPlayersVolume).
+		WithConfigMap(corev1ac.ConfigMapVolumeSource().
+			WithName(GameServerMinecraftPlayersConfigMapName(gs)).
+			WithOptional(true),
+		)
+}
+
+// BuildMinecraftPlayersConfigMap builds the ConfigMap holding whitelist.json and ops.json of the players resolved in
+// status.minecraft.players, or returns nil without whitelisted players or ops. Only the files of the lists that are
+// set are included; players that are not resolved are left out.
+func BuildMinecraftPlayersConfigMap(gs *gamesv1alpha1.GameServer) *corev1ac.ConfigMapApplyConfiguration {
+	minecraft := gameServerMinecraftConfig(gs)
+	if minecraft == nil || (minecraft.Whitelist == nil && minecraft.Ops == nil) {
+		return nil
+	}
+
+	uuids := map[string]string{}
+	if gs.Status.Minecraft != nil {
+		for _, player := range gs.Status.Minecraft.Players {
+			if player.UUID != "" {
+				uuids[player.Name] = player.UUID
+			}
+		}
+	}
+	file := func(names []string, op bool) string {
+		players := []MinecraftPlayer{}
+		for _, name := range names {
+			uuid, ok := uuids[name]
+			if !ok {
+				continue
+			}
+			player := MinecraftPlayer{UUID: uuid, Name: name}
+			if op {
+				level, bypass := minecraftOpLevel, false
+				player.Level, player.BypassesPlayerLimit = &level, &bypass
+			}
+			players = append(players, player)
+		}
+		data, _ := json.MarshalIndent(players, "", "  ")
+		return string(data)
+	}
+
+	data := map[string]string{}
+	if minecraft.Whitelist != nil {
+		data[MinecraftWhitelistKey] = file(minecraft.Whitelist, false)
+	}
+	if minecraft.Ops != nil {
+		data[MinecraftOpsKey] = file(minecraft.Ops, true)
+	}
+	return corev1ac.ConfigMap(GameServerMinecraftPlayersConfigMapName(gs), gs.Namespace).
+		WithLabels(gameServerLabels(gs)).
+		WithData(data)
+}
+
+// ParseMinecraftPlayers returns the names of the players in whitelist.json or ops.json.
+func ParseMinecraftPlayers(data string) ([]string, error) {
+	var players []MinecraftPlayer
+	if err := json.Unmarshal([]byte(data), &players); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(players))
+	for _, player := range players {
+		names = append(names, player.Name)
+	}
+	return names, nil
+}
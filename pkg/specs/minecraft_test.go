@@ -0,0 +1,213 @@
+package specs_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Minecraft server spec builders", func() {
+	modpack := gamesv1alpha1.MinecraftMod{
+		Source:  gamesv1alpha1.MinecraftModSourceModrinth,
+		Project: "fabulously-optimized",
+	}
+	sodium := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceModrinth, Project: "sodium"}
+
+	withServer := func(loader gamesv1alpha1.MinecraftLoader, loaderVersion string) func(*gamesv1alpha1.GameServer) {
+		return func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "mc"
+			gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+				Modpack: &modpack,
+				Mods:    []gamesv1alpha1.MinecraftMod{sodium},
+			}}
+			gs.Status.Minecraft = &gamesv1alpha1.MinecraftStatus{
+				Server: &gamesv1alpha1.MinecraftServerStatus{
+					Request:       *specs.GameServerMinecraftServer(gs),
+					Version:       "1.21.1",
+					Loader:        loader,
+					LoaderVersion: loaderVersion,
+					File:          &gamesv1alpha1.MinecraftModFile{Name: "server.jar", URL: "https://example.com/server.jar"},
+					Modpack: &gamesv1alpha1.MinecraftModpackFile{
+						MinecraftModFile: gamesv1alpha1.MinecraftModFile{
+							Name: "fo.mrpack",
+							URL:  "https://cdn.modrinth.com/fo.mrpack",
+							SHA1: "0123456789abcdef0123456789abcdef01234567",
+						},
+						Format: gamesv1alpha1.MinecraftModpackFormatMrpack,
+					},
+				},
+				Mods: []gamesv1alpha1.MinecraftModStatus{{Mod: sodium, File: &gamesv1alpha1.MinecraftModFile{
+					Name: "sodium.jar",
+					URL:  "https://cdn.modrinth.com/sodium.jar",
+				}}},
+			}
+		}
+	}
+
+	podSpec := func(gs *gamesv1alpha1.GameServer) *corev1ac.PodSpecApplyConfiguration {
+		return specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec
+	}
+
+	env := func(container corev1ac.ContainerApplyConfiguration) map[string]string {
+		values := map[string]string{}
+		for _, e := range container.Env {
+			values[*e.Name] = *e.Value
+		}
+		return values
+	}
+
+	It("installs the server and modpack before the mods", func() {
+		spec := podSpec(newGameServer(withServer(gamesv1alpha1.MinecraftLoaderFabric, "0.16.5")))
+		Expect(spec.InitContainers).To(HaveLen(2))
+		Expect(spec.InitContainers[0].Name).To(HaveValue(Equal("install-minecraft")))
+		Expect(spec.InitContainers[0].Image).To(HaveValue(Equal("gameservermanagers/gameserver:mc")))
+		Expect(spec.InitContainers[1].Name).To(HaveValue(Equal("install-mods")))
+		Expect(spec.Volumes).To(HaveLen(1))
+
+		Expect(env(spec.InitContainers[0])).To(And(
+			HaveKeyWithValue("INSTALL_KEY", "https://example.com/server.jar https://cdn.modrinth.com/fo.mrpack"),
+			HaveKeyWithValue("SERVER_SHA1", "-"),
+			HaveKeyWithValue("SERVER_INSTALLER", "false"),
+			HaveKeyWithValue("MODPACK_SHA1", "0123456789abcdef0123456789abcdef01234567"),
+			HaveKeyWithValue("MODPACK_FORMAT", "Mrpack"),
+			HaveKeyWithValue("MINECRAFT_EXECUTABLE", "java -Xmx${javaram}M -jar ${serverfiles}/minecraft_server.jar"),
+		))
+		Expect(spec.InitContainers[0].Command).To(HaveExactElements("/bin/bash", "-c",
+			HavePrefix(`CONFIG_FILE="${LGSM_CONFIG:-/data/config-lgsm}/mcserver/mcserver.cfg"`+"\n")))
+		Expect(env(spec.InitContainers[1])).To(HaveKeyWithValue("MODS_DIR", "/data/serverfiles/mods"))
+	})
+
+	It("starts Forge and NeoForge servers with the arguments of their installer", func() {
+		spec := podSpec(newGameServer(withServer(gamesv1alpha1.MinecraftLoaderForge, "52.0.16")))
+		Expect(env(spec.InitContainers[0])).To(And(
+			HaveKeyWithValue("SERVER_INSTALLER", "true"),
+			HaveKeyWithValue("MINECRAFT_EXECUTABLE",
+				"java -Xmx${javaram}M @libraries/net/minecraftforge/forge/1.21.1-52.0.16/unix_args.txt"),
+		))
+
+		spec = podSpec(newGameServer(withServer(gamesv1alpha1.MinecraftLoaderNeoForge, "21.1.77")))
+		Expect(env(spec.InitContainers[0])).To(HaveKeyWithValue("MINECRAFT_EXECUTABLE",
+			"java -Xmx${javaram}M @libraries/net/neoforged/neoforge/21.1.77/unix_args.txt"))
+	})
+
+	It("installs the plugins of Paper servers", func() {
+		spec := podSpec(newGameServer(withServer(gamesv1alpha1.MinecraftLoaderPaper, "231")))
+		Expect(env(spec.InitContainers[1])).To(HaveKeyWithValue("MODS_DIR", "/data/serverfiles/plugins"))
+	})
+
+	It("leaves installing the server to LinuxGSM without a version, loader or modpack", func() {
+		spec := podSpec(newGameServer(withServer(gamesv1alpha1.MinecraftLoaderVanilla, ""),
+			func(gs *gamesv1alpha1.GameServer) {
+				gs.Spec.GameConfigs.Minecraft.Modpack = nil
+			}))
+		Expect(spec.InitContainers).To(HaveLen(1))
+		Expect(spec.InitContainers[0].Name).To(HaveValue(Equal("install-mods")))
+	})
+})
+
+var _ = Describe("Minecraft config spec builders", func() {
+	withConfig := func(config gamesv1alpha1.MinecraftConfig) func(*gamesv1alpha1.GameServer) {
+		return func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "mc"
+			gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &config}
+		}
+	}
+
+	container := func(gs *gamesv1alpha1.GameServer) corev1ac.ContainerApplyConfiguration {
+		return specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec.Containers[0]
+	}
+
+	It("writes the properties into server.properties once the server is installed", func() {
+		gs := newGameServer(withConfig(gamesv1alpha1.MinecraftConfig{
+			Whitelist: []string{"Steve"},
+			Properties: &gamesv1alpha1.MinecraftProperties{
+				MOTD:       new(`Steve's \ server`),
+				Difficulty: "hard",
+				MaxPlayers: new(int32(10)),
+				OnlineMode: new(false),
+				Extra:      map[string]string{"enable-command-block": "true"},
+			},
+		}))
+		command := container(gs).Command
+		Expect(command).To(HaveLen(3))
+		Expect(command[2]).To(ContainSubstring(`if [ -n "$(ls -A "${serverfiles}" 2>/dev/null)" ]; then
+  set_config "${serverfiles}/server.properties" properties white-list 'true'
+  set_config "${serverfiles}/server.properties" properties enforce-whitelist 'true'
+  set_config "${serverfiles}/server.properties" properties enable-command-block 'true'
+  set_config "${serverfiles}/server.properties" properties motd 'Steve'\''s \\ server'
+  set_config "${serverfiles}/server.properties" properties difficulty 'hard'
+  set_config "${serverfiles}/server.properties" properties max-players '10'
+  set_config "${serverfiles}/server.properties" properties online-mode 'false'
+  if [ -f /config/minecraft/whitelist.json ]; then cp /config/minecraft/whitelist.json ` +
+			`"${serverfiles}/whitelist.json"; fi
+  if [ -f /config/minecraft/ops.json ]; then cp /config/minecraft/ops.json "${serverfiles}/ops.json"; fi
+fi
+exec /app/entrypoint-user.sh`))
+		Expect(container(gs).VolumeMounts).To(ContainElement(HaveField("MountPath", HaveValue(Equal("/config/minecraft")))))
+	})
+
+	It("installs the server into a fresh data volume before writing its config", func() {
+		gs := newGameServer(withConfig(gamesv1alpha1.MinecraftConfig{
+			Whitelist:  []string{"Steve"},
+			Properties: &gamesv1alpha1.MinecraftProperties{Extra: map[string]string{"level-seed": "42"}},
+		}))
+		script := container(gs).Command[2]
+		install := `if [ -z "$(ls -A "${LGSM_SERVERFILES:-/data/serverfiles}" 2>/dev/null)" ]; then
+  [ -f /app/mcserver ] || (cd /app && ./linuxgsm.sh mcserver)
+  /app/mcserver auto-install
+fi
+`
+		Expect(script).To(ContainSubstring(install))
+		Expect(strings.Index(script, install)).To(BeNumerically("<",
+			strings.Index(script, "properties white-list 'true'")))
+
+		By("leaving the install to LinuxGSM without persistent storage")
+		gs.Spec.Storage = &gamesv1alpha1.StorageSpec{Enabled: new(false)}
+		Expect(container(gs).Command).To(Equal([]string{"/app/entrypoint-user.sh"}))
+	})
+
+	It("writes the whitelist and ops of the resolved players", func() {
+		gs := newGameServer(withConfig(gamesv1alpha1.MinecraftConfig{
+			Whitelist: []string{"Steve", "Nobody"},
+			Ops:       []string{"Steve"},
+		}))
+		gs.Status.Minecraft = &gamesv1alpha1.MinecraftStatus{Players: []gamesv1alpha1.MinecraftPlayerStatus{
+			{Name: "Steve", UUID: "8667ba71-b85a-4004-af54-457a9734eed7"},
+			{Name: "Nobody", Message: "there is no Minecraft account named Nobody"},
+		}}
+
+		configMap := specs.BuildMinecraftPlayersConfigMap(gs)
+		Expect(configMap.Name).To(HaveValue(Equal("example-minecraft-players")))
+		Expect(configMap.Data).To(HaveKeyWithValue(specs.MinecraftWhitelistKey, `[
+  {
+    "uuid": "8667ba71-b85a-4004-af54-457a9734eed7",
+    "name": "Steve"
+  }
+]`))
+		Expect(configMap.Data).To(HaveKeyWithValue(specs.MinecraftOpsKey, `[
+  {
+    "uuid": "8667ba71-b85a-4004-af54-457a9734eed7",
+    "name": "Steve",
+    "level": 4,
+    "bypassesPlayerLimit": false
+  }
+]`))
+
+		By("leaving the files of lists that are not set alone")
+		gs.Spec.GameConfigs.Minecraft.Ops = nil
+		Expect(specs.BuildMinecraftPlayersConfigMap(gs).Data).NotTo(HaveKey(specs.MinecraftOpsKey))
+		gs.Spec.GameConfigs.Minecraft.Whitelist = nil
+		Expect(specs.BuildMinecraftPlayersConfigMap(gs)).To(BeNil())
+	})
+
+	It("leaves the server files alone without properties or players", func() {
+		gs := newGameServer(withConfig(gamesv1alpha1.MinecraftConfig{}))
+		Expect(container(gs).Command).To(Equal([]string{"/app/entrypoint-user.sh"}))
+	})
+})
@@ -0,0 +1,114 @@
+package specs
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+)
+
+const (
+	// modsImage downloads the mods, with rclone copyurl and the shell and sha1sum of its Alpine base.
+	modsImage = backupImage
+
+	modsContainerName   = "install-mods"
+	modsTmpVolumeName   = "tmp"
+	modsEnv             = "MODS"
+	modsDirEnv          = "MODS_DIR"
+	modsUnknownSHA1     = "-"
+	minecraftModsDir    = "/data/serverfiles/mods"
+	minecraftPluginsDir = "/data/serverfiles/plugins"
+	minecraftModsIndex  = ".gameserver-operator-mods"
+)
+
+// modsScript installs the mods listed in MODS, one per line as the SHA-1 hash or "-", the file name and the URL,
+// into the mods directory MODS_DIR. Mods it installed before that are no longer listed are removed, files it did not
+// install are left alone. Files that exist with the expected hash are not downloaded again.
+//
+// On the first start the data volume is empty and LinuxGSM installs the game, which it skips when the server files
+// exist. The mods are then installed from the next start on, unless the operator installs the server before.
+const modsScript = `set -eu
+if [ -z "$(ls -A /data/serverfiles 2>/dev/null)" ]; then
+  echo "The game is not installed yet, the mods are installed on the next start"
+  exit 0
+fi
+mods="${MODS_DIR}"
+index="${mods}/` + minecraftModsIndex + `"
+mkdir -p "${mods}"
+touch "${index}"
+printf '%s\n' "${MODS}" | awk 'NF == 3 { print $2 }' > /tmp/listed
+while read -r file; do
+  grep -qxF "${file}" /tmp/listed || rm -f "${mods}/${file}"
+done < "${index}"
+printf '%s\n' "${MODS}" | while read -r sha1 file url; do
+  [ -n "${url}" ] || continue
+  if [ -f "${mods}/${file}" ] && { [ "${sha1}" = "-" ] ||
+    [ "$(sha1sum "${mods}/${file}" | cut -d ' ' -f 1)" = "${sha1}" ]; }; then
+    continue
+  fi
+  echo "Downloading ${file}"
+  rclone copyurl "${url}" "/tmp/${file}"
+  if [ "${sha1}" != "-" ] && [ "$(sha1sum "/tmp/${file}" | cut -d ' ' -f 1)" != "${sha1}" ]; then
+    echo "The SHA-1 hash of ${file} is not ${sha1}" >&2
+    exit 1
+  fi
+  mv "/tmp/${file}" "${mods}/${file}"
+done
+cp /tmp/listed "${index}"
+`
+
+// GameServerMinecraftMods returns the mods of the Minecraft config of the game server, if any.
+func GameServerMinecraftMods(gs *gamesv1alpha1.GameServer) []gamesv1alpha1.MinecraftMod {
+	if gs.Spec.GameConfigs == nil || gs.Spec.GameConfigs.Minecraft == nil {
+		return nil
+	}
+	return gs.Spec.GameConfigs.Minecraft.Mods
+}
+
+// buildModsInitContainer returns the init container installing the mods resolved in status.minecraft.mods,
+// or nil when the game server never had mods. The init container is kept once all mods are removed from the spec,
+// so the mods it installed are removed as well.
+func buildModsInitContainer(gs *gamesv1alpha1.GameServer) *corev1ac.ContainerApplyConfiguration {
+	if gs.Status.Minecraft == nil {
+		return nil
+	}
+
+	modsDir := minecraftModsDir
+	if server := minecraftServerStatus(gs); server != nil && server.Loader == gamesv1alpha1.MinecraftLoaderPaper {
+		// Paper loads plugins, which are published as mods on Modrinth and CurseForge.
+		modsDir = minecraftPluginsDir
+	}
+
+	var mods strings.Builder
+	for _, mod := range gs.Status.Minecraft.Mods {
+		if mod.File == nil {
+			continue
+		}
+		sha1 := mod.File.SHA1
+		if sha1 == "" {
+			sha1 = modsUnknownSHA1
+		}
+		fmt.Fprintf(&mods, "%s %s %s\n", sha1, mod.File.Name, mod.File.URL)
+	}
+
+	return corev1ac.Container().
+		WithName(modsContainerName).
+		WithImage(modsImage).
+		WithImagePullPolicy(v1.PullIfNotPresent).
+		WithCommand("/bin/sh", "-c", modsScript).
+		WithSecurityContext(restrictedContainerSecurityContext().
+			WithReadOnlyRootFilesystem(true),
+		).
+		WithEnv(
+			corev1ac.EnvVar().WithName(modsEnv).WithValue(mods.String()),
+			corev1ac.EnvVar().WithName(modsDirEnv).WithValue(modsDir),
+			corev1ac.EnvVar().WithName("HOME").WithValue("/tmp"),
+		).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().WithName(dataVolumeName).WithMountPath("/data"),
+			corev1ac.VolumeMount().WithName(modsTmpVolumeName).WithMountPath("/tmp"),
+		)
+}
@@ -0,0 +1,74 @@
+package specs_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Mod spec builders", func() {
+	sodium := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceModrinth, Project: "sodium"}
+	jei := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceCurseForge, Project: "238222"}
+	custom := gamesv1alpha1.MinecraftMod{Source: gamesv1alpha1.MinecraftModSourceURL, URL: "https://example.com/a.jar"}
+
+	withMods := func(gs *gamesv1alpha1.GameServer) {
+		gs.Spec.GameName = "mc"
+		gs.Spec.GameConfigs = &gamesv1alpha1.GameConfigs{Minecraft: &gamesv1alpha1.MinecraftConfig{
+			Mods: []gamesv1alpha1.MinecraftMod{sodium, jei, custom},
+		}}
+		gs.Status.Minecraft = &gamesv1alpha1.MinecraftStatus{Mods: []gamesv1alpha1.MinecraftModStatus{
+			{Mod: sodium, File: &gamesv1alpha1.MinecraftModFile{
+				Name: "sodium-0.6.0.jar",
+				URL:  "https://cdn.modrinth.com/sodium-0.6.0.jar",
+				SHA1: "0123456789abcdef0123456789abcdef01234567",
+			}},
+			{Mod: jei, Message: "mods on CurseForge cannot be resolved, the operator has no CurseForge API key"},
+			{Mod: custom, File: &gamesv1alpha1.MinecraftModFile{Name: "a.jar", URL: "https://example.com/a.jar"}},
+		}}
+	}
+
+	podSpec := func(gs *gamesv1alpha1.GameServer) *corev1ac.PodSpecApplyConfiguration {
+		return specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec
+	}
+
+	It("installs the resolved mods with an init container", func() {
+		spec := podSpec(newGameServer(withMods))
+		Expect(spec.InitContainers).To(HaveLen(1))
+		initContainer := spec.InitContainers[0]
+		Expect(initContainer.Name).To(HaveValue(Equal("install-mods")))
+		Expect(initContainer.SecurityContext.ReadOnlyRootFilesystem).To(HaveValue(BeTrue()))
+		Expect(initContainer.Env).NotTo(BeEmpty())
+		Expect(initContainer.Env[0].Name).To(HaveValue(Equal("MODS")))
+		Expect(initContainer.Env[0].Value).To(HaveValue(Equal(
+			"0123456789abcdef0123456789abcdef01234567 sodium-0.6.0.jar https://cdn.modrinth.com/sodium-0.6.0.jar\n" +
+				"- a.jar https://example.com/a.jar\n")))
+
+		var mountPaths []string
+		for _, mount := range initContainer.VolumeMounts {
+			mountPaths = append(mountPaths, *mount.MountPath)
+		}
+		Expect(mountPaths).To(ConsistOf("/data", "/tmp"))
+		Expect(spec.Volumes).To(HaveLen(1))
+		Expect(spec.Volumes[0].EmptyDir).NotTo(BeNil())
+	})
+
+	It("installs no mods before they are resolved", func() {
+		spec := podSpec(newGameServer(withMods, func(gs *gamesv1alpha1.GameServer) {
+			gs.Status.Minecraft = nil
+		}))
+		Expect(spec.InitContainers).To(BeEmpty())
+	})
+
+	It("removes the mods once they are no longer listed", func() {
+		spec := podSpec(newGameServer(withMods, func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameConfigs.Minecraft.Mods = nil
+			gs.Status.Minecraft.Mods = nil
+		}))
+		Expect(spec.InitContainers).To(HaveLen(1))
+		Expect(spec.InitContainers[0].Env[0].Value).To(HaveValue(BeEmpty()))
+	})
+})
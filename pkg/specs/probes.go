@@ -0,0 +1,172 @@
+package specs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+)
+
+const (
+	// defaultStartupTimeout leaves room for installing games that download tens of gigabytes.
+	defaultStartupTimeout = 2 * time.Hour
+
+	// probePeriodSeconds and probeTimeoutSeconds apply to the startup and readiness probes of the game.
+	probePeriodSeconds        = 10
+	probeTimeoutSeconds       = 5
+	readinessFailureThreshold = 3
+
+	// The LinuxGSM monitor command queries the game and starts it again when it stopped, which takes a while.
+	monitorPeriodSeconds     = 60
+	monitorTimeoutSeconds    = 60
+	livenessFailureThreshold = 3
+)
+
+// GameServerProbe returns how the game server is probed and the container port the probes connect to.
+// Games the operator knows how to query are queried, games with a TCP game port are probed on it and
+// other games are not probed, unless spec.probes says otherwise.
+func GameServerProbe(gs *gamesv1alpha1.GameServer) (gamesv1alpha1.ProbeType, int32) {
+	var spec gamesv1alpha1.ProbesSpec
+	if gs.Spec.Probes != nil {
+		spec = *gs.Spec.Probes
+	}
+
+	q, queried := linuxgsm.LookupQuery(gs.Spec.GameName)
+	probeType := spec.Type
+	if probeType == "" {
+		switch {
+		case queried && q.Protocol == query.ProtocolA2S:
+			probeType = gamesv1alpha1.ProbeTypeA2S
+		case queried && q.Protocol == query.ProtocolMinecraft:
+			probeType = gamesv1alpha1.ProbeTypeMinecraft
+		case gameContainerPort(gs, "game", corev1.ProtocolTCP) != 0:
+			probeType = gamesv1alpha1.ProbeTypeTCP
+		default:
+			probeType = gamesv1alpha1.ProbeTypeNone
+		}
+	}
+
+	port := spec.Port
+	if port == 0 {
+		switch probeType {
+		case gamesv1alpha1.ProbeTypeA2S:
+			port = gameContainerPort(gs, q.PortName, corev1.ProtocolUDP)
+		case gamesv1alpha1.ProbeTypeMinecraft:
+			port = gameContainerPort(gs, q.PortName, corev1.ProtocolTCP)
+		case gamesv1alpha1.ProbeTypeTCP:
+			port = gameContainerPort(gs, "game", corev1.ProtocolTCP)
+		}
+	}
+	return probeType, port
+}
+
+// gameContainerPort returns the container port of the named port of the game, taken from the Service when it
+// exposes the port and from the game defaults otherwise. It returns 0 when the game has no such port.
+func gameContainerPort(gs *gamesv1alpha1.GameServer, name string, protocol corev1.Protocol) int32 {
+	if name == "" {
+		return 0
+	}
+	if gs.Spec.Service != nil {
+		for _, port := range gs.Spec.Service.Ports {
+			if port.Name != name || port.Protocol != protocol {
+				continue
+			}
+			if port.TargetPort.IntVal != 0 {
+				return port.TargetPort.IntVal
+			}
+			if port.TargetPort.StrVal == "" {
+				return port.Port
+			}
+		}
+	}
+
+	defaults, _ := linuxgsm.LookupDefaults(gs.Spec.GameName)
+	for _, port := range defaults.Ports {
+		if port.Name == name && port.Protocol == protocol {
+			return port.Port
+		}
+	}
+	return 0
+}
+
+// buildProbes sets the startup, readiness and liveness probes of the game server container.
+func buildProbes(gs *gamesv1alpha1.GameServer, container *corev1ac.ContainerApplyConfiguration) {
+	probeType, port := GameServerProbe(gs)
+	if probeType == gamesv1alpha1.ProbeTypeNone {
+		return
+	}
+
+	startupTimeout := defaultStartupTimeout
+	liveness := true
+	if gs.Spec.Probes != nil {
+		if gs.Spec.Probes.StartupTimeout != nil {
+			startupTimeout = gs.Spec.Probes.StartupTimeout.Duration
+		}
+		if gs.Spec.Probes.Liveness != nil {
+			liveness = *gs.Spec.Probes.Liveness
+		}
+	}
+
+	period, timeout := int32(probePeriodSeconds), int32(probeTimeoutSeconds)
+	if probeType == gamesv1alpha1.ProbeTypeMonitor {
+		period, timeout = monitorPeriodSeconds, monitorTimeoutSeconds
+	}
+	startupFailureThreshold := max(int32(startupTimeout/(time.Duration(period)*time.Second)), 1)
+
+	container.WithStartupProbe(buildProbe(gs, probeType, port).
+		WithPeriodSeconds(period).
+		WithTimeoutSeconds(timeout).
+		WithFailureThreshold(startupFailureThreshold))
+	container.WithReadinessProbe(buildProbe(gs, probeType, port).
+		WithPeriodSeconds(period).
+		WithTimeoutSeconds(timeout).
+		WithFailureThreshold(readinessFailureThreshold))
+	if liveness {
+		container.WithLivenessProbe(buildProbe(gs, gamesv1alpha1.ProbeTypeMonitor, 0).
+			WithPeriodSeconds(monitorPeriodSeconds).
+			WithTimeoutSeconds(monitorTimeoutSeconds).
+			WithFailureThreshold(livenessFailureThreshold))
+	}
+}
+
+// buildProbe returns a probe checking the game. The A2S and Minecraft probes send a query with the bash
+// network redirections, as the LinuxGSM images have no tools to query games, and succeed on any response.
+func buildProbe(
+	gs *gamesv1alpha1.GameServer,
+	probeType gamesv1alpha1.ProbeType,
+	port int32,
+) *corev1ac.ProbeApplyConfiguration {
+	probe := corev1ac.Probe()
+	switch probeType {
+	case gamesv1alpha1.ProbeTypeTCP:
+		probe.WithTCPSocket(corev1ac.TCPSocketAction().WithPort(intstr.FromInt32(port)))
+	case gamesv1alpha1.ProbeTypeA2S:
+		probe.WithExec(corev1ac.ExecAction().WithCommand("/bin/bash", "-c",
+			queryProbeScript("udp", port, query.A2SInfoRequest())))
+	case gamesv1alpha1.ProbeTypeMinecraft:
+		probe.WithExec(corev1ac.ExecAction().WithCommand("/bin/bash", "-c",
+			queryProbeScript("tcp", port, query.MinecraftStatusRequest("localhost", uint16(port)))))
+	default:
+		game, _ := linuxgsm.LookupGame(gs.Spec.GameName)
+		probe.WithExec(corev1ac.ExecAction().WithCommand(game.Command(linuxgsm.CommandMonitor)...))
+	}
+	return probe
+}
+
+// queryProbeScript returns a bash script sending request to the local port and waiting for the first byte of
+// the response.
+func queryProbeScript(network string, port int32, request []byte) string {
+	var escaped strings.Builder
+	for _, b := range request {
+		fmt.Fprintf(&escaped, `\x%02x`, b)
+	}
+	return fmt.Sprintf(`exec 3<>/dev/%s/127.0.0.1/%d && printf '%s' >&3 && `+
+		`[ "$(timeout %d head -c 1 <&3 | wc -c)" -eq 1 ]`, network, port, escaped.String(), probeTimeoutSeconds-1)
+}
@@ -0,0 +1,99 @@
+package specs_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Probe spec builders", func() {
+	container := func(gs *gamesv1alpha1.GameServer) corev1ac.ContainerApplyConfiguration {
+		return specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec.Containers[0]
+	}
+
+	It("queries games the operator knows how to query", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) { gs.Spec.GameName = "vh" })
+		probeType, port := specs.GameServerProbe(gs)
+		Expect(probeType).To(Equal(gamesv1alpha1.ProbeTypeA2S))
+		Expect(port).To(BeEquivalentTo(2457))
+
+		probes := container(gs)
+		Expect(probes.ReadinessProbe.Exec.Command).To(HaveExactElements("/bin/bash", "-c", And(
+			HavePrefix("exec 3<>/dev/udp/127.0.0.1/2457 && printf '\\xff\\xff\\xff\\xff\\x54\\x53\\x6f"),
+			HaveSuffix(`[ "$(timeout 4 head -c 1 <&3 | wc -c)" -eq 1 ]`),
+		)))
+		Expect(*probes.ReadinessProbe.PeriodSeconds).To(BeEquivalentTo(10))
+		Expect(*probes.ReadinessProbe.FailureThreshold).To(BeEquivalentTo(3))
+
+		By("giving the first start two hours to install the game")
+		Expect(probes.StartupProbe.Exec.Command).To(Equal(probes.ReadinessProbe.Exec.Command))
+		Expect(*probes.StartupProbe.FailureThreshold).To(BeEquivalentTo(720))
+
+		By("restarting the game with the LinuxGSM monitor command")
+		Expect(probes.LivenessProbe.Exec.Command).To(Equal([]string{"/app/vhserver", "monitor"}))
+	})
+
+	It("pings Minecraft servers on the target port of the game port", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "mc"
+			gs.Spec.Service = &gamesv1alpha1.ServiceSpec{Ports: []gamesv1alpha1.ServicePort{{
+				Name: "game", Port: 25565, TargetPort: intstr.FromInt32(25566), Protocol: corev1.ProtocolTCP,
+			}}}
+		})
+		probeType, port := specs.GameServerProbe(gs)
+		Expect(probeType).To(Equal(gamesv1alpha1.ProbeTypeMinecraft))
+		Expect(port).To(BeEquivalentTo(25566))
+		Expect(container(gs).ReadinessProbe.Exec.Command[2]).To(HavePrefix("exec 3<>/dev/tcp/127.0.0.1/25566 && "))
+	})
+
+	It("connects to the TCP game port of games that cannot be queried", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) { gs.Spec.GameName = "terraria" })
+		Expect(container(gs).ReadinessProbe.TCPSocket.Port).To(HaveValue(Equal(intstr.FromInt32(7777))))
+	})
+
+	It("does not probe games without a known port", func() {
+		probes := container(newGameServer())
+		Expect(probes.StartupProbe).To(BeNil())
+		Expect(probes.ReadinessProbe).To(BeNil())
+		Expect(probes.LivenessProbe).To(BeNil())
+	})
+
+	It("applies spec.probes", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "vh"
+			gs.Spec.Probes = &gamesv1alpha1.ProbesSpec{
+				Type:           gamesv1alpha1.ProbeTypeTCP,
+				Port:           2456,
+				StartupTimeout: &metav1.Duration{Duration: 30 * time.Minute},
+				Liveness:       new(false),
+			}
+		})
+		probes := container(gs)
+		Expect(probes.ReadinessProbe.TCPSocket.Port).To(HaveValue(Equal(intstr.FromInt32(2456))))
+		Expect(*probes.StartupProbe.FailureThreshold).To(BeEquivalentTo(180))
+		Expect(probes.LivenessProbe).To(BeNil())
+
+		gs.Spec.Probes = &gamesv1alpha1.ProbesSpec{Type: gamesv1alpha1.ProbeTypeNone}
+		Expect(container(gs).ReadinessProbe).To(BeNil())
+	})
+
+	It("runs the LinuxGSM monitor command less often", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "vh"
+			gs.Spec.Probes = &gamesv1alpha1.ProbesSpec{Type: gamesv1alpha1.ProbeTypeMonitor}
+		})
+		probes := container(gs)
+		Expect(probes.ReadinessProbe.Exec.Command).To(Equal([]string{"/app/vhserver", "monitor"}))
+		Expect(*probes.ReadinessProbe.PeriodSeconds).To(BeEquivalentTo(60))
+		Expect(*probes.StartupProbe.FailureThreshold).To(BeEquivalentTo(120))
+	})
+})
@@ -0,0 +1,158 @@
+package specs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+)
+
+const (
+	linuxGSMEntrypoint = "/app/entrypoint-user.sh"
+
+	rconPasswordEnv = "RCON_PASSWORD"
+	rconPortEnv     = "RCON_PORT"
+)
+
+// setConfigFunction defines the set_config shell function, which replaces a setting in a config file.
+// LinuxGSM configs are sourced by bash, so their values are quoted with printf %q.
+const setConfigFunction = `set -eu
+# set_config FILE FORMAT NAME VALUE
+set_config() {
+  case "$2" in
+    linuxgsm) line="$3=$(printf '%q' "$4")" ;;
+    properties) line="$3=$4" ;;
+    source) line="$3 \"$4\"" ;;
+  esac
+  mkdir -p "$(dirname "$1")"
+  touch "$1"
+  awk -v name="$3" '{ s = $0; sub(/^[ \t]+/, "", s) }
+    index(s, name "=") != 1 && index(s, name " ") != 1 && index(s, name "\t") != 1' "$1" > "$1.tmp"
+  printf '%s\n' "${line}" >> "$1.tmp"
+  mv "$1.tmp" "$1"
+}
+`
+
+// GameServerRCON returns the protocol and port of the remote console of the game server, falling back to
+// those of the game. The port is zero when RCON is not configured or the game has no known RCON port.
+func GameServerRCON(gs *gamesv1alpha1.GameServer) (rcon.Protocol, int32) {
+	if gs.Spec.RCON == nil {
+		return "", 0
+	}
+	game, _ := linuxgsm.LookupRCON(gs.Spec.GameName)
+
+	protocol := rcon.Protocol(gs.Spec.RCON.Protocol)
+	if protocol == "" {
+		protocol = game.Protocol
+	}
+	if protocol == "" {
+		protocol = rcon.ProtocolSource
+	}
+
+	port := gs.Spec.RCON.Port
+	if port == 0 {
+		port = game.Port
+	}
+	return protocol, port
+}
+
+// buildRCONEnv passes the RCON password and port to the game server container, for the config script.
+func buildRCONEnv(gs *gamesv1alpha1.GameServer) []*corev1ac.EnvVarApplyConfiguration {
+	secretRef := GameServerRCONPasswordSecretRef(gs)
+	if secretRef == nil {
+		return nil
+	}
+
+	env := []*corev1ac.EnvVarApplyConfiguration{secretEnvVar(rconPasswordEnv, secretRef)}
+	if _, port := GameServerRCON(gs); port != 0 {
+		env = append(env, corev1ac.EnvVar().
+			WithName(rconPortEnv).
+			WithValue(strconv.Itoa(int(port))),
+		)
+	}
+	return env
+}
+
+// buildConfigScript returns the script writing the config of the game before LinuxGSM starts it,
+// or an empty string when there is nothing to configure.
+func buildConfigScript(gs *gamesv1alpha1.GameServer) string {
+	// Settings managed by the operator are written last, so they are not overwritten by the config of the user.
+	// The config of the LinuxGSM instance is written before the game is installed, as it configures the install.
+	config := buildLinuxGSMConfigScript(gs) + buildInstallScript(gs)
+	if LinuxGSMStorageEnabled(gs) {
+		config += buildConfigFilesScript(gs) + buildMinecraftConfigScript(gs)
+	}
+	config += buildSecretsConfigScript(gs) + buildRCONConfigScript(gs)
+	if config == "" {
+		return ""
+	}
+	return setConfigFunction + config + "exec " + linuxGSMEntrypoint + "\n"
+}
+
+// buildRCONConfigScript returns the part of the config script enabling RCON in the config of the game,
+// or an empty string when RCON is not configured.
+//
+// Settings of LinuxGSM are written to the config of the LinuxGSM instance, which LinuxGSM never overwrites.
+// Config files of the game are created when LinuxGSM installs it, so they are only updated when they exist. The
+// config script installs the game first when it is not, so they exist before the first start.
+func buildRCONConfigScript(gs *gamesv1alpha1.GameServer) string {
+	if GameServerRCONPasswordSecretRef(gs) == nil {
+		return ""
+	}
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return ""
+	}
+	r, ok := linuxgsm.LookupRCON(gs.Spec.GameName)
+	if !ok {
+		return ""
+	}
+
+	file := linuxGSMInstanceConfig(game)
+	if r.ConfigFile != "" {
+		file = `${LGSM_SERVERFILES:-/data/serverfiles}/` + r.ConfigFile
+	}
+
+	var settings []string
+	setConfig := func(name, value string) {
+		settings = append(settings, fmt.Sprintf(`set_config "%s" %s %s %s`, file, r.ConfigFormat, name, value))
+	}
+	for _, setting := range r.Settings {
+		setConfig(setting.Name, shellQuote(setting.Value))
+	}
+	if r.PortSetting != "" {
+		setConfig(r.PortSetting, `"${`+rconPortEnv+`}"`)
+	}
+	setConfig(r.PasswordSetting, `"${`+rconPasswordEnv+`}"`)
+
+	var script strings.Builder
+	if r.ConfigFile != "" {
+		fmt.Fprintf(&script, "if [ -f \"%s\" ]; then\n", file)
+		for _, setting := range settings {
+			fmt.Fprintf(&script, "  %s\n", setting)
+		}
+		script.WriteString("fi\n")
+	} else {
+		script.WriteString(strings.Join(settings, "\n") + "\n")
+	}
+	return script.String()
+}
+
+// rconConfiguresServerFiles reports whether the RCON settings are written into a config file of the game.
+func rconConfiguresServerFiles(gs *gamesv1alpha1.GameServer) bool {
+	if GameServerRCONPasswordSecretRef(gs) == nil {
+		return false
+	}
+	r, ok := linuxgsm.LookupRCON(gs.Spec.GameName)
+	return ok && r.ConfigFile != ""
+}
+
+// shellQuote quotes s as a single word for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,92 @@
+package specs_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/rcon"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("RCON spec builders", func() {
+	withRCON := func(gameName string) func(*gamesv1alpha1.GameServer) {
+		return func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = gameName
+			gs.Spec.RCON = &gamesv1alpha1.RCONSpec{
+				PasswordSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "rcon"},
+					Key:                  "password",
+				},
+			}
+		}
+	}
+
+	container := func(gs *gamesv1alpha1.GameServer) corev1ac.ContainerApplyConfiguration {
+		GinkgoHelper()
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec
+		Expect(podSpec.Containers).To(HaveLen(1))
+		return podSpec.Containers[0]
+	}
+
+	envNames := func(c corev1ac.ContainerApplyConfiguration) []string {
+		var names []string
+		for _, env := range c.Env {
+			names = append(names, *env.Name)
+		}
+		return names
+	}
+
+	It("falls back to the protocol and port of the game", func() {
+		gs := newGameServer(withRCON("rust"))
+		protocol, port := specs.GameServerRCON(gs)
+		Expect(protocol).To(Equal(rcon.ProtocolWebRCON))
+		Expect(port).To(Equal(int32(28016)))
+
+		gs.Spec.RCON.Port = 28017
+		_, port = specs.GameServerRCON(gs)
+		Expect(port).To(Equal(int32(28017)))
+
+		gs = newGameServer(withRCON("vh"))
+		protocol, port = specs.GameServerRCON(gs)
+		Expect(protocol).To(Equal(rcon.ProtocolSource))
+		Expect(port).To(BeZero())
+	})
+
+	It("enables RCON in the LinuxGSM config before starting the game", func() {
+		c := container(newGameServer(withRCON("rust")))
+
+		Expect(envNames(c)).To(Equal([]string{"UPDATE_CHECK", "RCON_PASSWORD", "RCON_PORT"}))
+		Expect(c.Env[1].ValueFrom.SecretKeyRef.Name).To(HaveValue(Equal("rcon")))
+		Expect(c.Env[1].ValueFrom.SecretKeyRef.Key).To(HaveValue(Equal("password")))
+		Expect(c.Env[2].Value).To(HaveValue(Equal("28016")))
+
+		Expect(c.Command).To(HaveLen(3))
+		Expect(c.Command[:2]).To(Equal([]string{"/bin/bash", "-c"}))
+		script := c.Command[2]
+		Expect(script).To(ContainSubstring(`set_config "${LGSM_CONFIG:-/data/config-lgsm}/rustserver/rustserver.cfg" ` +
+			`linuxgsm rconpassword "${RCON_PASSWORD}"`))
+		Expect(script).To(ContainSubstring(`linuxgsm rconweb '1'`))
+		Expect(script).To(HaveSuffix("exec /app/entrypoint-user.sh\n"))
+		Expect(script).NotTo(ContainSubstring("auto-install"))
+	})
+
+	It("only updates config files of the game once it is installed", func() {
+		script := container(newGameServer(withRCON("mc"))).Command[2]
+		Expect(script).To(ContainSubstring("  /app/mcserver auto-install\nfi\n"))
+		Expect(script).To(ContainSubstring(`if [ -f "${LGSM_SERVERFILES:-/data/serverfiles}/server.properties" ]; then`))
+		Expect(script).To(ContainSubstring(`properties rcon.port "${RCON_PORT}"`))
+		Expect(script).To(ContainSubstring(`properties enable-rcon 'true'`))
+	})
+
+	It("leaves the config of games with unknown RCON settings alone", func() {
+		gs := newGameServer(withRCON("vh"))
+		gs.Spec.RCON.Port = 2459
+		c := container(gs)
+		Expect(c.Command).To(Equal([]string{"/app/entrypoint-user.sh"}))
+		Expect(envNames(c)).To(Equal([]string{"UPDATE_CHECK", "RCON_PASSWORD", "RCON_PORT"}))
+	})
+})
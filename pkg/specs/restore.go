@@ -0,0 +1,124 @@
+package specs
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	batchv1ac "k8s.io/client-go/applyconfigurations/batch/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/utils"
+)
+
+const restoreContainerName = "restore"
+
+// restoreScript downloads the archive, verifies its checksum against the expected one or the checksum file
+// stored by the backup, and only then replaces the contents of the data volume. A checksum mismatch is
+// written to the termination message, which the controller reports on the GameServerRestore.
+const restoreScript = `set -eu
+mkdir -p /tmp/restore
+rclone copyto "${BACKUP_TARGET}/${BACKUP_NAME}" "/tmp/restore/${BACKUP_NAME}"
+expected="${BACKUP_SHA256}"
+if [ -z "${expected}" ]; then
+  rclone copyto "${BACKUP_TARGET}/${BACKUP_NAME}.sha256" "/tmp/restore/${BACKUP_NAME}.sha256"
+  expected="$(cut -d ' ' -f 1 "/tmp/restore/${BACKUP_NAME}.sha256")"
+fi
+actual="$(sha256sum "/tmp/restore/${BACKUP_NAME}" | cut -d ' ' -f 1)"
+if [ "${actual}" != "${expected}" ]; then
+  echo "checksum mismatch: expected ${expected}, got ${actual}" | tee /dev/termination-log >&2
+  exit 1
+fi
+find /data -mindepth 1 -delete
+tar -xzf "/tmp/restore/${BACKUP_NAME}" -C /data
+`
+
+// GameServerRestoreJobName returns the name of the Job restoring the archive of the GameServerRestore.
+func GameServerRestoreJobName(restore *gamesv1alpha1.GameServerRestore) string {
+	return fmt.Sprintf("%s-restore", restore.Name)
+}
+
+// GameServerRestoreLabels returns the labels of restore Jobs and their pods.
+// Like the backup labels, they deliberately differ from the game server labels.
+func GameServerRestoreLabels(gs *gamesv1alpha1.GameServer) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":   gs.Name,
+		"app.kubernetes.io/managed-by": utils.GameServerControllerName,
+		"app.kubernetes.io/component":  "restore",
+	}
+}
+
+// GameServerRestoreTarget returns the backup target the archive of the GameServerRestore is read from,
+// or nil when neither the restore nor the GameServer configures one. Snapshot backups have no target,
+// a GameServer is restored from a snapshot by creating it with spec.storage.fromSnapshot.
+func GameServerRestoreTarget(
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) *gamesv1alpha1.BackupTarget {
+	if restore.Spec.Backup.Target != nil {
+		return restore.Spec.Backup.Target
+	}
+	if gs.Spec.Backup != nil && GameServerBackupMode(gs) == gamesv1alpha1.BackupModeArchive {
+		return &gs.Spec.Backup.Target
+	}
+	return nil
+}
+
+// BuildGameServerRestoreJob builds the Job restoring the archive of the GameServerRestore onto the data
+// volume of the game server, or returns nil when there is no backup target to restore from.
+func BuildGameServerRestoreJob(
+	gs *gamesv1alpha1.GameServer,
+	restore *gamesv1alpha1.GameServerRestore,
+) *batchv1ac.JobApplyConfiguration {
+	target := GameServerRestoreTarget(gs, restore)
+	if target == nil {
+		return nil
+	}
+
+	container := corev1ac.Container().
+		WithName(restoreContainerName).
+		WithImage(backupImage).
+		WithImagePullPolicy(v1.PullIfNotPresent).
+		WithCommand("/bin/sh", "-c", restoreScript).
+		WithSecurityContext(restrictedContainerSecurityContext().
+			WithReadOnlyRootFilesystem(true),
+		).
+		WithEnv(
+			corev1ac.EnvVar().WithName("BACKUP_NAME").WithValue(restore.Spec.Backup.Name),
+			corev1ac.EnvVar().WithName("BACKUP_SHA256").WithValue(restore.Spec.Backup.SHA256),
+			corev1ac.EnvVar().WithName("HOME").WithValue("/tmp"),
+		).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().WithName(dataVolumeName).WithMountPath("/data"),
+			corev1ac.VolumeMount().WithName(backupTmpVolumeName).WithMountPath("/tmp"),
+		)
+
+	podSpec := corev1ac.PodSpec().
+		WithRestartPolicy(v1.RestartPolicyNever).
+		WithAutomountServiceAccountToken(false).
+		WithSecurityContext(linuxGSMPodSecurityContext()).
+		WithAffinity(gameServerAntiAffinity(gs)).
+		WithVolumes(
+			corev1ac.Volume().
+				WithName(dataVolumeName).
+				WithPersistentVolumeClaim(corev1ac.PersistentVolumeClaimVolumeSource().
+					WithClaimName(GameServerDataVolumeClaimName(gs)),
+				),
+			corev1ac.Volume().
+				WithName(backupTmpVolumeName).
+				WithEmptyDir(corev1ac.EmptyDirVolumeSource()),
+		)
+
+	withBackupTarget(gs, *target, container, podSpec)
+
+	return batchv1ac.Job(GameServerRestoreJobName(restore), restore.Namespace).
+		WithLabels(GameServerRestoreLabels(gs)).
+		WithSpec(batchv1ac.JobSpec().
+			WithBackoffLimit(backupJobBackoffLimit).
+			WithActiveDeadlineSeconds(int64(BackupJobDeadline.Seconds())).
+			WithTemplate(corev1ac.PodTemplateSpec().
+				WithLabels(GameServerRestoreLabels(gs)).
+				WithSpec(podSpec.WithContainers(container)),
+			),
+		)
+}
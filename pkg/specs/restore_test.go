@@ -0,0 +1,78 @@
+package specs_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Restore spec builders", func() {
+	newRestore := func(source gamesv1alpha1.RestoreBackupSource) *gamesv1alpha1.GameServerRestore {
+		return &gamesv1alpha1.GameServerRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-restore", Namespace: "default"},
+			Spec: gamesv1alpha1.GameServerRestoreSpec{
+				GameServerRef: corev1.LocalObjectReference{Name: "example"},
+				Backup:        source,
+			},
+		}
+	}
+
+	pvcTarget := gamesv1alpha1.BackupTarget{
+		PersistentVolumeClaim: &gamesv1alpha1.PersistentVolumeClaimBackupTarget{ClaimName: "backups"},
+	}
+
+	It("returns nil when there is no backup target to restore from", func() {
+		restore := newRestore(gamesv1alpha1.RestoreBackupSource{Name: "example-20250101000000.tar.gz"})
+		Expect(specs.BuildGameServerRestoreJob(newGameServer(), restore)).To(BeNil())
+	})
+
+	It("restores from the backup target of the GameServer onto its data volume", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.Backup = &gamesv1alpha1.BackupSpec{Schedule: "0 4 * * *", Target: pvcTarget}
+		})
+		restore := newRestore(gamesv1alpha1.RestoreBackupSource{
+			Name:   "example-20250101000000.tar.gz",
+			SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		})
+
+		job := specs.BuildGameServerRestoreJob(gs, restore)
+		Expect(job).NotTo(BeNil())
+		Expect(*job.Name).To(Equal("example-restore-restore"))
+		Expect(job.Labels).To(Equal(specs.GameServerRestoreLabels(gs)))
+		Expect(job.Spec.ActiveDeadlineSeconds).To(HaveValue(Equal(int64(4 * 60 * 60))))
+
+		podSpec := job.Spec.Template.Spec
+		Expect(podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].
+			LabelSelector.MatchLabels).To(Equal(expectedLabels(gs)))
+
+		for _, volume := range podSpec.Volumes {
+			if *volume.Name == "data" {
+				Expect(*volume.PersistentVolumeClaim.ClaimName).To(Equal("data-example-0"))
+				Expect(volume.PersistentVolumeClaim.ReadOnly).To(BeNil(), "the restore replaces the data")
+			}
+		}
+
+		env := map[string]string{}
+		for _, e := range podSpec.Containers[0].Env {
+			if e.Value != nil {
+				env[*e.Name] = *e.Value
+			}
+		}
+		Expect(env).To(HaveKeyWithValue("BACKUP_TARGET", "/backups/example"))
+		Expect(env).To(HaveKeyWithValue("BACKUP_NAME", "example-20250101000000.tar.gz"))
+		Expect(env).To(HaveKeyWithValue("BACKUP_SHA256", restore.Spec.Backup.SHA256))
+	})
+
+	It("prefers the backup target of the restore", func() {
+		gs := newGameServer()
+		restore := newRestore(gamesv1alpha1.RestoreBackupSource{Name: "old-20250101000000.tar.gz", Target: &pvcTarget})
+
+		Expect(specs.GameServerRestoreTarget(gs, restore)).To(Equal(&pvcTarget))
+		Expect(specs.BuildGameServerRestoreJob(gs, restore)).NotTo(BeNil())
+	})
+})
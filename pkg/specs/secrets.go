@@ -0,0 +1,206 @@
+package specs
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+)
+
+// Keys of the passwords in the Secret the operator generates them into.
+const (
+	SecretKeyServerPassword = "serverPassword"
+	SecretKeyRCONPassword   = "rconPassword"
+	SecretKeyAdminPassword  = "adminPassword"
+)
+
+const (
+	serverPasswordEnv = "SERVER_PASSWORD"
+	adminPasswordEnv  = "ADMIN_PASSWORD"
+	gsltEnv           = "GSLT"
+)
+
+// gameServerSecret is a password or token of spec.secrets, passed to the game server container as env.
+type gameServerSecret struct {
+	env       string
+	secretRef *corev1.SecretKeySelector
+	setting   linuxgsm.SecretSetting
+}
+
+// GameServerSecretName returns the name of the Secret holding the passwords the operator generates.
+func GameServerSecretName(gs *gamesv1alpha1.GameServer) string {
+	return fmt.Sprintf("%s-secrets", gs.Name)
+}
+
+// GameServerGeneratedSecretKeys returns the keys of the passwords of spec.secrets the operator generates into the
+// Secret named by GameServerSecretName, or nil when it generates none.
+func GameServerGeneratedSecretKeys(gs *gamesv1alpha1.GameServer) []string {
+	secrets := gs.Spec.Secrets
+	if secrets == nil {
+		return nil
+	}
+
+	var keys []string
+	for _, password := range []struct {
+		key    string
+		source *gamesv1alpha1.SecretSource
+	}{
+		{SecretKeyServerPassword, secrets.ServerPassword},
+		{SecretKeyRCONPassword, secrets.RCONPassword},
+		{SecretKeyAdminPassword, secrets.AdminPassword},
+	} {
+		if password.source != nil && password.source.SecretKeyRef == nil && password.source.Generate {
+			keys = append(keys, password.key)
+		}
+	}
+	return keys
+}
+
+// GameServerRCONPasswordSecretRef returns the key of the Secret holding the RCON password, from
+// spec.rcon.passwordSecretRef or spec.secrets.rconPassword, or nil when RCON is not configured.
+func GameServerRCONPasswordSecretRef(gs *gamesv1alpha1.GameServer) *corev1.SecretKeySelector {
+	if gs.Spec.RCON == nil {
+		return nil
+	}
+	if gs.Spec.RCON.PasswordSecretRef != nil {
+		return gs.Spec.RCON.PasswordSecretRef
+	}
+	if gs.Spec.Secrets == nil {
+		return nil
+	}
+	return secretSourceRef(gs, gs.Spec.Secrets.RCONPassword, SecretKeyRCONPassword)
+}
+
+// GameServerSecretRefs returns the keys of the Secrets the game server container reads the passwords and tokens of
+// spec.secrets and spec.rcon from.
+func GameServerSecretRefs(gs *gamesv1alpha1.GameServer) []*corev1.SecretKeySelector {
+	var refs []*corev1.SecretKeySelector
+	if ref := GameServerRCONPasswordSecretRef(gs); ref != nil {
+		refs = append(refs, ref)
+	}
+	for _, secret := range gameServerSecrets(gs) {
+		refs = append(refs, secret.secretRef)
+	}
+	return refs
+}
+
+// secretSourceRef returns the key of the Secret holding a password, which is the key named after it in the Secret
+// of the GameServer when it is generated, or nil when the password is not set.
+func secretSourceRef(
+	gs *gamesv1alpha1.GameServer,
+	source *gamesv1alpha1.SecretSource,
+	key string,
+) *corev1.SecretKeySelector {
+	switch {
+	case source == nil:
+		return nil
+	case source.SecretKeyRef != nil:
+		return source.SecretKeyRef
+	case source.Generate:
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: GameServerSecretName(gs)},
+			Key:                  key,
+		}
+	}
+	return nil
+}
+
+// gameServerSecrets returns the passwords and tokens of spec.secrets other than the RCON password, with the settings
+// of the game holding them. Settings without a name are not known for the game.
+func gameServerSecrets(gs *gamesv1alpha1.GameServer) []gameServerSecret {
+	secrets := gs.Spec.Secrets
+	if secrets == nil {
+		return nil
+	}
+	settings, _ := linuxgsm.LookupSecrets(gs.Spec.GameName)
+
+	serverPassword := secretSourceRef(gs, secrets.ServerPassword, SecretKeyServerPassword)
+	adminPassword := secretSourceRef(gs, secrets.AdminPassword, SecretKeyAdminPassword)
+
+	var result []gameServerSecret
+	for _, secret := range []gameServerSecret{
+		{serverPasswordEnv, serverPassword, settings.ServerPassword},
+		{gsltEnv, secrets.GSLT, settings.GSLT},
+		{adminPasswordEnv, adminPassword, settings.AdminPassword},
+	} {
+		if secret.secretRef != nil {
+			result = append(result, secret)
+		}
+	}
+	return result
+}
+
+// buildSecretsEnv passes the passwords and tokens of spec.secrets to the game server container, for the config
+// script and the config of the game.
+func buildSecretsEnv(gs *gamesv1alpha1.GameServer) []*corev1ac.EnvVarApplyConfiguration {
+	var env []*corev1ac.EnvVarApplyConfiguration
+	for _, secret := range gameServerSecrets(gs) {
+		env = append(env, secretEnvVar(secret.env, secret.secretRef))
+	}
+	return env
+}
+
+// secretEnvVar returns an environment variable holding the key of a Secret.
+func secretEnvVar(name string, secretRef *corev1.SecretKeySelector) *corev1ac.EnvVarApplyConfiguration {
+	selector := corev1ac.SecretKeySelector().
+		WithName(secretRef.Name).
+		WithKey(secretRef.Key)
+	if secretRef.Optional != nil {
+		selector.WithOptional(*secretRef.Optional)
+	}
+	return corev1ac.EnvVar().
+		WithName(name).
+		WithValueFrom(corev1ac.EnvVarSource().WithSecretKeyRef(selector))
+}
+
+// buildSecretsConfigScript returns the part of the config script writing the passwords and tokens of spec.secrets
+// into the settings of the game, or an empty string when there is nothing to write.
+//
+// Settings are only written when their variable is set, which it is not for optional keys that do not exist. Like the
+// RCON settings, config files of the game are created when LinuxGSM installs it, so they are only updated when they
+// exist. The config script installs the game first when it is not, so they exist before the first start.
+func buildSecretsConfigScript(gs *gamesv1alpha1.GameServer) string {
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return ""
+	}
+
+	var script strings.Builder
+	for _, secret := range gameServerSecrets(gs) {
+		setting := secret.setting
+		if setting.Name == "" {
+			continue
+		}
+		file := linuxGSMInstanceConfig(game)
+		condition := fmt.Sprintf(`[ -n "${%s+set}" ]`, secret.env)
+		if setting.ConfigFile != "" {
+			file = `${LGSM_SERVERFILES:-/data/serverfiles}/` + setting.ConfigFile
+			condition += fmt.Sprintf(` && [ -f "%s" ]`, file)
+		}
+		fmt.Fprintf(&script, "if %s; then set_config \"%s\" %s %s \"${%s}\"; fi\n",
+			condition, file, setting.ConfigFormat, setting.Name, secret.env)
+	}
+	return script.String()
+}
+
+// secretsConfigureServerFiles reports whether spec.secrets is written into config files of the game.
+func secretsConfigureServerFiles(gs *gamesv1alpha1.GameServer) bool {
+	for _, secret := range gameServerSecrets(gs) {
+		if secret.setting.Name != "" && secret.setting.ConfigFile != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildGameServerSecret builds the Secret holding the passwords the operator generated for the GameServer.
+func BuildGameServerSecret(gs *gamesv1alpha1.GameServer, data map[string][]byte) *corev1ac.SecretApplyConfiguration {
+	return corev1ac.Secret(GameServerSecretName(gs), gs.Namespace).
+		WithLabels(gameServerLabels(gs)).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(data)
+}
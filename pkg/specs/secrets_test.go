@@ -0,0 +1,111 @@
+package specs_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Secrets spec builders", func() {
+	withSecrets := func(gameName string) func(*gamesv1alpha1.GameServer) {
+		return func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = gameName
+			gs.Spec.RCON = &gamesv1alpha1.RCONSpec{}
+			gs.Spec.Secrets = &gamesv1alpha1.SecretsSpec{
+				ServerPassword: &gamesv1alpha1.SecretSource{Generate: true},
+				RCONPassword:   &gamesv1alpha1.SecretSource{Generate: true},
+				GSLT: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "steam"},
+					Key:                  "token",
+					Optional:             new(true),
+				},
+				AdminPassword: &gamesv1alpha1.SecretSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "admin"},
+					Key:                  "password",
+				}},
+			}
+		}
+	}
+
+	It("passes the passwords and tokens to the game server container", func() {
+		gs := newGameServer(withSecrets("cs2"))
+		Expect(specs.GameServerGeneratedSecretKeys(gs)).To(Equal([]string{
+			specs.SecretKeyServerPassword, specs.SecretKeyRCONPassword,
+		}))
+
+		c := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec.Containers[0]
+		env := map[string]*corev1.SecretKeySelector{}
+		for _, e := range c.Env {
+			if e.ValueFrom != nil {
+				ref := e.ValueFrom.SecretKeyRef
+				env[*e.Name] = &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: *ref.Name},
+					Key:                  *ref.Key,
+					Optional:             ref.Optional,
+				}
+			}
+		}
+		Expect(env).To(HaveKeyWithValue("SERVER_PASSWORD", HaveField("Name", "example-secrets")))
+		Expect(env).To(HaveKeyWithValue("SERVER_PASSWORD", HaveField("Key", specs.SecretKeyServerPassword)))
+		Expect(env).To(HaveKeyWithValue("RCON_PASSWORD", HaveField("Key", specs.SecretKeyRCONPassword)))
+		Expect(env).To(HaveKeyWithValue("GSLT", HaveField("Optional", HaveValue(BeTrue()))))
+		Expect(env).To(HaveKeyWithValue("ADMIN_PASSWORD", HaveField("Name", "admin")))
+	})
+
+	It("writes the passwords and tokens into the settings of the game", func() {
+		script := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withSecrets("cs2"))).
+			Spec.Template.Spec.Containers[0].Command[2]
+
+		Expect(script).To(ContainSubstring(`if [ -n "${SERVER_PASSWORD+set}" ] && ` +
+			`[ -f "${LGSM_SERVERFILES:-/data/serverfiles}/game/csgo/cfg/cs2server.cfg" ]; ` +
+			`then set_config "${LGSM_SERVERFILES:-/data/serverfiles}/game/csgo/cfg/cs2server.cfg" ` +
+			`source sv_password "${SERVER_PASSWORD}"; fi`))
+		Expect(script).To(ContainSubstring(`if [ -n "${GSLT+set}" ]; then ` +
+			`set_config "${LGSM_CONFIG:-/data/config-lgsm}/cs2server/cs2server.cfg" linuxgsm gslt "${GSLT}"; fi`))
+		Expect(script).To(ContainSubstring(`source rcon_password "${RCON_PASSWORD}"`))
+		Expect(script).NotTo(ContainSubstring("ADMIN_PASSWORD"))
+	})
+
+	It("installs the game into a fresh data volume before writing its config files", func() {
+		script := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withSecrets("cs2"))).
+			Spec.Template.Spec.Containers[0].Command[2]
+		install := strings.Index(script, "  /app/cs2server auto-install\n")
+		Expect(install).To(BeNumerically(">", 0))
+		Expect(install).To(BeNumerically("<", strings.Index(script, "source sv_password")))
+		Expect(install).To(BeNumerically("<", strings.Index(script, "source rcon_password")))
+
+		By("leaving the install to LinuxGSM when only its config is written")
+		gs := newGameServer(withSecrets("cs2"), func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.RCON = nil
+			gs.Spec.Secrets.RCONPassword = nil
+			gs.Spec.Secrets.ServerPassword = nil
+		})
+		script = specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec.Containers[0].Command[2]
+		Expect(script).To(ContainSubstring("linuxgsm gslt"))
+		Expect(script).NotTo(ContainSubstring("auto-install"))
+	})
+
+	It("only passes the passwords of games without known settings", func() {
+		gs := newGameServer(withSecrets("jk2"), func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.RCON = nil
+			gs.Spec.Secrets.RCONPassword = nil
+		})
+		c := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec.Containers[0]
+		Expect(c.Env).To(HaveLen(4))
+		Expect(c.Command).To(Equal([]string{"/app/entrypoint-user.sh"}))
+	})
+
+	It("builds the Secret holding the generated passwords", func() {
+		gs := newGameServer(withSecrets("vh"))
+		secret := specs.BuildGameServerSecret(gs, map[string][]byte{specs.SecretKeyServerPassword: []byte("hunter2")})
+		Expect(secret.Name).To(HaveValue(Equal("example-secrets")))
+		Expect(secret.Namespace).To(HaveValue(Equal(gs.Namespace)))
+		Expect(secret.Data).To(HaveKeyWithValue(specs.SecretKeyServerPassword, []byte("hunter2")))
+	})
+})
@@ -0,0 +1,122 @@
+package specs
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+)
+
+// defaultShutdownGracePeriodSeconds is how long LinuxGSM gets to stop the game when spec.shutdown
+// does not set a grace period.
+const defaultShutdownGracePeriodSeconds = 120
+
+// GameServerShutdownWarnings returns the times before the game server stops at which players are warned,
+// longest first. It returns none for games without a known announce command.
+func GameServerShutdownWarnings(gs *gamesv1alpha1.GameServer) []time.Duration {
+	if gs.Spec.Shutdown == nil {
+		return nil
+	}
+	if console, ok := linuxgsm.LookupConsole(gs.Spec.GameName); !ok || console.AnnounceCommand == "" {
+		return nil
+	}
+
+	var warnings []time.Duration
+	for _, warning := range gs.Spec.Shutdown.Warnings {
+		if warning.Duration > 0 && !slices.Contains(warnings, warning.Duration) {
+			warnings = append(warnings, warning.Duration)
+		}
+	}
+	slices.SortFunc(warnings, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+	return warnings
+}
+
+// GameServerSaveCommand returns the console command saving the world, or an empty string when there is none.
+func GameServerSaveCommand(gs *gamesv1alpha1.GameServer) string {
+	if gs.Spec.Shutdown != nil && gs.Spec.Shutdown.SaveCommand != "" {
+		return gs.Spec.Shutdown.SaveCommand
+	}
+	console, _ := linuxgsm.LookupConsole(gs.Spec.GameName)
+	return console.SaveCommand
+}
+
+// GameServerAnnounceCommand returns the console command announcing the message to all players,
+// or an empty string when the announce command of the game is not known.
+func GameServerAnnounceCommand(gs *gamesv1alpha1.GameServer, message string) string {
+	console, ok := linuxgsm.LookupConsole(gs.Spec.GameName)
+	if !ok || console.AnnounceCommand == "" {
+		return ""
+	}
+	return fmt.Sprintf(console.AnnounceCommand, message)
+}
+
+// ShutdownWarning returns the message warning players that the game server stops or restarts after remaining,
+// e.g. 'The server restarts in 5 minutes'.
+func ShutdownWarning(event string, remaining time.Duration) string {
+	amount, unit := int(remaining.Round(time.Second)/time.Second), "second"
+	if remaining >= time.Minute && remaining%time.Minute == 0 {
+		amount, unit = int(remaining/time.Minute), "minute"
+	}
+	if amount != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("The server %s in %d %s", event, amount, unit)
+}
+
+// gameServerTerminationGracePeriodSeconds returns how long the pod gets to stop: the longest warning
+// and the time LinuxGSM gets to stop the game. It returns nil without spec.shutdown, keeping the Kubernetes default.
+func gameServerTerminationGracePeriodSeconds(gs *gamesv1alpha1.GameServer) *int64 {
+	if gs.Spec.Shutdown == nil {
+		return nil
+	}
+	seconds := gs.Spec.Shutdown.GracePeriodSeconds
+	if seconds == 0 {
+		seconds = defaultShutdownGracePeriodSeconds
+	}
+	if warnings := GameServerShutdownWarnings(gs); len(warnings) > 0 {
+		seconds += int64(warnings[0].Round(time.Second) / time.Second)
+	}
+	return &seconds
+}
+
+// buildPreStopHook returns the hook warning players, saving the world and stopping the game through LinuxGSM
+// before the pod is terminated, or nil without spec.shutdown. The console commands are sent to the LinuxGSM
+// console, failures are ignored so the game is always stopped.
+func buildPreStopHook(gs *gamesv1alpha1.GameServer) *corev1ac.LifecycleHandlerApplyConfiguration {
+	if gs.Spec.Shutdown == nil {
+		return nil
+	}
+	game, ok := linuxgsm.LookupGame(gs.Spec.GameName)
+	if !ok {
+		return nil
+	}
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "server=%s\n", shellQuote(game.ScriptPath()))
+	script.WriteString(`send() { "${server}" send "$1" >/dev/null 2>&1 || true; }` + "\n")
+
+	warnings := GameServerShutdownWarnings(gs)
+	for i, warning := range warnings {
+		fmt.Fprintf(&script, "send %s\n", shellQuote(GameServerAnnounceCommand(gs, ShutdownWarning("stops", warning))))
+		next := time.Duration(0)
+		if i+1 < len(warnings) {
+			next = warnings[i+1]
+		}
+		fmt.Fprintf(&script, "sleep %d\n", int((warning-next).Round(time.Second)/time.Second))
+	}
+	if save := GameServerSaveCommand(gs); save != "" {
+		fmt.Fprintf(&script, "send %s\n", shellQuote(save))
+	}
+	script.WriteString(`exec "${server}" stop` + "\n")
+
+	return corev1ac.LifecycleHandler().
+		WithExec(corev1ac.ExecAction().
+			WithCommand("/bin/bash", "-c", script.String()),
+		)
+}
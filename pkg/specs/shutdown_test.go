@@ -0,0 +1,79 @@
+package specs_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Shutdown spec builders", func() {
+	withShutdown := func(gs *gamesv1alpha1.GameServer) {
+		gs.Spec.GameName = "mc"
+		gs.Spec.Shutdown = &gamesv1alpha1.ShutdownSpec{
+			GracePeriodSeconds: 60,
+			Warnings: []metav1.Duration{
+				{Duration: 10 * time.Second},
+				{Duration: 5 * time.Minute},
+				{Duration: time.Minute},
+				{Duration: time.Minute},
+			},
+		}
+	}
+
+	It("keeps the Kubernetes defaults without spec.shutdown", func() {
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer()).Spec.Template.Spec
+		Expect(podSpec.TerminationGracePeriodSeconds).To(BeNil())
+		Expect(podSpec.Containers[0].Lifecycle).To(BeNil())
+	})
+
+	It("warns players, saves the world and stops the game before the pod terminates", func() {
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(newGameServer(withShutdown)).Spec.Template.Spec
+		Expect(podSpec.TerminationGracePeriodSeconds).To(HaveValue(Equal(int64(5*60 + 60))))
+
+		preStop := podSpec.Containers[0].Lifecycle.PreStop
+		Expect(preStop.Exec.Command).To(HaveLen(3))
+		Expect(preStop.Exec.Command[2]).To(Equal(`server='/app/mcserver'
+send() { "${server}" send "$1" >/dev/null 2>&1 || true; }
+send 'say The server stops in 5 minutes'
+sleep 240
+send 'say The server stops in 1 minute'
+sleep 50
+send 'say The server stops in 10 seconds'
+sleep 10
+send 'save-all flush'
+exec "${server}" stop
+`))
+	})
+
+	It("does not warn players of games without an announce command", func() {
+		gs := newGameServer(withShutdown, func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.GameName = "vh"
+			gs.Spec.Shutdown.GracePeriodSeconds = 0
+		})
+		Expect(specs.GameServerShutdownWarnings(gs)).To(BeEmpty())
+
+		podSpec := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.Template.Spec
+		Expect(podSpec.TerminationGracePeriodSeconds).To(HaveValue(Equal(int64(120))))
+		Expect(podSpec.Containers[0].Lifecycle.PreStop.Exec.Command[2]).To(HaveSuffix(
+			"send() { \"${server}\" send \"$1\" >/dev/null 2>&1 || true; }\nexec \"${server}\" stop\n"))
+	})
+
+	It("uses the configured save command", func() {
+		gs := newGameServer(withShutdown, func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.Shutdown.SaveCommand = "save-all"
+		})
+		Expect(specs.GameServerSaveCommand(gs)).To(Equal("save-all"))
+	})
+
+	It("describes the time left in warnings", func() {
+		Expect(specs.ShutdownWarning("restarts", 5*time.Minute)).To(Equal("The server restarts in 5 minutes"))
+		Expect(specs.ShutdownWarning("stops", time.Minute)).To(Equal("The server stops in 1 minute"))
+		Expect(specs.ShutdownWarning("stops", 90*time.Second)).To(Equal("The server stops in 90 seconds"))
+	})
+})
@@ -0,0 +1,99 @@
+package specs
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/utils"
+)
+
+// VolumeSnapshotGroupVersionKind is the kind of the CSI VolumeSnapshots taken by Snapshot backups.
+// VolumeSnapshots are handled as unstructured objects, so the operator does not depend on the
+// external-snapshotter client and runs on clusters without the snapshot CRDs installed.
+var VolumeSnapshotGroupVersionKind = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+const (
+	// SnapshotGameServerUIDLabel is the label of a VolumeSnapshot holding the UID of the GameServer that took it, so
+	// the snapshots of a deleted GameServer are never pruned by a new one with the same name.
+	SnapshotGameServerUIDLabel = "games.idebeijer.github.io/gameserver-uid"
+
+	// SnapshotFinalLabel marks the VolumeSnapshot taken before the GameServer was deleted, which is never pruned.
+	SnapshotFinalLabel = "games.idebeijer.github.io/final-snapshot"
+)
+
+// snapshotTimeFormat is the UTC timestamp in snapshot names, matching the names of archive backups.
+const snapshotTimeFormat = "20060102150405"
+
+// GameServerBackupMode returns how backups of the game server are taken.
+func GameServerBackupMode(gs *gamesv1alpha1.GameServer) gamesv1alpha1.BackupMode {
+	if gs.Spec.Backup != nil && gs.Spec.Backup.Mode != "" {
+		return gs.Spec.Backup.Mode
+	}
+	return gamesv1alpha1.BackupModeArchive
+}
+
+// GameServerSnapshotName returns the name of the VolumeSnapshot scheduled at the given time.
+func GameServerSnapshotName(gs *gamesv1alpha1.GameServer, scheduled time.Time) string {
+	return fmt.Sprintf("%s-%s", gs.Name, scheduled.UTC().Format(snapshotTimeFormat))
+}
+
+// GameServerFinalSnapshotName returns the name of the VolumeSnapshot taken before the GameServer is deleted.
+// It includes the deletion time, so a recreated GameServer with the same name does not reuse it.
+func GameServerFinalSnapshotName(gs *gamesv1alpha1.GameServer) string {
+	deleted := time.Now()
+	if gs.DeletionTimestamp != nil {
+		deleted = gs.DeletionTimestamp.Time
+	}
+	return fmt.Sprintf("%s-final-%s", gs.Name, deleted.UTC().Format(snapshotTimeFormat))
+}
+
+// GameServerSnapshotLabels returns the labels of the VolumeSnapshots of the game server, which select the snapshots
+// taken by this GameServer rather than by an earlier one with the same name.
+func GameServerSnapshotLabels(gs *gamesv1alpha1.GameServer) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/instance":   gs.Name,
+		"app.kubernetes.io/managed-by": utils.GameServerControllerName,
+		"app.kubernetes.io/component":  "snapshot",
+		SnapshotGameServerUIDLabel:     string(gs.UID),
+	}
+}
+
+// BuildGameServerVolumeSnapshot builds a VolumeSnapshot of the data volume of the game server.
+// VolumeSnapshots are not owned by the GameServer, so they outlive it and can be used to create a new one.
+func BuildGameServerVolumeSnapshot(gs *gamesv1alpha1.GameServer, name string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(gs.Namespace)
+	snapshot.SetLabels(GameServerSnapshotLabels(gs))
+
+	spec := map[string]any{
+		"source": map[string]any{
+			"persistentVolumeClaimName": GameServerDataVolumeClaimName(gs),
+		},
+	}
+	if gs.Spec.Backup != nil && gs.Spec.Backup.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *gs.Spec.Backup.VolumeSnapshotClassName
+	}
+	snapshot.Object["spec"] = spec
+
+	return snapshot
+}
+
+// BuildGameServerFinalVolumeSnapshot builds the VolumeSnapshot of the data volume taken before the GameServer is
+// deleted, named by GameServerFinalSnapshotName.
+func BuildGameServerFinalVolumeSnapshot(gs *gamesv1alpha1.GameServer) *unstructured.Unstructured {
+	snapshot := BuildGameServerVolumeSnapshot(gs, GameServerFinalSnapshotName(gs))
+	labels := snapshot.GetLabels()
+	labels[SnapshotFinalLabel] = "true"
+	snapshot.SetLabels(labels)
+	return snapshot
+}
@@ -0,0 +1,69 @@
+package specs_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/specs"
+)
+
+var _ = Describe("Snapshot spec builders", func() {
+	withSnapshots := func(gs *gamesv1alpha1.GameServer) {
+		gs.Spec.Backup = &gamesv1alpha1.BackupSpec{
+			Schedule:                "0 4 * * *",
+			Mode:                    gamesv1alpha1.BackupModeSnapshot,
+			VolumeSnapshotClassName: new("csi-snapclass"),
+		}
+	}
+
+	It("builds a VolumeSnapshot of the data volume", func() {
+		gs := newGameServer(withSnapshots)
+		name := specs.GameServerSnapshotName(gs, time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC))
+		Expect(name).To(Equal("example-20260102040000"))
+
+		snapshot := specs.BuildGameServerVolumeSnapshot(gs, name)
+		Expect(snapshot.GroupVersionKind()).To(Equal(specs.VolumeSnapshotGroupVersionKind))
+		Expect(snapshot.GetLabels()).To(Equal(specs.GameServerSnapshotLabels(gs)))
+		Expect(snapshot.GetOwnerReferences()).To(BeEmpty(), "snapshots must outlive the GameServer")
+
+		pvc, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		Expect(pvc).To(Equal("data-example-0"))
+		class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+		Expect(class).To(Equal("csi-snapclass"))
+	})
+
+	It("labels snapshots with the UID of the GameServer and marks the final one", func() {
+		gs := newGameServer(withSnapshots, func(gs *gamesv1alpha1.GameServer) {
+			gs.UID = "0b5c7f0e-uid"
+		})
+		Expect(specs.GameServerSnapshotLabels(gs)).To(HaveKeyWithValue(specs.SnapshotGameServerUIDLabel, "0b5c7f0e-uid"))
+
+		final := specs.BuildGameServerFinalVolumeSnapshot(gs)
+		Expect(final.GetName()).To(HavePrefix("example-final-"))
+		Expect(final.GetLabels()).To(HaveKeyWithValue(specs.SnapshotFinalLabel, "true"))
+		Expect(final.GetLabels()).To(HaveKeyWithValue(specs.SnapshotGameServerUIDLabel, "0b5c7f0e-uid"))
+	})
+
+	It("does not take archive backups in Snapshot mode", func() {
+		gs := newGameServer(withSnapshots)
+		Expect(specs.BuildGameServerBackupCronJob(gs)).To(BeNil())
+		Expect(specs.BuildGameServerFinalBackupJob(gs)).To(BeNil())
+	})
+
+	It("populates the data volume from a snapshot", func() {
+		gs := newGameServer(func(gs *gamesv1alpha1.GameServer) {
+			gs.Spec.Storage = &gamesv1alpha1.StorageSpec{FromSnapshot: "old-20260102040000"}
+		})
+
+		template := specs.BuildLinuxGSMGameServerStatefulSet(gs).Spec.VolumeClaimTemplates[0]
+		Expect(template.Spec.DataSourceRef).NotTo(BeNil())
+		Expect(template.Spec.DataSourceRef.APIGroup).To(HaveValue(Equal("snapshot.storage.k8s.io")))
+		Expect(template.Spec.DataSourceRef.Kind).To(HaveValue(Equal("VolumeSnapshot")))
+		Expect(template.Spec.DataSourceRef.Name).To(HaveValue(Equal("old-20260102040000")))
+	})
+})
@@ -0,0 +1,95 @@
+package specs
+
+import (
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	discoveryv1ac "k8s.io/client-go/applyconfigurations/discovery/v1"
+
+	gamesv1alpha1 "github.com/idebeijer/gameserver-operator/api/v1alpha1"
+	"github.com/idebeijer/gameserver-operator/pkg/linuxgsm"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+	"github.com/idebeijer/gameserver-operator/pkg/wake"
+)
+
+// WakeEndpointSliceManager is the endpointslice.kubernetes.io/managed-by label of the EndpointSlices adding the
+// wake proxy to the endpoints of game server Services.
+const WakeEndpointSliceManager = "wake-proxy.games.idebeijer.github.io"
+
+// GameServerWakeOnConnect reports whether the game server is started when a player connects to it while it is idle.
+func GameServerWakeOnConnect(gs *gamesv1alpha1.GameServer) bool {
+	return gs.Spec.Idle != nil && gs.Spec.Idle.WakeOnConnect && gs.Spec.Service != nil
+}
+
+// WakeEndpointSliceName returns the name of the EndpointSlice routing the Service of the game server to the
+// wake proxy.
+func WakeEndpointSliceName(gs *gamesv1alpha1.GameServer) string {
+	return gs.Name + "-wake"
+}
+
+// WakePorts returns the ports of the Service of the game server the wake proxy listens for.
+func WakePorts(gs *gamesv1alpha1.GameServer) []wake.Port {
+	if gs.Spec.Service == nil {
+		return nil
+	}
+
+	q, queried := linuxgsm.LookupQuery(gs.Spec.GameName)
+	ports := make([]wake.Port, 0, len(gs.Spec.Service.Ports))
+	for _, port := range gs.Spec.Service.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		ports = append(ports, wake.Port{
+			Name:     port.Name,
+			Protocol: protocol,
+			Minecraft: queried && q.Protocol == query.ProtocolMinecraft && port.Name == q.PortName &&
+				protocol == corev1.ProtocolTCP,
+		})
+	}
+	return ports
+}
+
+// BuildWakeEndpointSlice returns an EndpointSlice adding the wake proxy at address to the endpoints of the Service
+// of the game server. The controller creates it while the game server sleeps and removes it once the game server
+// pod is ready, so the Service routes players to the proxy in the meantime.
+// The proxy runs on the node named nodeName, which Services with externalTrafficPolicy Local need to route players
+// to it, and listens on the ports in the order of WakePorts.
+func BuildWakeEndpointSlice(
+	gs *gamesv1alpha1.GameServer,
+	address string,
+	nodeName string,
+	ports []int32,
+) *discoveryv1ac.EndpointSliceApplyConfiguration {
+	addressType := discoveryv1.AddressTypeIPv4
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+
+	labels := gameServerLabels(gs)
+	labels[discoveryv1.LabelServiceName] = gs.Name
+	labels[discoveryv1.LabelManagedBy] = WakeEndpointSliceManager
+
+	endpoint := discoveryv1ac.Endpoint().
+		WithAddresses(address).
+		WithConditions(discoveryv1ac.EndpointConditions().WithReady(true))
+	if nodeName != "" {
+		endpoint.WithNodeName(nodeName)
+	}
+
+	slice := discoveryv1ac.EndpointSlice(WakeEndpointSliceName(gs), gs.Namespace).
+		WithLabels(labels).
+		WithAddressType(addressType).
+		WithEndpoints(endpoint)
+	for i, port := range WakePorts(gs) {
+		if i >= len(ports) {
+			break
+		}
+		slice.WithPorts(discoveryv1ac.EndpointPort().
+			WithName(port.Name).
+			WithProtocol(port.Protocol).
+			WithPort(ports[i]))
+	}
+	return slice
+}
@@ -0,0 +1,92 @@
+package wake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mcproto"
+)
+
+const (
+	// maxMinecraftPacketSize bounds the packets read from clients, which only send small packets before logging in.
+	maxMinecraftPacketSize = 1 << 10
+
+	// StartingMessage is shown to Minecraft players in the server list and when they try to join.
+	StartingMessage = "Server is starting, please try again in a minute"
+)
+
+// serveMinecraft answers a Minecraft client: the Server List Ping with a server list entry saying the server is
+// starting, and logins by disconnecting with that message. A wake up is requested on any handshake.
+func serveMinecraft(conn net.Conn, requestWake func()) {
+	r := bufio.NewReader(conn)
+
+	packet, err := readMinecraftPacket(r, mcproto.HandshakePacket)
+	if err != nil {
+		return
+	}
+	protocolVersion, err := mcproto.ReadVarInt(packet)
+	if err != nil {
+		return
+	}
+	if _, err := mcproto.ReadString(packet); err != nil {
+		return
+	}
+	if _, err := packet.Seek(2, io.SeekCurrent); err != nil {
+		return
+	}
+	nextState, err := mcproto.ReadVarInt(packet)
+	if err != nil {
+		return
+	}
+	go requestWake()
+
+	if nextState != mcproto.StatusState {
+		// Logins and transfers are told to come back later. The login request is read first, closing the connection
+		// with unread data would reset it before the client reads the message.
+		_, _ = readMinecraftPacket(r, mcproto.LoginStartPacket)
+		disconnect := mcproto.AppendString(nil, minecraftText(StartingMessage))
+		_, _ = conn.Write(mcproto.Packet(mcproto.LoginDisconnectPacket, disconnect))
+		return
+	}
+
+	if _, err := readMinecraftPacket(r, mcproto.StatusPacket); err != nil {
+		return
+	}
+	if _, err := conn.Write(mcproto.Packet(mcproto.StatusPacket,
+		mcproto.AppendString(nil, minecraftStatus(protocolVersion)))); err != nil {
+		return
+	}
+
+	// The client measures the latency with a ping, which is answered with the same payload.
+	ping, err := readMinecraftPacket(r, mcproto.PingPacket)
+	if err != nil {
+		return
+	}
+	payload, _ := io.ReadAll(ping)
+	_, _ = conn.Write(mcproto.Packet(mcproto.PingPacket, payload))
+}
+
+// minecraftStatus returns the status response of a starting server. The protocol version of the client is
+// echoed, so the client does not report the server as incompatible.
+func minecraftStatus(protocolVersion int32) string {
+	status := map[string]any{
+		"version":     map[string]any{"name": "Starting", "protocol": protocolVersion},
+		"players":     map[string]any{"max": 0, "online": 0},
+		"description": map[string]any{"text": StartingMessage},
+	}
+	b, _ := json.Marshal(status)
+	return string(b)
+}
+
+func minecraftText(text string) string {
+	b, _ := json.Marshal(map[string]string{"text": text})
+	return string(b)
+}
+
+// readMinecraftPacket reads a packet of a client with the given id and returns a reader of its data.
+func readMinecraftPacket(r *bufio.Reader, id int32) (*bytes.Reader, error) {
+	return mcproto.ReadPacket(r, id, maxMinecraftPacketSize)
+}
@@ -0,0 +1,226 @@
+// Package wake answers connections to game servers that are stopped or starting, and requests them to start.
+//
+// The Proxy listens on a random port for each port of a game server. The operator routes the Service of the game
+// server to these ports while the game server is not ready, so the first player connecting starts it, and Minecraft
+// players are told the server is starting instead of waiting for a timeout.
+package wake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// wakeRequestInterval throttles the wake up requests of a game server, as players keep connecting while it starts.
+	wakeRequestInterval = 10 * time.Second
+
+	// connectionTimeout bounds how long a connection is served.
+	connectionTimeout = 10 * time.Second
+)
+
+// Port is a port of a game server the proxy listens for.
+type Port struct {
+	// Name is the name of the Service port.
+	Name string
+	// Protocol is the protocol of the Service port, TCP or UDP.
+	Protocol corev1.Protocol
+	// Minecraft answers the Minecraft Server List Ping and logins with a message that the server is starting,
+	// and only requests a wake up on a Minecraft handshake.
+	Minecraft bool
+}
+
+// WakeFunc requests the game server to start.
+type WakeFunc func(ctx context.Context, key types.NamespacedName) error
+
+// Proxy listens for connections to game servers and requests them to start. It is safe for concurrent use.
+type Proxy struct {
+	address  string
+	nodeName string
+	wake     WakeFunc
+
+	mu          sync.Mutex
+	gameServers map[types.NamespacedName]*gameServerListeners
+}
+
+// gameServerListeners are the listeners of the ports of a game server.
+type gameServerListeners struct {
+	ports     []Port
+	listeners []listener
+	bound     []int32
+
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+// listener is a TCP or UDP listener.
+type listener interface {
+	Close() error
+}
+
+// NewProxy returns a proxy reached at address, the IP address of the pod it runs in, on the node named nodeName,
+// calling wake on connections.
+func NewProxy(address, nodeName string, wake WakeFunc) *Proxy {
+	return &Proxy{
+		address:     address,
+		nodeName:    nodeName,
+		wake:        wake,
+		gameServers: map[types.NamespacedName]*gameServerListeners{},
+	}
+}
+
+// Address returns the IP address the proxy is reached at.
+func (p *Proxy) Address() string {
+	return p.address
+}
+
+// NodeName returns the name of the node the proxy runs on, or an empty string when unknown.
+func (p *Proxy) NodeName() string {
+	return p.nodeName
+}
+
+// Listen listens for the ports of the game server and returns the ports it listens on, in the order of ports.
+// It keeps listening on the same ports when called again with the same ports.
+func (p *Proxy) Listen(key types.NamespacedName, ports []Port) ([]int32, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.gameServers[key]; ok {
+		if slices.Equal(existing.ports, ports) {
+			return existing.bound, nil
+		}
+		existing.close()
+		delete(p.gameServers, key)
+	}
+
+	gsl := &gameServerListeners{ports: slices.Clone(ports)}
+	for _, port := range ports {
+		if err := gsl.listen(key, port, p.wake); err != nil {
+			gsl.close()
+			return nil, err
+		}
+	}
+	p.gameServers[key] = gsl
+	return gsl.bound, nil
+}
+
+// Close stops listening for the ports of the game server.
+func (p *Proxy) Close(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if gsl, ok := p.gameServers[key]; ok {
+		gsl.close()
+		delete(p.gameServers, key)
+	}
+}
+
+// Start implements manager.Runnable. It stops listening for all game servers when ctx is done.
+func (p *Proxy) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, gsl := range p.gameServers {
+		gsl.close()
+		delete(p.gameServers, key)
+	}
+	return nil
+}
+
+func (g *gameServerListeners) listen(key types.NamespacedName, port Port, wake WakeFunc) error {
+	requestWake := func() { g.requestWake(key, wake) }
+
+	switch port.Protocol {
+	case corev1.ProtocolUDP:
+		conn, err := net.ListenPacket("udp", ":0")
+		if err != nil {
+			return fmt.Errorf("wake: failed to listen for %s port %s: %w", key, port.Name, err)
+		}
+		g.listeners = append(g.listeners, conn)
+		g.bound = append(g.bound, int32(conn.LocalAddr().(*net.UDPAddr).Port))
+		go servePackets(conn, requestWake)
+	default:
+		ln, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return fmt.Errorf("wake: failed to listen for %s port %s: %w", key, port.Name, err)
+		}
+		g.listeners = append(g.listeners, ln)
+		g.bound = append(g.bound, int32(ln.Addr().(*net.TCPAddr).Port))
+		serve := func(conn net.Conn) { requestWake() }
+		if port.Minecraft {
+			serve = func(conn net.Conn) { serveMinecraft(conn, requestWake) }
+		}
+		go serveConnections(ln, serve)
+	}
+	return nil
+}
+
+func (g *gameServerListeners) close() {
+	for _, l := range g.listeners {
+		_ = l.Close()
+	}
+}
+
+// requestWake requests the game server to start, at most once per wakeRequestInterval.
+func (g *gameServerListeners) requestWake(key types.NamespacedName, wake WakeFunc) {
+	g.mu.Lock()
+	if time.Since(g.lastRequest) < wakeRequestInterval {
+		g.mu.Unlock()
+		return
+	}
+	g.lastRequest = time.Now()
+	g.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+	log := logf.Log.WithName("wake").WithValues("gameserver", key)
+	if err := wake(ctx, key); err != nil {
+		log.Error(err, "Failed to request the game server to start")
+		g.mu.Lock()
+		g.lastRequest = time.Time{}
+		g.mu.Unlock()
+		return
+	}
+	log.Info("Requested the game server to start on a connection")
+}
+
+// serveConnections serves the connections to ln until it is closed.
+func serveConnections(ln net.Listener, serve func(net.Conn)) {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			_ = conn.SetDeadline(time.Now().Add(connectionTimeout))
+			serve(conn)
+		}()
+	}
+}
+
+// servePackets requests a wake up for each packet received on conn until it is closed. The packets are not
+// answered, game clients retry until the game server answers them.
+func servePackets(conn net.PacketConn, requestWake func()) {
+	buf := make([]byte, 1)
+	for {
+		if _, _, err := conn.ReadFrom(buf); errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+		go requestWake()
+	}
+}
@@ -0,0 +1,112 @@
+package wake
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/idebeijer/gameserver-operator/pkg/mcproto"
+	"github.com/idebeijer/gameserver-operator/pkg/query"
+)
+
+var _ = Describe("Proxy", func() {
+	key := types.NamespacedName{Name: "mc", Namespace: "default"}
+
+	var (
+		proxy *Proxy
+		mu    sync.Mutex
+		woken []types.NamespacedName
+	)
+
+	wokenUp := func() []types.NamespacedName {
+		mu.Lock()
+		defer mu.Unlock()
+		return woken
+	}
+
+	address := func(port int32) string {
+		return net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port)))
+	}
+
+	BeforeEach(func() {
+		woken = nil
+		proxy = NewProxy("10.0.0.1", "node-a", func(_ context.Context, key types.NamespacedName) error {
+			mu.Lock()
+			defer mu.Unlock()
+			woken = append(woken, key)
+			return nil
+		})
+	})
+
+	AfterEach(func() {
+		proxy.Close(key)
+	})
+
+	It("tells Minecraft players the server is starting and wakes it up once", func() {
+		ports, err := proxy.Listen(key, []Port{{Name: "game", Protocol: corev1.ProtocolTCP, Minecraft: true}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ports).To(HaveLen(1))
+
+		info, err := query.Query(context.Background(), query.ProtocolMinecraft, address(ports[0]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Name).To(Equal(StartingMessage))
+		Expect(info.Version).To(Equal("Starting"))
+		Eventually(wokenUp).Should(Equal([]types.NamespacedName{key}))
+
+		By("disconnecting players trying to join")
+		conn, err := net.Dial("tcp", address(ports[0]))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = conn.Close() }()
+		handshake := mcproto.AppendVarInt(nil, 767)
+		handshake = mcproto.AppendString(handshake, "localhost")
+		handshake = append(handshake, 0x63, 0xDD, 2)
+		_, err = conn.Write(append(mcproto.Packet(mcproto.HandshakePacket, handshake),
+			mcproto.Packet(mcproto.LoginStartPacket, mcproto.AppendString(nil, "alice"))...))
+		Expect(err).NotTo(HaveOccurred())
+		disconnect, err := readMinecraftPacket(bufio.NewReader(conn), mcproto.LoginDisconnectPacket)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mcproto.ReadString(disconnect)).To(ContainSubstring(StartingMessage))
+
+		By("not requesting another wake up right away")
+		Consistently(wokenUp, "200ms").Should(HaveLen(1))
+	})
+
+	It("wakes up game servers on UDP packets and TCP connections", func() {
+		ports, err := proxy.Listen(key, []Port{
+			{Name: "game", Protocol: corev1.ProtocolUDP},
+			{Name: "rcon", Protocol: corev1.ProtocolTCP},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ports).To(HaveLen(2))
+
+		conn, err := net.Dial("udp", address(ports[0]))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = conn.Close() }()
+		_, err = conn.Write([]byte("\xFF\xFF\xFF\xFFTSource Engine Query\x00"))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(wokenUp).Should(Equal([]types.NamespacedName{key}))
+
+		tcp, err := net.Dial("tcp", address(ports[1]))
+		Expect(err).NotTo(HaveOccurred())
+		_ = tcp.Close()
+	})
+
+	It("keeps listening on the same ports until it is closed", func() {
+		gamePorts := []Port{{Name: "game", Protocol: corev1.ProtocolTCP}}
+		ports, err := proxy.Listen(key, gamePorts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxy.Listen(key, gamePorts)).To(Equal(ports))
+
+		proxy.Close(key)
+		_, err = net.Dial("tcp", address(ports[0]))
+		Expect(err).To(HaveOccurred())
+		Expect(wokenUp()).To(BeEmpty())
+	})
+})
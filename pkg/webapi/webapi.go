@@ -0,0 +1,77 @@
+// Package webapi gets resources from the web APIs the operator resolves Minecraft servers, mods and players with.
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// UserAgent identifies the operator, as Modrinth asks of API clients.
+	UserAgent = "idebeijer/gameserver-operator (https://github.com/idebeijer/gameserver-operator)"
+
+	// DefaultTimeout bounds the requests of the HTTP clients of the operator.
+	DefaultTimeout = 30 * time.Second
+
+	// MaxJSONSize bounds the JSON responses read, which list at most a page of versions.
+	MaxJSONSize = 16 << 20
+)
+
+// ErrNotFound is returned for requests answered with 404 Not Found.
+var ErrNotFound = errors.New("not found")
+
+// GetJSON decodes the JSON response to a GET request of rawURL into v.
+func GetJSON(ctx context.Context, client *http.Client, rawURL string, header http.Header, v any) error {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Accept", "application/json")
+	body, err := Get(ctx, client, rawURL, header, MaxJSONSize)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Get returns the response to a GET request of rawURL, failing when it is larger than limit. A nil client is
+// http.DefaultClient.
+func Get(ctx context.Context, client *http.Client, rawURL string, header http.Header, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("the response of %s is larger than %d bytes", req.URL.Host, limit)
+	}
+	return body, nil
+}